@@ -0,0 +1,24 @@
+//go:build embedui
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"log"
+)
+
+// 默认静态资源（课程封面、示例PDF等），用户上传的头像不嵌入
+//
+//go:embed static/images/course static/pdfs static/forums
+var embeddedAssets embed.FS
+
+// defaultAssets 返回嵌入的默认资源中 dir 子目录，作为磁盘目录找不到文件时的后备
+func defaultAssets(dir string) fs.FS {
+	sub, err := fs.Sub(embeddedAssets, "static/"+dir)
+	if err != nil {
+		log.Printf("读取嵌入资源 %s 失败: %v", dir, err)
+		return nil
+	}
+	return sub
+}
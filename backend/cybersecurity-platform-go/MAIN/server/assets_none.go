@@ -0,0 +1,10 @@
+//go:build !embedui
+
+package main
+
+import "io/fs"
+
+// defaultAssets 未使用 embedui 标签构建时没有嵌入资源
+func defaultAssets(dir string) fs.FS {
+	return nil
+}
@@ -0,0 +1,715 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"cybersecurity-platform-go/internal/config"
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/handlers"
+	"cybersecurity-platform-go/internal/health"
+	"cybersecurity-platform-go/internal/hls"
+	"cybersecurity-platform-go/internal/mediainfo"
+	"cybersecurity-platform-go/internal/migrate"
+	"cybersecurity-platform-go/internal/ops"
+	"cybersecurity-platform-go/internal/seed"
+	"cybersecurity-platform-go/internal/sheet"
+	"cybersecurity-platform-go/internal/storage"
+)
+
+const usage = `用法: server <子命令> [参数]
+
+子命令:
+  serve                              启动HTTP服务（默认）
+  migrate [up|status]                执行或查看数据库迁移
+  seed [-list] <数据集>...            写入测试数据
+  user create|disable|enable|reset-password  管理学生账号
+  course import|export|reindex       导入或导出课程（JSON），或重建课程检索索引
+  course roster-import|roster-export 导入或导出课程学生名单（CSV/XLSX）
+  teacher token -id <教师ID>          为教师签发上传接口令牌
+  video backfill [-force] [-dry-run]   解析视频目录中的文件，补全视频时长、分辨率和编码
+  video hls -id <视频ID> | -pending    将视频打包为 HLS 分段
+  cache flush                        清空运行中服务的内存缓存
+  storage migrate|presign            在存储之间复制文件，或生成预签名下载地址
+  doctor                             检查配置、目录、数据库和图数据库连接
+
+使用 "server <子命令> -h" 查看子命令参数
+`
+
+// runCLI 解析并执行子命令
+// 所有子命令共用 config.Load 加载的配置
+func runCLI(args []string) error {
+	cmd := "serve"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	if cmd == "help" || cmd == "-h" || cmd == "--help" {
+		fmt.Print(usage)
+		return nil
+	}
+
+	cfg := config.Load()
+	database.Configure(cfg.GetDBDSN())
+	defer database.CloseDB()
+
+	switch cmd {
+	case "serve":
+		return serve(cfg)
+	case "migrate":
+		return runMigrate(args)
+	case "seed":
+		return runSeed(args)
+	case "user":
+		return runUser(args)
+	case "course":
+		return runCourse(args)
+	case "teacher":
+		return runTeacher(args)
+	case "video":
+		return runVideo(cfg, args)
+	case "cache":
+		return runCache(cfg, args)
+	case "storage":
+		return runStorage(cfg, args)
+	case "doctor":
+		return runDoctor(cfg)
+	default:
+		fmt.Print(usage)
+		return fmt.Errorf("未知的子命令: %s", cmd)
+	}
+}
+
+// openDB 获取数据库连接，连接失败时返回错误而不是继续执行
+func openDB() (*sql.DB, error) {
+	db, err := database.GetDB()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("数据库连接失败: %v", err)
+	}
+	return db, nil
+}
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(args []string) error {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		done, err := migrate.Up(db)
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("✓ 数据库结构已是最新")
+		} else {
+			fmt.Printf("✓ 已执行 %d 个迁移\n", len(done))
+		}
+		return nil
+	case "status":
+		applied, err := migrate.Applied(db)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrate.Migrations() {
+			if a, ok := applied[m.Version]; ok {
+				fmt.Printf("  ✅ %04d_%s  (%s)\n", m.Version, m.Name, a.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("  ⏳ %04d_%s  (未执行)\n", m.Version, m.Name)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("未知的 migrate 操作: %s（可用: up, status）", action)
+	}
+}
+
+// checkMigrations 检查是否有未执行的迁移，有则返回错误并提示先执行 migrate up
+func checkMigrations() error {
+	db, err := database.GetDB()
+	if err != nil {
+		return err
+	}
+	pending, err := migrate.Pending(db)
+	if err != nil {
+		return fmt.Errorf("检查数据库迁移失败: %v", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(pending))
+	for _, m := range pending {
+		names = append(names, fmt.Sprintf("%04d_%s", m.Version, m.Name))
+	}
+	return fmt.Errorf("有 %d 个数据库迁移未执行（%s），请先运行 \"server migrate up\"", len(pending), strings.Join(names, ", "))
+}
+
+// runSeed 执行 seed 子命令
+func runSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	list := fs.Bool("list", false, "列出可用的数据集")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *list || fs.NArg() == 0 {
+		fmt.Println("可用的数据集:")
+		for _, set := range seed.Sets() {
+			fmt.Printf("  %-10s %s\n", set.Name, set.Description)
+		}
+		if !*list {
+			return errors.New("请指定至少一个数据集")
+		}
+		return nil
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	if err := seed.Run(db, fs.Args()...); err != nil {
+		return err
+	}
+	fmt.Printf("✓ 数据集已写入: %s\n", strings.Join(fs.Args(), ", "))
+	return nil
+}
+
+// runUser 执行 user 子命令
+func runUser(args []string) error {
+	if len(args) == 0 {
+		return errors.New("用法: user create|disable|enable|reset-password [参数]")
+	}
+	action, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("user "+action, flag.ContinueOnError)
+	stuID := fs.String("stuId", "", "学号")
+	email := fs.String("email", "", "邮箱（create）")
+	password := fs.String("password", "", "密码（create、reset-password）")
+	nickName := fs.String("nick", "", "昵称（create，默认为学号）")
+	userName := fs.String("name", "", "姓名（create）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *stuID == "" {
+		return errors.New("缺少 -stuId 参数")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "create":
+		err = ops.CreateUser(db, ops.NewUser{
+			StuID: *stuID, Email: *email, Password: *password, NickName: *nickName, UserName: *userName,
+		})
+	case "disable":
+		err = ops.SetUserDisabled(db, *stuID, true)
+	case "enable":
+		err = ops.SetUserDisabled(db, *stuID, false)
+	case "reset-password":
+		err = ops.ResetPassword(db, *stuID, *password)
+	default:
+		return fmt.Errorf("未知的 user 操作: %s", action)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ user %s 完成: %s\n", action, *stuID)
+	return nil
+}
+
+// runTeacher 执行 teacher 子命令
+func runTeacher(args []string) error {
+	if len(args) == 0 || args[0] != "token" {
+		return errors.New("用法: teacher token -id <教师ID>")
+	}
+
+	fs := flag.NewFlagSet("teacher token", flag.ContinueOnError)
+	teacherID := fs.Int("id", 0, "教师ID")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *teacherID <= 0 {
+		return errors.New("缺少 -id 参数")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	token, err := ops.IssueTeacherToken(db, *teacherID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ 已为教师 %d 签发令牌（旧令牌已失效，请妥善保存）:\n%s\n", *teacherID, token)
+	return nil
+}
+
+// runVideo 执行 video 子命令
+func runVideo(cfg *config.Config, args []string) error {
+	if len(args) > 0 && args[0] == "hls" {
+		return runVideoHLS(cfg, args[1:])
+	}
+	if len(args) == 0 || args[0] != "backfill" {
+		return errors.New("用法: video backfill [-force] [-dry-run] | video hls -id <视频ID> | -pending")
+	}
+
+	fs := flag.NewFlagSet("video backfill", flag.ContinueOnError)
+	force := fs.Bool("force", false, "重新解析已有元数据的视频")
+	dryRun := fs.Bool("dry-run", false, "只解析不写入数据库")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	stats, err := ops.BackfillVideoMetadata(db, cfg.VideoDirs, *force, *dryRun, func(id int, videoURL string, info *mediainfo.Info, err error) {
+		if err != nil {
+			fmt.Printf("  ✗ [%d] %s: %v\n", id, videoURL, err)
+			return
+		}
+		fmt.Printf("  ✓ [%d] %s: %ds %dx%d %s/%s %dkbps\n",
+			id, videoURL, info.Seconds(), info.Width, info.Height, info.VideoCodec, info.AudioCodec, info.Bitrate/1000)
+	})
+	if err != nil {
+		return err
+	}
+
+	action := "已更新"
+	if *dryRun {
+		action = "可更新"
+	}
+	fmt.Printf("✓ %s %d 个视频，跳过外部地址 %d 个，文件缺失 %d 个，无效文件 %d 个\n",
+		action, stats.Updated, stats.Skipped, stats.Missing, stats.Invalid)
+	return nil
+}
+
+// runVideoHLS 执行 video hls 子命令
+func runVideoHLS(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("video hls", flag.ContinueOnError)
+	videoID := fs.Int64("id", 0, "视频ID")
+	pending := fs.Bool("pending", false, "打包所有尚未打包或打包失败的视频")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *videoID <= 0 && !*pending {
+		return errors.New("需要 -id 或 -pending 参数")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	store, err := storage.Open(cfg, "")
+	if err != nil {
+		return err
+	}
+
+	ids := []int64{*videoID}
+	if *pending {
+		rows, err := db.Query("SELECT id FROM videos WHERE hls_status IN ('', ?) ORDER BY id", ops.HLSFailed)
+		if err != nil {
+			return err
+		}
+		ids = ids[:0]
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	opts := hls.Options{SegmentDuration: cfg.HLSSegmentDuration, Transcoder: cfg.HLSTranscoder}
+	failed := 0
+	for _, id := range ids {
+		if err := ops.PackageVideoHLS(context.Background(), db, store, cfg.VideoDirs, id, opts, cfg.UploadTempDir); err != nil {
+			fmt.Printf("  ✗ 视频 %d: %v\n", id, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ✓ 视频 %d\n", id)
+	}
+
+	fmt.Printf("✓ 已打包 %d 个视频，失败 %d 个\n", len(ids)-failed, failed)
+	if failed > 0 && !*pending {
+		return errors.New("打包失败")
+	}
+	return nil
+}
+
+// runCourse 执行 course 子命令
+func runCourse(args []string) error {
+	if len(args) == 0 {
+		return errors.New("用法: course import -f <文件> | course export -id <课程ID> [-o <文件>] | course reindex [-all]\n" +
+			"      course roster-import -id <课程ID> -f <名单> [-dry-run] [-create-accounts] | course roster-export -id <课程ID> [-o <文件>] [-format csv|xlsx]")
+	}
+	action, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("course "+action, flag.ContinueOnError)
+	file := fs.String("f", "", "导入的JSON文件（import），或 CSV/XLSX 名单（roster-import）")
+	id := fs.Int("id", 0, "课程ID（export、roster-import、roster-export）")
+	out := fs.String("o", "", "导出文件，默认输出到标准输出（export、roster-export）")
+	all := fs.Bool("all", false, "重建全部课程的检索索引，默认只补建缺失的（reindex）")
+	dryRun := fs.Bool("dry-run", false, "只校验名单，不写入（roster-import）")
+	createAccounts := fs.Bool("create-accounts", false, "为不存在的学号创建账号（roster-import）")
+	format := fs.String("format", "", "导出格式 csv 或 xlsx，默认按 -o 的扩展名，否则为 csv（roster-export）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "import":
+		if *file == "" {
+			return errors.New("缺少 -f 参数")
+		}
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		var bundle ops.CourseBundle
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			return fmt.Errorf("解析课程文件失败: %v", err)
+		}
+		courseID, err := ops.ImportCourse(db, &bundle)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ 已导入课程《%s》，ID: %d\n", bundle.Title, courseID)
+		return nil
+
+	case "export":
+		if *id <= 0 {
+			return errors.New("缺少 -id 参数")
+		}
+		bundle, err := ops.ExportCourse(db, *id)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(bundle)
+
+	case "roster-import":
+		if *id <= 0 || *file == "" {
+			return errors.New("缺少 -id 或 -f 参数")
+		}
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		table, err := sheet.Read(data)
+		if err != nil {
+			return err
+		}
+		rows, err := ops.ParseRoster(table)
+		if err != nil {
+			return err
+		}
+		result, err := ops.ImportRoster(db, 0, *id, rows, ops.RosterImportOptions{DryRun: *dryRun, CreateAccounts: *createAccounts})
+		if err != nil {
+			return err
+		}
+		for _, row := range result.Rows {
+			switch {
+			case row.Error != "":
+				fmt.Printf("  第%d行 %s: %s\n", row.Line, row.StuID, row.Error)
+			case row.Password != "":
+				fmt.Printf("  第%d行 %s: 已创建账号，初始密码 %s\n", row.Line, row.StuID, row.Password)
+			}
+		}
+		prefix := "✓ 已导入"
+		if result.DryRun {
+			prefix = "✓ 校验完成（未写入），将导入"
+		}
+		fmt.Printf("%s: 加入 %d 人，新建账号 %d 人，已在课程中 %d 人，错误 %d 行\n",
+			prefix, result.Enrolled, result.Created, result.Existing, result.Failed)
+		return nil
+
+	case "roster-export":
+		if *id <= 0 {
+			return errors.New("缺少 -id 参数")
+		}
+		f := *format
+		if f == "" {
+			f = sheet.Format(*out)
+		}
+		if f == "" {
+			f = sheet.FormatCSV
+		}
+		report, err := ops.CourseRosterReport(db, 0, *id)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			file, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		return sheet.Write(w, f, ops.RosterTable(report))
+
+	case "reindex":
+		n, err := ops.ReindexCourses(context.Background(), db, *all)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ 已重建 %d 门课程的检索索引\n", n)
+		return nil
+
+	default:
+		return fmt.Errorf("未知的 course 操作: %s", action)
+	}
+}
+
+// runCache 执行 cache 子命令
+// 缓存位于服务进程内存中，因此通过管理接口通知运行中的服务清空
+func runCache(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "flush" {
+		return errors.New("用法: cache flush [-url <服务地址>]")
+	}
+
+	fs := flag.NewFlagSet("cache flush", flag.ContinueOnError)
+	baseURL := fs.String("url", cfg.BaseURL, "运行中服务的地址")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if cfg.AdminToken == "" {
+		return errors.New("未配置 ADMIN_TOKEN，无法调用管理接口")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(*baseURL, "/")+"/api/admin/cache/flush", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.AdminToken)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("调用服务失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("清空缓存失败: HTTP %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	fmt.Println("✓ 缓存已清空")
+	return nil
+}
+
+// runStorage 执行 storage 子命令
+func runStorage(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("用法: storage migrate -from <local|s3> -to <local|s3> [-prefix <前缀>] [-dry-run] | storage presign -key <对象键> [-ttl 15m]")
+	}
+	action, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("storage "+action, flag.ContinueOnError)
+	from := fs.String("from", "local", "源存储（migrate）")
+	to := fs.String("to", "s3", "目标存储（migrate）")
+	prefix := fs.String("prefix", "", "只处理该前缀下的对象（migrate）")
+	dryRun := fs.Bool("dry-run", false, "只统计需要复制的对象，不实际复制（migrate）")
+	key := fs.String("key", "", "对象键（presign），如 images/user/avatar-1.jpg")
+	ttl := fs.Duration("ttl", cfg.StoragePresignTTL, "预签名地址有效期（presign）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "migrate":
+		if *from == *to {
+			return errors.New("源存储和目标存储不能相同")
+		}
+		src, err := storage.Open(cfg, *from)
+		if err != nil {
+			return err
+		}
+		dst, err := storage.Open(cfg, *to)
+		if err != nil {
+			return err
+		}
+
+		stats, err := storage.Migrate(ctx, src, dst, *prefix, *dryRun, func(info storage.ObjectInfo, skipped bool) {
+			if skipped {
+				fmt.Printf("  - %s（已存在，跳过）\n", info.Key)
+			} else {
+				fmt.Printf("  + %s (%d 字节)\n", info.Key, info.Size)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		verb := "已复制"
+		if *dryRun {
+			verb = "需要复制"
+		}
+		fmt.Printf("✓ %s %d 个对象（%d 字节），跳过 %d 个\n", verb, stats.Copied, stats.Bytes, stats.Skipped)
+		return nil
+
+	case "presign":
+		if *key == "" {
+			return errors.New("缺少 -key 参数")
+		}
+		store, err := storage.Open(cfg, "")
+		if err != nil {
+			return err
+		}
+		u, err := store.PresignGet(ctx, *key, *ttl)
+		if err != nil {
+			return err
+		}
+		fmt.Println(u)
+		return nil
+
+	default:
+		return fmt.Errorf("未知的 storage 操作: %s", action)
+	}
+}
+
+// runDoctor 执行 doctor 子命令：检查配置、目录、数据库和图数据库
+// 存在关键问题时返回错误（退出码非0），便于在部署脚本中使用
+func runDoctor(cfg *config.Config) error {
+	problems := 0
+	report := func(ok bool, format string, a ...interface{}) {
+		icon := "✅"
+		if !ok {
+			icon = "❌"
+			problems++
+		}
+		fmt.Printf("  %s %s\n", icon, fmt.Sprintf(format, a...))
+	}
+	warn := func(format string, a ...interface{}) {
+		fmt.Printf("  ⚠️  %s\n", fmt.Sprintf(format, a...))
+	}
+
+	fmt.Println("=== 配置 ===")
+	fmt.Printf("  运行环境: %s, 服务地址: %s\n", cfg.Env, cfg.BaseURL)
+	if cfg.AdminToken == "" {
+		warn("未配置 ADMIN_TOKEN，管理接口和 /health/details 不可用")
+	}
+	if cfg.MediaSigningKey == "" {
+		warn("未配置 MEDIA_SIGNING_KEY，重启后已签发的视频和PDF链接会失效")
+	}
+	if cfg.IsProduction() && os.Getenv("DB_PASSWORD") == "" {
+		warn("生产环境正在使用默认数据库密码")
+	}
+
+	fmt.Println("\n=== 目录 ===")
+	dirGroups := []struct {
+		name string
+		dirs []string
+	}{
+		{"视频", cfg.VideoDirs},
+		{"PDF", cfg.PdfDirs},
+		{"图片", cfg.ImageDirs},
+		{"文章", cfg.ArticleDirs},
+	}
+	for _, g := range dirGroups {
+		dir := firstExisting(g.dirs)
+		if dir == "" {
+			warn("%s目录均不存在: %s", g.name, strings.Join(g.dirs, ", "))
+		} else {
+			report(true, "%s目录: %s", g.name, dir)
+		}
+	}
+
+	fmt.Println("\n=== 依赖 ===")
+	if _, err := handlers.InitGraphHandler(cfg.Neo4jURI, cfg.Neo4jUser, cfg.Neo4jPassword); err != nil {
+		warn("图数据库初始化失败: %v", err)
+	}
+	checker := newHealthChecker(cfg)
+	for _, result := range checker.Run(context.Background(), false).Checks {
+		ok := result.Status == health.StatusUp
+		detail := ""
+		if !ok {
+			detail = " - " + result.Error
+		}
+		if ok || result.Critical {
+			report(ok, "%s (%dms)%s", result.Name, result.DurationMs, detail)
+		} else {
+			warn("%s (%dms)%s", result.Name, result.DurationMs, detail)
+		}
+	}
+
+	fmt.Println("\n=== 数据库迁移 ===")
+	if db, err := openDB(); err != nil {
+		report(false, "无法检查迁移: %v", err)
+	} else if pending, err := migrate.Pending(db); err != nil {
+		report(false, "检查迁移失败: %v", err)
+	} else if len(pending) > 0 {
+		report(false, "有 %d 个迁移未执行，请运行 \"server migrate\"", len(pending))
+	} else {
+		report(true, "数据库结构已是最新")
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("发现 %d 个问题", problems)
+	}
+	fmt.Println("\n✓ 检查通过")
+	return nil
+}
+
+// firstExisting 返回第一个存在的目录，都不存在时返回空字符串
+func firstExisting(dirs []string) string {
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
@@ -1,39 +1,42 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"cybersecurity-platform-go/internal/config"
 	"cybersecurity-platform-go/internal/database"
 	"cybersecurity-platform-go/internal/handlers"
-
-	"github.com/joho/godotenv"
+	"cybersecurity-platform-go/internal/health"
+	"cybersecurity-platform-go/internal/hls"
+	"cybersecurity-platform-go/internal/hotsearch"
+	"cybersecurity-platform-go/internal/media"
+	"cybersecurity-platform-go/internal/ops"
+	"cybersecurity-platform-go/internal/static"
+	"cybersecurity-platform-go/internal/storage"
+	"cybersecurity-platform-go/internal/upload"
+	"cybersecurity-platform-go/internal/webui"
 )
 
 func main() {
-	fmt.Println("=== 网络安全平台后端（Go版本） ===")
-	fmt.Println("正在启动...")
-
-	// 1. 加载环境变量
-	envFile := ".env"
-	if os.Getenv("NODE_ENV") == "production" {
-		envFile = ".env.production"
-		fmt.Println("检测到生产环境")
-	}
-
-	err := godotenv.Load(envFile)
-	if err != nil {
-		log.Printf("注意：未找到 %s 文件，使用系统环境变量", envFile)
-	} else {
-		fmt.Printf("✓ 已加载环境变量文件: %s\n", envFile)
+	if err := runCLI(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(1)
 	}
+}
 
-	// 2. 加载配置
-	cfg := config.LoadConfig()
+// serve 启动HTTP服务（serve 子命令，也是未指定子命令时的默认行为）
+func serve(cfg *config.Config) error {
+	fmt.Println("=== 网络安全平台后端（Go版本） ===")
+	fmt.Println("正在启动...")
 
 	// 显示配置信息
 	fmt.Println("\n=== 配置信息 ===")
@@ -58,6 +61,10 @@ func main() {
 		fmt.Println("将继续启动服务，但数据库相关功能可能不可用")
 	} else {
 		fmt.Println("✓ 数据库连接成功")
+		// 代码依赖最新的数据库结构（如登录查询 students.disabled），有未执行的迁移时拒绝启动
+		if err := checkMigrations(); err != nil {
+			return err
+		}
 	}
 
 	// 4. 初始化图数据库连接
@@ -82,7 +89,7 @@ func main() {
 
 	// 5.1 登录路由
 	loginMux := handlers.RegisterLoginRoutes()
-	fmt.Println("✓ 登录API路由已注册: /api/login")
+	fmt.Println("✓ 登录API路由已注册: /api/login, /api/logout")
 
 	// 5.2 注册路由（假设您已有）
 	// registerMux := handlers.RegisterRoutes()
@@ -112,17 +119,26 @@ func main() {
 	graphMux := handlers.RegisterGraphRoutes()
 	fmt.Println("✓ 图数据库API路由已注册: /api/init-graph, /api/expand-node")
 
+	// 5.9 学习进度路由
+	progressMux := handlers.RegisterProgressRoutes()
+	fmt.Println("✓ 学习进度API路由已注册: /api/app/edu/pub/progress")
+
 	// 6. 创建主路由处理器
 	mainMux := http.NewServeMux()
 
 	// 7. 添加各个路由到主路由
 	mainMux.Handle("/api/videos/", videoMux)
 	mainMux.Handle("/api/login", loginMux)
+	mainMux.Handle("/api/logout", loginMux)
 	// mainMux.Handle("/api/register", registerMux)
 	mainMux.Handle("/api/courses/", courseMux)
 	mainMux.Handle("/api/student/", studentMux)
 	mainMux.Handle("/api/teachers/", teacherMux)
 	mainMux.Handle("/api/forum/", forumMux)
+	mainMux.Handle("/api/admin/", handlers.RegisterAdminRoutes(cfg.AdminToken))
+	mainMux.Handle("/api/app/edu/pub/progress", progressMux)
+	mainMux.Handle("/api/app/edu/pub/progress/", progressMux)
+	mainMux.Handle("/api/app/edu/pub/subject/", handlers.RegisterSubjectRoutes())
 	mainMux.Handle("/api/", graphMux)
 
 	fmt.Println("✓ 所有路由已添加到主路由")
@@ -130,84 +146,207 @@ func main() {
 	// 8. 添加静态文件服务（支持多个目录）
 	fmt.Println("\n=== 注册静态文件服务 ===")
 
+	// 上传文件和媒体文件使用配置的对象存储，远程存储时静态路由重定向到预签名地址
+	store, err := storage.Open(cfg, "")
+	if err != nil {
+		return fmt.Errorf("初始化存储失败: %v", err)
+	}
+	storage.SetDefault(store)
+	if local, ok := store.(*storage.Local); ok {
+		mainMux.Handle("/api/storage/", http.StripPrefix("/api/storage/", local))
+	}
+	fmt.Printf("✓ 对象存储: %s\n", cfg.StorageDriver)
+
+	// 课程视频和PDF只能通过签名链接访问
+	if cfg.MediaSigningKey == "" {
+		log.Println("⚠️  未配置 MEDIA_SIGNING_KEY，使用随机密钥（重启后已签发的媒体链接失效）")
+	}
+	signer := media.NewSigner([]byte(cfg.MediaSigningKey), cfg.MediaURLTTL)
+	media.SetDefault(signer)
+
+	// 视频后台打包为 HLS（配置 HLS_TRANSCODER 时生成多个清晰度）
+	hlsOpts := hls.Options{SegmentDuration: cfg.HLSSegmentDuration, Transcoder: cfg.HLSTranscoder}
+	hlsQueue := hls.NewQueue(100, func(ctx context.Context, videoID int64) error {
+		db, err := database.GetDB()
+		if err != nil {
+			return err
+		}
+		return ops.PackageVideoHLS(ctx, db, store, cfg.VideoDirs, videoID, hlsOpts, cfg.UploadTempDir)
+	})
+	hlsQueue.Start(context.Background())
+
+	// 热门搜索：定期根据搜索日志重新计算
+	hotOpts := hotsearch.DefaultOptions()
+	if cfg.HotSearchWindow > 0 {
+		hotOpts.Window = cfg.HotSearchWindow
+	}
+	if hotOpts.Recent > hotOpts.Window {
+		hotOpts.Recent = hotOpts.Window
+	}
+	hotsearch.SetDefault(hotsearch.NewBoard(hotOpts))
+	if cfg.HotSearchRefresh > 0 {
+		hotsearch.Run(context.Background(), cfg.HotSearchRefresh, func(ctx context.Context) error {
+			db, err := database.GetDB()
+			if err != nil {
+				return err
+			}
+			_, err = ops.RefreshHotSearch(ctx, db)
+			return err
+		})
+	}
+
+	// 退课期限（课程没有单独设置截止时间时）
+	ops.EnrollDropWindow = cfg.EnrollDropWindow
+
+	// 学生登录会话有效期
+	if cfg.StudentSessionTTL > 0 {
+		ops.StudentSessionTTL = cfg.StudentSessionTTL
+	}
+
+	// 评价课程需要达到的学习进度
+	if cfg.ReviewMinProgress >= 0 && cfg.ReviewMinProgress <= 100 {
+		ops.ReviewMinProgress = cfg.ReviewMinProgress
+	}
+
+	// 合作教师和助教的默认权限，配置无效时保留内置默认值
+	for role, value := range map[string]string{ops.RoleCoTeacher: cfg.CoTeacherPermissions, ops.RoleTA: cfg.TAPermissions} {
+		perms, err := ops.ParsePermissions(value)
+		if err != nil {
+			log.Printf("%s 的默认权限配置无效，使用内置默认值: %v", role, err)
+			continue
+		}
+		ops.DefaultRolePermissions[role] = perms
+	}
+
+	// 候补名单：定期作废过期的名额并顺延给下一位
+	if cfg.WaitlistOfferWindow > 0 {
+		ops.WaitlistOfferWindow = cfg.WaitlistOfferWindow
+	}
+	if cfg.WaitlistSweep > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.WaitlistSweep)
+			defer ticker.Stop()
+			for range ticker.C {
+				db, err := database.GetDB()
+				if err != nil {
+					continue
+				}
+				if n, err := ops.SweepWaitlists(context.Background(), db); err != nil {
+					log.Printf("处理候补名单失败: %v", err)
+				} else if n > 0 {
+					log.Printf("已向 %d 名候补学生发出名额", n)
+				}
+			}
+		}()
+	}
+
+	// 课程检索索引：补建还没有索引的课程（升级后首次启动或索引被清空时）
+	go func() {
+		db, err := database.GetDB()
+		if err != nil {
+			return
+		}
+		n, err := ops.ReindexCourses(context.Background(), db, false)
+		if err != nil {
+			log.Printf("补建课程检索索引失败: %v", err)
+		} else if n > 0 {
+			log.Printf("已补建 %d 门课程的检索索引", n)
+		}
+	}()
+
+	// 教师视频分片上传（断点续传），完成后写入对象存储并创建视频记录
+	finalize := func(ctx context.Context, sess *upload.Session, key string) (int64, error) {
+		videoID, err := handlers.CreateUploadedVideo(ctx, sess, key)
+		if err == nil && cfg.HLSAutoPackage && !hlsQueue.Enqueue(videoID) {
+			log.Printf("HLS打包队列已满，视频 %d 需要稍后用 video hls 命令打包", videoID)
+		}
+		return videoID, err
+	}
+	uploads := upload.NewService(upload.Config{
+		TempDir:  cfg.UploadTempDir,
+		MaxSize:  cfg.VideoMaxUploadMB << 20,
+		Quota:    cfg.VideoQuotaMB << 20,
+		MaxChunk: cfg.UploadChunkMaxMB << 20,
+	}, upload.NewSQLStore(database.GetDB), store, finalize)
+	mainMux.Handle("/api/uploads/", handlers.RegisterUploadRoutes(uploads))
+	fmt.Println("✓ 教师视频上传: /api/uploads/videos")
+	mainMux.Handle("/api/teacher/videos/", handlers.RegisterSubtitleRoutes(store))
+	fmt.Println("✓ 教师字幕管理: /api/teacher/videos/{id}/subtitles")
+	handlers.MountAuthoringRoutes(mainMux, store)
+	fmt.Println("✓ 教师课程编辑: /api/teacher/courses, /api/teacher/chapters, /api/teacher/lessons")
+	fmt.Println("✓ 教师选课审核: /api/teacher/requests, /api/teacher/invites")
+	fmt.Println("✓ 教师回复评价: /api/teacher/reviews/{id}/reply")
+
 	// 8.1 用户头像静态服务（多个可能位置）
-	registerMultiDirStatic(mainMux, "/img/user/", []string{
+	mainMux.Handle("/img/user/", uploadHandler(store, cfg, "/img/user/", storage.PrefixUserImages, []string{
 		cfg.UserImageDir,
 		filepath.Join(cwd(), "static", "images", "user"),
 		filepath.Join(cwd(), "MAIN", "server", "static", "images", "user"),
 		filepath.Join(cwd(), "assets", "image", "user"),
-	})
+	}, nil))
 	fmt.Println("✓ 用户头像静态服务: /img/user/")
 
 	// 8.2 课程图片静态服务
-	registerMultiDirStatic(mainMux, "/img/course/", []string{
+	mainMux.Handle("/img/course/", uploadHandler(store, cfg, "/img/course/", storage.PrefixCourseImages, []string{
 		cfg.CourseImageDir,
 		filepath.Join(cwd(), "static", "images", "course"),
 		filepath.Join(cwd(), "MAIN", "server", "static", "images", "course"),
 		filepath.Join(cwd(), "assets", "image", "course"),
-	})
+	}, defaultAssets("images/course")))
 	fmt.Println("✓ 课程图片静态服务: /img/course/")
 
 	// 8.3 视频静态服务（对应原 /api/videoing）
-	registerMultiDirStatic(mainMux, "/api/videoing/", cfg.VideoDirs)
-	fmt.Println("✓ 视频静态服务: /api/videoing/")
+	// HLS 播放列表中的分段地址按当前学生的授权重新签名
+	openPlaylist := func(ctx context.Context, name string) (io.ReadCloser, error) {
+		rc, _, err := store.Get(ctx, storage.PrefixVideos+name)
+		if errors.Is(err, storage.ErrNotExist) || errors.Is(err, storage.ErrInvalidKey) {
+			return nil, fs.ErrNotExist
+		}
+		return rc, err
+	}
+	mainMux.Handle("/api/videoing/", media.Protect(signer, handlers.MediaAccess,
+		media.SignPlaylists(signer, "/api/videoing/", openPlaylist,
+			uploadHandler(store, cfg, "/api/videoing/", storage.PrefixVideos, cfg.VideoDirs, nil))))
+	fmt.Println("✓ 视频静态服务: /api/videoing/（需要签名链接）")
 
 	// 8.4 PDF静态服务（对应原 /api/pdfs）
-	registerMultiDirStatic(mainMux, "/api/pdfs/", cfg.PdfDirs)
-	fmt.Println("✓ PDF静态服务: /api/pdfs/")
+	mainMux.Handle("/api/pdfs/", media.Protect(signer, handlers.MediaAccess,
+		uploadHandler(store, cfg, "/api/pdfs/", storage.PrefixPdfs, cfg.PdfDirs, defaultAssets("pdfs")), ".pdf"))
+	fmt.Println("✓ PDF静态服务: /api/pdfs/（PDF文件需要签名链接）")
 
 	// 8.5 论坛文章内容静态服务
-	registerMultiDirStatic(mainMux, "/api/forum/articles/content/", cfg.ArticleDirs)
+	registerMultiDirStatic(mainMux, "/api/forum/articles/content/", cfg.ArticleDirs, defaultAssets("forums/articles"))
 	fmt.Println("✓ 论坛文章内容静态服务: /api/forum/articles/content/")
 
 	// 8.6 论坛上传文件静态服务
-	mainMux.Handle("/api/forum/uploads/",
-		http.StripPrefix("/api/forum/uploads/", http.FileServer(http.Dir(cfg.ForumUploadDir))))
+	mainMux.Handle("/api/forum/uploads/", uploadHandler(store, cfg, "/api/forum/uploads/", storage.PrefixForumUploads, []string{cfg.ForumUploadDir}, nil))
 	fmt.Println("✓ 论坛上传文件静态服务: /api/forum/uploads/")
 
 	// 8.7 通用图片静态服务（备用）
-	registerMultiDirStatic(mainMux, "/images/", cfg.ImageDirs)
+	registerMultiDirStatic(mainMux, "/images/", cfg.ImageDirs, defaultAssets("images"))
 	fmt.Println("✓ 通用图片静态服务: /images/")
 
 	// 9. 健康检查端点
-	mainMux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		
-		// 检查各个目录状态
-		videoDirStatus := checkDirStatus(cfg.GetFirstExistingVideoDir())
-		pdfDirStatus := checkDirStatus(cfg.GetFirstExistingPdfDir())
-		articleDirStatus := checkDirStatus(cfg.GetFirstExistingArticleDir())
-		userImageDirStatus := checkDirStatus(cfg.UserImageDir)
-		
-		fmt.Fprintf(w, `{
-			"status": "ok", 
-			"service": "cybersecurity-platform-go", 
-			"version": "1.0.0",
-			"environment": "%s",
-			"database": {
-				"mysql": "%s",
-				"neo4j": "%s"
-			},
-			"directories": {
-				"videos": {"path": "%s", "exists": %t},
-				"pdfs": {"path": "%s", "exists": %t},
-				"articles": {"path": "%s", "exists": %t},
-				"user_images": {"path": "%s", "exists": %t}
-			}
-		}`, 
-		cfg.Env,
-		databaseStatus(),
-		neo4jStatus(cfg),
-		cfg.GetFirstExistingVideoDir(), videoDirStatus,
-		cfg.GetFirstExistingPdfDir(), pdfDirStatus,
-		cfg.GetFirstExistingArticleDir(), articleDirStatus,
-		cfg.UserImageDir, userImageDirStatus)
-	})
-	fmt.Println("✓ 健康检查路由: /health")
-
-	// 10. 首页路由
-	mainMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	checker := newHealthChecker(cfg)
+	mainMux.HandleFunc("GET /livez", checker.LivenessHandler())
+	mainMux.HandleFunc("GET /readyz", checker.ReadinessHandler())
+	mainMux.HandleFunc("GET /health/details", handlers.AdminAuth(cfg.AdminToken, checker.DetailsHandler()))
+	// 兼容旧的 /health 路径，行为与 /readyz 一致
+	mainMux.HandleFunc("GET /health", checker.ReadinessHandler())
+	fmt.Println("✓ 健康检查路由: /livez, /readyz, /health/details")
+
+	// 10. 首页路由（启用嵌入式前端时，首页为前端入口，服务状态页移到 /status）
+	statusPath := "/"
+	if cfg.EmbeddedUI {
+		if dist, ok := webui.Dist(); ok {
+			mainMux.Handle("/", static.NewFSHandler(dist, "index.html"))
+			statusPath = "/status"
+			fmt.Println("✓ 嵌入式前端: /")
+		} else {
+			log.Println("⚠️  已设置 EMBEDDED_UI，但当前程序未包含前端文件（需使用 -tags embedui 构建）")
+		}
+	}
+	mainMux.HandleFunc(statusPath, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
 		
@@ -540,7 +679,7 @@ func main() {
                     </div>
                     <div class="info-item">
                         <div class="info-label">MySQL数据库</div>
-                        <div class="info-value">` + databaseStatus() + `</div>
+                        <div class="info-value">` + databaseStatus(r.Context(), checker) + `</div>
                     </div>
                     <div class="info-item">
                         <div class="info-label">Neo4j图数据库</div>
@@ -660,17 +799,15 @@ func main() {
                 <a href="/health" class="link-button">健康检查</a>
                 <a href="/api/courses" class="link-button">课程列表</a>
                 <a href="/api/forum/categories" class="link-button">论坛分类</a>
-                <a href="/img/user/" class="link-button">用户头像</a>
-                <a href="/api/videoing/" class="link-button">视频文件</a>
             </div>
         </footer>
     </div>
 </body>
 </html>`
 		
-		fmt.Fprintf(w, html)
+		io.WriteString(w, html)
 	})
-	fmt.Println("✓ 首页路由: /")
+	fmt.Printf("✓ 服务状态页: %s\n", statusPath)
 
 	// 11. 设置服务器地址
 	serverAddr := ":" + cfg.Port
@@ -679,7 +816,7 @@ func main() {
 	fmt.Println("\n=== 启动信息 ===")
 	fmt.Printf("服务器将启动在: %s\n", cfg.BaseURL)
 	fmt.Printf("首页: %s\n", cfg.BaseURL)
-	fmt.Printf("健康检查: %s/health\n", cfg.BaseURL)
+	fmt.Printf("健康检查: %s/livez, %s/readyz\n", cfg.BaseURL, cfg.BaseURL)
 	fmt.Printf("用户头像: %s/img/user/\n", cfg.BaseURL)
 	fmt.Printf("课程图片: %s/img/course/\n", cfg.BaseURL)
 	fmt.Printf("视频文件: %s/api/videoing/\n", cfg.BaseURL)
@@ -713,8 +850,9 @@ func main() {
 	fmt.Println("\n🚀 启动HTTP服务器...")
 	log.Printf("服务已启动: %s", cfg.BaseURL)
 	if err := http.ListenAndServe(serverAddr, mainMux); err != nil {
-		log.Fatalf("启动服务器失败: %v", err)
+		return fmt.Errorf("启动服务器失败: %v", err)
 	}
+	return nil
 }
 
 // 获取当前工作目录
@@ -727,37 +865,18 @@ func cwd() string {
 }
 
 // registerMultiDirStatic 注册多目录静态服务
-func registerMultiDirStatic(mux *http.ServeMux, prefix string, dirs []string) {
-	mux.Handle(prefix, http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 设置CORS头
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		
-		// 处理OPTIONS请求
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-		
-		// 只允许GET请求
-		if r.Method != "GET" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		
-		// 尝试每个目录，直到找到文件
-		for _, dir := range dirs {
-			filePath := filepath.Join(dir, r.URL.Path)
-			if _, err := os.Stat(filePath); err == nil {
-				http.ServeFile(w, r, filePath)
-				return
-			}
-		}
-		
-		// 所有目录都没找到文件
-		http.NotFound(w, r)
-	})))
+// fallback 不为nil时，所有目录都找不到的文件会从中查找（嵌入的默认资源）
+func registerMultiDirStatic(mux *http.ServeMux, prefix string, dirs []string, fallback fs.FS) {
+	mux.Handle(prefix, http.StripPrefix(prefix, static.NewDirHandler(dirs, fallback)))
+}
+
+// uploadHandler 创建上传文件的静态服务处理器
+// 本地存储时按多目录方式直接发送文件；远程存储时重定向到对象的预签名地址
+func uploadHandler(store storage.Storage, cfg *config.Config, prefix, keyPrefix string, dirs []string, fallback fs.FS) http.Handler {
+	if _, ok := store.(*storage.Local); ok {
+		return http.StripPrefix(prefix, static.NewDirHandler(dirs, fallback))
+	}
+	return http.StripPrefix(prefix, storage.RedirectHandler(store, keyPrefix, cfg.StoragePresignTTL, fallback))
 }
 
 // 检查目录状态
@@ -803,20 +922,28 @@ func getStatusColor(exists bool) string {
 	return "#dc3545"
 }
 
-func databaseStatus() string {
-	if err := database.TestConnection(); err == nil {
+func databaseStatus(ctx context.Context, checker *health.Checker) string {
+	if result, ok := checker.Result(ctx, "mysql"); ok && result.Status == health.StatusUp {
 		return "✅ 已连接"
 	}
 	return "❌ 未连接"
 }
 
-func neo4jStatus(cfg *config.Config) string {
-	if cfg.Neo4jURI == "" || cfg.Neo4jUser == "" || cfg.Neo4jPassword == "" {
-		return "未配置"
-	}
-	
-	if gh := handlers.GetGraphHandler(); gh != nil {
-		return "已连接"
+// newHealthChecker 根据配置组装依赖检查
+// 图谱接口目前返回内置数据、不连接 Neo4j，没有可以探测的依赖，因此不加入检查
+func newHealthChecker(cfg *config.Config) *health.Checker {
+	checks := []health.Check{
+		health.PingCheck("mysql", true, database.Ping),
+		health.FuncCheck("cache", false, handlers.PingForumCache),
+		health.WritableDirCheck("dir:user_images", cfg.UserImageDir),
+		health.WritableDirCheck("dir:course_images", cfg.CourseImageDir),
+		health.WritableDirCheck("dir:forum_uploads", cfg.ForumUploadDir),
+		health.DiskSpaceCheck("disk", cfg.ForumUploadDir, uint64(cfg.MinFreeDiskMB)<<20),
 	}
-	return "连接失败"
-}
\ No newline at end of file
+
+	checker := health.NewChecker(cfg.HealthCheckTimeout, cfg.HealthCacheTTL, checks...)
+	checker.SetInfo("service", "cybersecurity-platform-go")
+	checker.SetInfo("version", "1.0.0")
+	checker.SetInfo("environment", cfg.Env)
+	return checker
+}
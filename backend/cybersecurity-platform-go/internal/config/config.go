@@ -3,9 +3,14 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
 )
 
 // Config 应用配置
@@ -34,9 +39,85 @@ type Config struct {
 	Neo4jURI     string
 	Neo4jUser    string
 	Neo4jPassword string
+	
+	// 使用嵌入到二进制中的前端和默认资源（需要以 -tags embedui 构建）
+	EmbeddedUI bool
+	
+	// 管理接口配置
+	AdminToken string // 管理员令牌（/health/details 等接口使用）
+	
+	// 健康检查配置
+	HealthCheckTimeout time.Duration // 单项检查超时时间
+	HealthCacheTTL     time.Duration // 检查结果缓存时间
+	MinFreeDiskMB      int64         // 上传目录所在磁盘的最小剩余空间（MB）
+	
+	// 对象存储配置（上传文件和媒体文件）
+	StorageDriver     string        // local 或 s3
+	StorageLocalRoot  string        // 本地存储根目录
+	StorageSigningKey string        // 本地存储预签名地址的签名密钥
+	StoragePresignTTL time.Duration // 预签名地址有效期
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3PathStyle       bool // MinIO 等自建服务通常需要路径形式的地址
+	
+	// 课程媒体（视频、PDF）签名链接配置
+	MediaSigningKey string        // 签名密钥，多实例部署时必须一致
+	MediaURLTTL     time.Duration // 签名链接有效期（需覆盖一次完整的视频播放）
+	
+	// 教师视频分片上传配置
+	UploadTempDir    string // 分片暂存目录
+	VideoMaxUploadMB int64  // 单个视频最大大小（MB）
+	VideoQuotaMB     int64  // 每位教师的视频存储配额（MB），0 表示不限制
+	UploadChunkMaxMB int64  // 单个分片最大大小（MB）
+	
+	// 视频 HLS 打包配置
+	HLSTranscoder      string        // ffmpeg 兼容的转码程序路径，为空时只重新封装（不生成多清晰度）
+	HLSSegmentDuration time.Duration // 分段时长
+	HLSAutoPackage     bool          // 上传完成后自动打包
+	
+	// 热门搜索配置
+	HotSearchRefresh time.Duration // 重新计算的间隔
+	HotSearchWindow  time.Duration // 统计最近多长时间的搜索
+	
+	// 候补名单配置
+	WaitlistOfferWindow time.Duration // 候补学生获得名额后的选课期限
+	WaitlistSweep       time.Duration // 处理过期名额、顺延候补的间隔
+
+	// 退课配置
+	EnrollDropWindow time.Duration // 课程没有设置退课截止时间时，加入后可以退课的时长，0 表示不限制
+
+	// 学生登录会话有效期
+	StudentSessionTTL time.Duration
+
+	// 课程评价配置
+	ReviewMinProgress int // 在修学生评价课程需要达到的学习进度（百分比）
+
+	// 合作教师和助教的默认权限（逗号分隔：content、students、reviews、publish），课程可以单独设置
+	CoTeacherPermissions string
+	TAPermissions        string
 }
 
-// LoadConfig 加载配置
+// Load 加载环境变量文件并构建配置
+// 服务器和所有命令行子命令都通过它获取配置，保证数据库、目录等设置一致
+func Load() *Config {
+	envFile := ".env"
+	if os.Getenv("NODE_ENV") == "production" {
+		envFile = ".env.production"
+	}
+
+	if err := godotenv.Load(envFile); err != nil {
+		log.Printf("注意：未找到 %s 文件，使用系统环境变量", envFile)
+	} else {
+		log.Printf("已加载环境变量文件: %s", envFile)
+	}
+
+	return LoadConfig()
+}
+
+// LoadConfig 根据当前环境变量构建配置
 func LoadConfig() *Config {
 	// 确定环境
 	env := os.Getenv("NODE_ENV")
@@ -114,6 +195,39 @@ func LoadConfig() *Config {
 		Neo4jURI:      getEnvOrDefault("NEO4J_URI", "bolt://localhost:7687"),
 		Neo4jUser:     getEnvOrDefault("NEO4J_USER", "neo4j"),
 		Neo4jPassword: getEnvOrDefault("NEO4J_PASSWORD", "hukaile5206"),
+		EmbeddedUI:    getEnvBool("EMBEDDED_UI", false),
+		AdminToken:    os.Getenv("ADMIN_TOKEN"),
+		HealthCheckTimeout: getEnvDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+		HealthCacheTTL:     getEnvDuration("HEALTH_CACHE_TTL", 5*time.Second),
+		MinFreeDiskMB:      getEnvInt64("HEALTH_MIN_FREE_DISK_MB", 512),
+		StorageDriver:      getEnvOrDefault("STORAGE_DRIVER", "local"),
+		StorageLocalRoot:   getEnvOrDefault("STORAGE_LOCAL_ROOT", filepath.Join(cwd, "static")),
+		StorageSigningKey:  os.Getenv("STORAGE_SIGNING_KEY"),
+		StoragePresignTTL:  getEnvDuration("STORAGE_PRESIGN_TTL", 15*time.Minute),
+		S3Endpoint:         os.Getenv("S3_ENDPOINT"),
+		S3Region:           getEnvOrDefault("S3_REGION", "us-east-1"),
+		S3Bucket:           os.Getenv("S3_BUCKET"),
+		S3AccessKey:        os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:        os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle:        getEnvBool("S3_PATH_STYLE", true),
+		MediaSigningKey:    os.Getenv("MEDIA_SIGNING_KEY"),
+		MediaURLTTL:        getEnvDuration("MEDIA_URL_TTL", 2*time.Hour),
+		UploadTempDir:      getEnvOrDefault("UPLOAD_TMP_DIR", filepath.Join(cwd, "tmp", "uploads")),
+		VideoMaxUploadMB:   getEnvInt64("VIDEO_MAX_UPLOAD_MB", 8192),
+		VideoQuotaMB:       getEnvInt64("VIDEO_QUOTA_MB", 20480),
+		UploadChunkMaxMB:   getEnvInt64("UPLOAD_CHUNK_MAX_MB", 64),
+		HLSTranscoder:      os.Getenv("HLS_TRANSCODER"),
+		HLSSegmentDuration: getEnvDuration("HLS_SEGMENT_DURATION", 6*time.Second),
+		HLSAutoPackage:     getEnvBool("HLS_AUTO_PACKAGE", true),
+		HotSearchRefresh:   getEnvDuration("HOT_SEARCH_REFRESH", 5*time.Minute),
+		HotSearchWindow:    getEnvDuration("HOT_SEARCH_WINDOW", 7*24*time.Hour),
+		WaitlistOfferWindow: getEnvDuration("WAITLIST_OFFER_WINDOW", 48*time.Hour),
+		WaitlistSweep:       getEnvDuration("WAITLIST_SWEEP_INTERVAL", time.Minute),
+		EnrollDropWindow:    getEnvDuration("ENROLL_DROP_WINDOW", 14*24*time.Hour),
+		StudentSessionTTL:   getEnvDuration("STUDENT_SESSION_TTL", 7*24*time.Hour),
+		ReviewMinProgress:   int(getEnvInt64("REVIEW_MIN_PROGRESS", 30)),
+		CoTeacherPermissions: getEnvOrDefault("COTEACHER_PERMISSIONS", "content,students,reviews"),
+		TAPermissions:        getEnvOrDefault("TA_PERMISSIONS", "students"),
 	}
 }
 
@@ -139,6 +253,48 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvDuration 获取时长类型的环境变量（如 "5s"、"1m"），解析失败时返回默认值
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Printf("警告：环境变量 %s 的值 %q 不是有效的时长，使用默认值 %s\n", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getEnvBool 获取布尔类型的环境变量（true/false/1/0），解析失败时返回默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Printf("警告：环境变量 %s 的值 %q 不是有效的布尔值，使用默认值 %t\n", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+// getEnvInt64 获取整数类型的环境变量，解析失败时返回默认值
+func getEnvInt64(key string, defaultValue int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		fmt.Printf("警告：环境变量 %s 的值 %q 不是有效的整数，使用默认值 %d\n", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
 // ensureDirExists 确保目录存在
 func ensureDirExists(dir string) {
 	if dir == "" || dir == "." {
@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -27,8 +29,16 @@ type DBConfig struct {
 var (
 	db   *sql.DB
 	once sync.Once
+
+	// configuredDSN 由 Configure 设置，为空时从环境变量构建
+	configuredDSN string
 )
 
+// Configure 设置数据库连接字符串，需在第一次调用 GetDB 之前调用
+func Configure(dsn string) {
+	configuredDSN = dsn
+}
+
 // GetConfig 获取数据库配置
 func GetConfig() *DBConfig {
 	return &DBConfig{
@@ -50,8 +60,11 @@ func GetDB() (*sql.DB, error) {
 		config := GetConfig()
 		
 		// 构建连接字符串
-		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			config.User, config.Password, config.Host, config.Port, config.Database)
+		dsn := configuredDSN
+		if dsn == "" {
+			dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+				config.User, config.Password, config.Host, config.Port, config.Database)
+		}
 		
 		// 打开数据库连接
 		db, err = sql.Open("mysql", dsn)
@@ -112,4 +125,17 @@ func TestConnection() error {
 	
 	log.Println("数据库连接测试成功！")
 	return nil
-}
\ No newline at end of file
+}
+
+// Ping 在给定上下文内检查数据库是否可用
+// 与 TestConnection 不同，它不输出日志，适合健康检查等高频调用场景
+func Ping(ctx context.Context) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+	if db == nil {
+		return errors.New("数据库未初始化")
+	}
+	return db.PingContext(ctx)
+}
@@ -0,0 +1,216 @@
+// internal/handlers/admin.go
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"cybersecurity-platform-go/internal/hotsearch"
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// RegisterAdminRoutes 注册管理接口路由（全部需要管理员令牌）
+func RegisterAdminRoutes(token string) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// 清空内存缓存（命令行 cache flush 通过该接口通知运行中的服务）
+	mux.HandleFunc("POST /api/admin/cache/flush", AdminAuth(token, flushCacheHandler))
+
+	// 课程审核：按状态查询课程，发布、退回或归档
+	mux.HandleFunc("GET /api/admin/courses", AdminAuth(token, adminCoursesHandler))
+	mux.HandleFunc("PUT /api/admin/courses/{id}/status", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		courseStatusHandler(0, w, r)
+	}))
+	mux.HandleFunc("PUT /api/admin/courses/{id}/subjects", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		courseSubjectsHandler(0, w, r)
+	}))
+
+	// 课程学生名单：加入、移出学生，记录结课结果，导入和导出名单
+	mux.HandleFunc("GET /api/admin/courses/{id}/students", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		courseRosterHandler(0, w, r)
+	}))
+	mux.HandleFunc("PUT /api/admin/courses/{id}/students/{stuId}", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		setEnrollmentHandler(0, w, r)
+	}))
+	mux.HandleFunc("POST /api/admin/courses/{id}/students/import", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		importRosterHandler(0, w, r)
+	}))
+	mux.HandleFunc("GET /api/admin/courses/{id}/students/export", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		exportRosterHandler(0, w, r)
+	}))
+
+	// 先修课程规则
+	mux.HandleFunc("GET /api/admin/courses/{id}/prerequisites", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		coursePrerequisitesHandler(0, w, r)
+	}))
+	mux.HandleFunc("PUT /api/admin/courses/{id}/prerequisites", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		setCoursePrerequisitesHandler(0, w, r)
+	}))
+
+	// 课程教师和角色权限
+	mux.HandleFunc("GET /api/admin/courses/{id}/teachers", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		courseTeamHandler(0, w, r)
+	}))
+	mux.HandleFunc("PUT /api/admin/courses/{id}/teachers", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		setCourseTeachersHandler(0, w, r)
+	}))
+	mux.HandleFunc("PUT /api/admin/courses/{id}/permissions", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		setRolePermissionsHandler(0, w, r)
+	}))
+
+	// 选课审核和邀请码
+	mux.HandleFunc("GET /api/admin/requests", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		enrollmentRequestsHandler(0, w, r)
+	}))
+	mux.HandleFunc("PUT /api/admin/requests/{id}", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		reviewRequestHandler(0, w, r)
+	}))
+	mux.HandleFunc("GET /api/admin/courses/{id}/invites", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		invitesHandler(0, w, r)
+	}))
+	mux.HandleFunc("POST /api/admin/courses/{id}/invites", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		createInviteHandler(0, w, r)
+	}))
+	mux.HandleFunc("DELETE /api/admin/invites/{id}", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		revokeInviteHandler(0, w, r)
+	}))
+
+	// 课程评价审核和回复
+	mux.HandleFunc("GET /api/admin/reviews", AdminAuth(token, adminReviewsHandler))
+	mux.HandleFunc("PUT /api/admin/reviews/{id}", AdminAuth(token, moderateReviewHandler))
+	mux.HandleFunc("PUT /api/admin/reviews/{id}/reply", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		replyReviewHandler(0, w, r)
+	}))
+	mux.HandleFunc("GET /api/admin/courses/{id}/reviews", AdminAuth(token, func(w http.ResponseWriter, r *http.Request) {
+		courseReviewsManageHandler(0, w, r)
+	}))
+
+	// 课程分类管理
+	mux.HandleFunc("GET /api/admin/subjects", AdminAuth(token, adminSubjectsHandler))
+	mux.HandleFunc("POST /api/admin/subjects", AdminAuth(token, createSubjectHandler))
+	mux.HandleFunc("PUT /api/admin/subjects/{id}", AdminAuth(token, updateSubjectHandler))
+	mux.HandleFunc("DELETE /api/admin/subjects/{id}", AdminAuth(token, deleteSubjectHandler))
+
+	// 热门搜索：查看当前列表，置顶或屏蔽搜索词
+	mux.HandleFunc("GET /api/admin/search/hot", AdminAuth(token, adminHotSearchHandler))
+	mux.HandleFunc("PUT /api/admin/search/terms", AdminAuth(token, setSearchTermHandler))
+
+	return mux
+}
+
+// flushCacheHandler 清空缓存处理器
+func flushCacheHandler(w http.ResponseWriter, r *http.Request) {
+	FlushCaches()
+	log.Println("内存缓存已清空")
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(BaseResponse{
+		Code:    20000,
+		Message: "缓存已清空",
+	})
+}
+
+// adminCoursesHandler 按状态查询课程（默认为待审核）
+func adminCoursesHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = ops.CourseReview
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	courses, err := ops.CoursesByStatus(db, status)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, courses)
+}
+
+// adminHotSearchHandler 当前热门搜索（含得分）和管理员设置的规则
+func adminHotSearchHandler(w http.ResponseWriter, r *http.Request) {
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	rules, err := ops.SearchTermRules(r.Context(), db)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	terms, updated := hotsearch.Default().Terms()
+	sendAuthoringData(w, map[string]interface{}{
+		"list":      terms,
+		"rules":     rules,
+		"updatedAt": updated,
+	})
+}
+
+// setSearchTermHandler 置顶、屏蔽或取消搜索词规则，并立即重新计算热门搜索
+func setSearchTermHandler(w http.ResponseWriter, r *http.Request) {
+	var rule ops.SearchTermRule
+	if !decodeAuthoring(w, r, &rule) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.SetSearchTerm(r.Context(), db, rule); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	terms, err := ops.RefreshHotSearch(r.Context(), db)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, map[string]interface{}{"list": terms})
+}
+
+// AdminAuth 管理员令牌校验中间件
+// 令牌可通过 "Authorization: Bearer <token>" 或前端统一使用的 "X-Token" 请求头传入；
+// 未配置令牌时拒绝所有请求，避免管理接口在默认配置下被公开
+func AdminAuth(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if token == "" {
+			sendAdminError(w, http.StatusForbidden, 40300, "未配置管理员令牌，管理接口已禁用")
+			return
+		}
+
+		provided := requestToken(r)
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			sendAdminError(w, http.StatusUnauthorized, 40100, "管理员认证失败")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// requestToken 从请求头中提取令牌
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return strings.TrimSpace(r.Header.Get("X-Token"))
+}
+
+// sendAdminError 发送管理接口错误响应
+func sendAdminError(w http.ResponseWriter, httpStatus, code int, message string) {
+	w.WriteHeader(httpStatus)
+	response := map[string]interface{}{
+		"code":    code,
+		"message": message,
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("编码错误响应失败: %v", err)
+	}
+}
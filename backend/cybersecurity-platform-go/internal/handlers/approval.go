@@ -0,0 +1,190 @@
+// internal/handlers/approval.go
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// reviewRequest 审核选课申请
+type reviewRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note"` // 审核意见，会写入发给学生的通知
+}
+
+// cancelEnrollmentRequestHandler 学生撤回自己待审核的选课申请（学生取自登录会话）
+func cancelEnrollmentRequestHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var req studentCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数解析失败")
+		return
+	}
+	if req.CourseID <= 0 {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数不完整")
+		return
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+
+	switch err := ops.CancelEnrollmentRequest(db, stuID, req.CourseID); err {
+	case nil:
+	case ops.ErrRequestNotFound:
+		sendStudentError(w, http.StatusOK, 40010, "没有待审核的选课申请")
+		return
+	default:
+		log.Printf("撤回选课申请失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(StudentJoinResponse{Code: 20000, Message: "已撤回选课申请"})
+}
+
+func teacherEnrollmentRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	enrollmentRequestsHandler(teacherID, w, r)
+}
+
+// enrollmentRequestsHandler 审核队列，可按 status 和 courseId 筛选，teacherID 为0表示管理员（全部课程）
+func enrollmentRequestsHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", ops.RequestPending, ops.RequestApproved, ops.RequestRejected, ops.RequestCancelled:
+	default:
+		sendAdminError(w, http.StatusBadRequest, 40000, "无效的申请状态")
+		return
+	}
+	var courseID int
+	if s := r.URL.Query().Get("courseId"); s != "" {
+		id, err := strconv.Atoi(s)
+		if err != nil || id <= 0 {
+			sendAdminError(w, http.StatusBadRequest, 40000, "无效的课程ID")
+			return
+		}
+		courseID = id
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	list, err := ops.EnrollmentRequests(db, teacherID, courseID, status)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, list)
+}
+
+func teacherReviewRequestHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	reviewRequestHandler(teacherID, w, r)
+}
+
+// reviewRequestHandler 通过或拒绝选课申请，teacherID 为0表示管理员
+func reviewRequestHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	requestID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req reviewRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.ReviewEnrollmentRequest(db, teacherID, int64(requestID), req.Approve, req.Note); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func teacherInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	invitesHandler(teacherID, w, r)
+}
+
+// invitesHandler 课程的邀请码列表，teacherID 为0表示管理员
+func invitesHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	list, err := ops.CourseInvites(db, teacherID, courseID)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, list)
+}
+
+func teacherCreateInviteHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	createInviteHandler(teacherID, w, r)
+}
+
+// createInviteHandler 创建邀请码，teacherID 为0表示管理员
+func createInviteHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var in ops.InviteInput
+	if !decodeAuthoring(w, r, &in) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	invite, err := ops.CreateInvite(db, teacherID, courseID, in)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, invite)
+}
+
+func teacherRevokeInviteHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	revokeInviteHandler(teacherID, w, r)
+}
+
+// revokeInviteHandler 作废邀请码，teacherID 为0表示管理员
+func revokeInviteHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	inviteID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.RevokeInvite(db, teacherID, inviteID); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
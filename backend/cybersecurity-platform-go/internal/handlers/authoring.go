@@ -0,0 +1,576 @@
+// internal/handlers/authoring.go
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+	"cybersecurity-platform-go/internal/storage"
+)
+
+// 课时PDF讲义最大大小
+const maxLessonPdfSize = 50 << 20
+
+// RegisterAuthoringRoutes 注册教师编辑课程的路由，全部需要教师令牌，只能修改自己讲授的课程（teacher_courses）；
+// 合作教师和助教只能进行所在角色有权限的操作，设置课程教师和权限、删除课程只有负责人可以
+//
+//	GET    /api/teacher/courses                       我讲授的课程
+//	POST   /api/teacher/courses                       创建课程
+//	GET    /api/teacher/courses/{id}                  课程结构（章节和课时）
+//	PUT    /api/teacher/courses/{id}                  修改课程信息
+//	DELETE /api/teacher/courses/{id}                  删除课程（没有学生选修时）
+//	POST   /api/teacher/courses/{id}/chapters         添加章节
+//	PUT    /api/teacher/courses/{id}/chapters/order   调整章节顺序
+//	PUT    /api/teacher/courses/{id}/schedule         按周期设置各章节的开放时间
+//	PUT    /api/teacher/courses/{id}/status           提交审核、撤回或归档
+//	PUT    /api/teacher/courses/{id}/subjects         设置课程分类
+//	GET    /api/teacher/courses/{id}/students         学生名单（可按 status 筛选）
+//	PUT    /api/teacher/courses/{id}/students/{stuId} 加入、移出学生或记录结课结果
+//	POST   /api/teacher/courses/{id}/students/import  导入学生名单（CSV 或 XLSX）
+//	GET    /api/teacher/courses/{id}/students/export  导出学生名单、学习进度和成绩
+//	GET    /api/teacher/courses/{id}/prerequisites    先修课程规则
+//	PUT    /api/teacher/courses/{id}/prerequisites    设置先修课程规则（{"groups":[[1],[2,5]]}）
+//	GET    /api/teacher/courses/{id}/teachers         课程的教师、角色和权限
+//	PUT    /api/teacher/courses/{id}/teachers         设置课程的教师和角色（数组顺序即显示顺序）
+//	PUT    /api/teacher/courses/{id}/permissions      设置合作教师或助教的权限
+//	GET    /api/teacher/courses/{id}/invites          课程的邀请码
+//	POST   /api/teacher/courses/{id}/invites          创建邀请码
+//	DELETE /api/teacher/invites/{id}                  作废邀请码
+//	GET    /api/teacher/requests                      选课申请审核队列（可按 status、courseId 筛选）
+//	PUT    /api/teacher/requests/{id}                 通过或拒绝选课申请
+//	GET    /api/teacher/courses/{id}/reviews          课程的全部评价（含审核中和未通过的）
+//	PUT    /api/teacher/reviews/{id}/reply            回复课程评价
+//	PUT    /api/teacher/chapters/{id}                 修改章节标题和开放时间
+//	DELETE /api/teacher/chapters/{id}                 删除章节及其课时
+//	PUT    /api/teacher/chapters/{id}/prerequisites   设置先修章节规则（同一课程内）
+//	POST   /api/teacher/chapters/{id}/lessons         添加课时
+//	PUT    /api/teacher/chapters/{id}/lessons/order   调整课时顺序
+//	PUT    /api/teacher/lessons/{id}                  修改课时（标题、视频、PDF、所属章节）
+//	DELETE /api/teacher/lessons/{id}                  删除课时
+//	PUT    /api/teacher/lessons/{id}/pdf              上传课时的PDF讲义（请求体为PDF文件）
+func RegisterAuthoringRoutes(store storage.Storage) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/teacher/courses", TeacherAuth(listTeacherCoursesHandler))
+	mux.HandleFunc("POST /api/teacher/courses", TeacherAuth(createCourseHandler))
+	mux.HandleFunc("GET /api/teacher/courses/{id}", TeacherAuth(courseOutlineHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}", TeacherAuth(updateCourseHandler))
+	mux.HandleFunc("DELETE /api/teacher/courses/{id}", TeacherAuth(deleteCourseHandler))
+	mux.HandleFunc("POST /api/teacher/courses/{id}/chapters", TeacherAuth(createChapterHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}/chapters/order", TeacherAuth(reorderChaptersHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}/schedule", TeacherAuth(scheduleChaptersHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}/status", TeacherAuth(teacherCourseStatusHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}/subjects", TeacherAuth(teacherCourseSubjectsHandler))
+	mux.HandleFunc("GET /api/teacher/courses/{id}/students", TeacherAuth(teacherRosterHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}/students/{stuId}", TeacherAuth(teacherSetEnrollmentHandler))
+	mux.HandleFunc("POST /api/teacher/courses/{id}/students/import", TeacherAuth(teacherImportRosterHandler))
+	mux.HandleFunc("GET /api/teacher/courses/{id}/students/export", TeacherAuth(teacherExportRosterHandler))
+	mux.HandleFunc("GET /api/teacher/courses/{id}/prerequisites", TeacherAuth(teacherCoursePrerequisitesHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}/prerequisites", TeacherAuth(teacherSetCoursePrerequisitesHandler))
+	mux.HandleFunc("GET /api/teacher/courses/{id}/teachers", TeacherAuth(teacherCourseTeamHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}/teachers", TeacherAuth(teacherSetCourseTeachersHandler))
+	mux.HandleFunc("PUT /api/teacher/courses/{id}/permissions", TeacherAuth(teacherSetRolePermissionsHandler))
+	mux.HandleFunc("GET /api/teacher/courses/{id}/invites", TeacherAuth(teacherInvitesHandler))
+	mux.HandleFunc("POST /api/teacher/courses/{id}/invites", TeacherAuth(teacherCreateInviteHandler))
+	mux.HandleFunc("DELETE /api/teacher/invites/{id}", TeacherAuth(teacherRevokeInviteHandler))
+	mux.HandleFunc("GET /api/teacher/requests", TeacherAuth(teacherEnrollmentRequestsHandler))
+	mux.HandleFunc("PUT /api/teacher/requests/{id}", TeacherAuth(teacherReviewRequestHandler))
+	mux.HandleFunc("GET /api/teacher/courses/{id}/reviews", TeacherAuth(teacherCourseReviewsHandler))
+	mux.HandleFunc("PUT /api/teacher/reviews/{id}/reply", TeacherAuth(teacherReplyReviewHandler))
+	mux.HandleFunc("PUT /api/teacher/chapters/{id}", TeacherAuth(updateChapterHandler))
+	mux.HandleFunc("DELETE /api/teacher/chapters/{id}", TeacherAuth(deleteChapterHandler))
+	mux.HandleFunc("PUT /api/teacher/chapters/{id}/prerequisites", TeacherAuth(setChapterPrerequisitesHandler))
+	mux.HandleFunc("POST /api/teacher/chapters/{id}/lessons", TeacherAuth(createLessonHandler))
+	mux.HandleFunc("PUT /api/teacher/chapters/{id}/lessons/order", TeacherAuth(reorderLessonsHandler))
+	mux.HandleFunc("PUT /api/teacher/lessons/{id}", TeacherAuth(updateLessonHandler))
+	mux.HandleFunc("DELETE /api/teacher/lessons/{id}", TeacherAuth(deleteLessonHandler))
+	mux.HandleFunc("PUT /api/teacher/lessons/{id}/pdf", TeacherAuth(func(w http.ResponseWriter, r *http.Request) {
+		uploadLessonPdfHandler(store, w, r)
+	}))
+
+	return mux
+}
+
+// authoringPrefixes 教师编辑课程的路由在主路由上挂载的路径前缀，
+// RegisterAuthoringRoutes 新增的路由不在这些前缀下时会落到 /api/ 的图谱路由返回404
+var authoringPrefixes = []string{
+	"/api/teacher/courses",
+	"/api/teacher/courses/",
+	"/api/teacher/chapters/",
+	"/api/teacher/lessons/",
+	"/api/teacher/invites/",
+	"/api/teacher/requests",
+	"/api/teacher/requests/",
+	"/api/teacher/reviews/",
+}
+
+// MountAuthoringRoutes 把教师编辑课程的路由挂载到主路由
+func MountAuthoringRoutes(mux *http.ServeMux, store storage.Storage) {
+	authoring := RegisterAuthoringRoutes(store)
+	for _, prefix := range authoringPrefixes {
+		mux.Handle(prefix, authoring)
+	}
+}
+
+// titleRequest 章节标题
+type titleRequest struct {
+	Title string `json:"title"`
+}
+
+// statusRequest 课程状态变更
+type statusRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"` // 管理员退回时的审核意见
+}
+
+// scheduleRequest 章节开放计划：第一章在 start 开放，之后每隔 everyDays 天开放一章
+type scheduleRequest struct {
+	Start     string `json:"start"`
+	EveryDays int    `json:"everyDays"`
+}
+
+// orderRequest ID列表（排序后的章节、课时，或课程所属的分类）
+type orderRequest struct {
+	IDs []int `json:"ids"`
+}
+
+func listTeacherCoursesHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	courses, err := ops.TeacherCourses(db, teacherID)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, courses)
+}
+
+func createCourseHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	var in ops.CourseInput
+	if !decodeAuthoring(w, r, &in) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	id, err := ops.CreateCourse(db, teacherID, in)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, map[string]int64{"id": id})
+}
+
+func courseOutlineHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	outline, err := ops.CourseOutlineFor(db, teacherID, courseID)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, outline)
+}
+
+func updateCourseHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var in ops.CourseInput
+	if !decodeAuthoring(w, r, &in) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.UpdateCourse(db, teacherID, courseID, in); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func deleteCourseHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.DeleteCourse(db, teacherID, courseID); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func scheduleChaptersHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req scheduleRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	start, err := ops.ParseReleaseAt(req.Start)
+	if err != nil || start == nil {
+		sendAdminError(w, http.StatusBadRequest, 40000, "无效的开始时间")
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	every := time.Duration(req.EveryDays) * 24 * time.Hour
+	if err := ops.ScheduleChapters(db, teacherID, courseID, *start, every); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func teacherCourseStatusHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseStatusHandler(teacherID, w, r)
+}
+
+// courseStatusHandler 变更课程状态，teacherID 为0表示管理员
+func courseStatusHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req statusRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.SetCourseStatus(db, teacherID, courseID, req.Status, req.Note); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func teacherCourseSubjectsHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseSubjectsHandler(teacherID, w, r)
+}
+
+// courseSubjectsHandler 设置课程分类，teacherID 为0表示管理员
+func courseSubjectsHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req orderRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.SetCourseSubjects(db, teacherID, courseID, req.IDs); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func createChapterHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req titleRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	id, err := ops.CreateChapter(db, teacherID, courseID, req.Title)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, map[string]int64{"id": id})
+}
+
+func reorderChaptersHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req orderRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.ReorderChapters(db, teacherID, courseID, req.IDs); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func updateChapterHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	chapterID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var in ops.ChapterInput
+	if !decodeAuthoring(w, r, &in) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.UpdateChapter(db, teacherID, chapterID, in); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func deleteChapterHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	chapterID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.DeleteChapter(db, teacherID, chapterID); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func createLessonHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	chapterID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var in ops.LessonInput
+	if !decodeAuthoring(w, r, &in) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	id, err := ops.CreateLesson(db, teacherID, chapterID, in)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, map[string]int64{"id": id})
+}
+
+func reorderLessonsHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	chapterID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req orderRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.ReorderLessons(db, teacherID, chapterID, req.IDs); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func updateLessonHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	lessonID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var in ops.LessonInput
+	if !decodeAuthoring(w, r, &in) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.UpdateLesson(db, teacherID, lessonID, in); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func deleteLessonHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	lessonID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.DeleteLesson(db, teacherID, lessonID); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+// uploadLessonPdfHandler 保存PDF讲义到 pdfs/lessons/<课时ID>.pdf 并关联到课时，重复上传会替换原文件
+func uploadLessonPdfHandler(store storage.Storage, w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	lessonID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if _, err := ops.LessonCourse(db, teacherID, lessonID); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLessonPdfSize))
+	if err != nil {
+		sendAdminError(w, http.StatusRequestEntityTooLarge, 41300, "PDF文件过大")
+		return
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		sendAdminError(w, http.StatusUnsupportedMediaType, 41500, "文件不是PDF")
+		return
+	}
+
+	name := fmt.Sprintf("lessons/%d.pdf", lessonID)
+	if err := store.Put(r.Context(), storage.PrefixPdfs+name, bytes.NewReader(data), int64(len(data)), "application/pdf"); err != nil {
+		log.Printf("保存PDF讲义失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "保存文件失败")
+		return
+	}
+
+	pdfURL := "/api/pdfs/" + name
+	if err := ops.UpdateLesson(db, teacherID, lessonID, ops.LessonInput{PdfURL: &pdfURL}); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, map[string]string{"pdfUrl": pdfURL})
+}
+
+// authoringPathID 解析路径中的ID
+func authoringPathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		sendAdminError(w, http.StatusBadRequest, 40000, "无效的ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeAuthoring 解析 JSON 请求体
+func decodeAuthoring(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(v); err != nil {
+		sendAdminError(w, http.StatusBadRequest, 40000, "参数解析失败")
+		return false
+	}
+	return true
+}
+
+func authoringDB(w http.ResponseWriter) (*sql.DB, bool) {
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return nil, false
+	}
+	return db, true
+}
+
+// sendAuthoringError 将 ops 返回的错误转换为响应
+func sendAuthoringError(w http.ResponseWriter, err error) {
+	var input ops.InputError
+	switch {
+	case errors.As(err, &input), errors.Is(err, ops.ErrBadOrder):
+		sendAdminError(w, http.StatusBadRequest, 40000, err.Error())
+	case errors.Is(err, ops.ErrNotCourseTeacher), errors.Is(err, ops.ErrVideoNotAttachable), errors.Is(err, ops.ErrNoCoursePermission):
+		sendAdminError(w, http.StatusForbidden, 40300, err.Error())
+	case errors.Is(err, ops.ErrCourseNotFound), errors.Is(err, ops.ErrChapterNotFound), errors.Is(err, ops.ErrLessonNotFound),
+		errors.Is(err, ops.ErrSubjectNotFound), errors.Is(err, ops.ErrUserNotFound), errors.Is(err, ops.ErrRequestNotFound),
+		errors.Is(err, ops.ErrInviteNotFound), errors.Is(err, ops.ErrReviewNotFound):
+		sendAdminError(w, http.StatusNotFound, 40400, err.Error())
+	case errors.Is(err, ops.ErrCourseHasStudents), errors.Is(err, ops.ErrInvalidTransition), errors.Is(err, ops.ErrCourseEmpty),
+		errors.Is(err, ops.ErrSubjectHasChildren), errors.Is(err, ops.ErrNotEnrolled), errors.Is(err, ops.ErrRequestReviewed),
+		errors.Is(err, ops.ErrCourseFull):
+		sendAdminError(w, http.StatusConflict, 40900, err.Error())
+	default:
+		log.Printf("编辑课程失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+	}
+}
+
+// sendAuthoringData 返回成功响应
+func sendAuthoringData(w http.ResponseWriter, data interface{}) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    20000,
+		"message": "success",
+		"data":    data,
+	})
+}
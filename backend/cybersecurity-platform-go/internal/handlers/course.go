@@ -1,25 +1,41 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"log"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/hotsearch"
+	"cybersecurity-platform-go/internal/ops"
+	"cybersecurity-platform-go/internal/search"
 )
 
 // Course 课程结构体
+// 搜索时 TitleHighlight 和 Snippet 为标出检索词的 HTML 片段（其余内容已转义）
 type Course struct {
-	ID          int     `json:"id"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Cover       string  `json:"cover"`
-	LessonNum   int     `json:"lessonNum"`
-	Credit      float64 `json:"credit"`
-	LimitCount  int     `json:"limitCount"`
+	ID             int     `json:"id"`
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	Cover          string  `json:"cover"`
+	LessonNum      int     `json:"lessonNum"`
+	Credit         float64 `json:"credit"`
+	LimitCount     int     `json:"limitCount"`
+	TitleHighlight string  `json:"titleHighlight,omitempty"`
+	Snippet        string  `json:"snippet,omitempty"`
+	EnrolledCount  int     `json:"enrolledCount"`
+	RemainingSeats int     `json:"remainingSeats"`
+	Rating         float64 `json:"rating"`
+	RatingCount    int     `json:"ratingCount"`
+	// RatingDistribution 1星到5星的评分人数
+	RatingDistribution [5]int `json:"ratingDistribution"`
+	Joined         bool    `json:"joined"` // 当前学生是否已选（已登录时）
 }
 
 // CourseListResponse 课程列表响应
@@ -32,7 +48,7 @@ type CourseListData struct {
 	CourseList  []Course          `json:"courseList"`
 	Total       int               `json:"total"`
 	HotList     []string          `json:"hotList"`
-	SubjectList []interface{}     `json:"subjectList"`
+	SubjectList []*ops.Subject    `json:"subjectList"`
 }
 
 // CourseDetailResponse 课程详情响应
@@ -54,23 +70,41 @@ type CourseDetail struct {
 	LessonNum   int        `json:"lessonNum"`
 	Credit      float64    `json:"credit"`
 	LimitCount  int        `json:"limitCount"`
-	Chapter     []Chapter  `json:"chapter"`
+	EnrollMode  string     `json:"enrollMode"` // 选课方式：open、approval、invite
+	Rating      ops.RatingSummary `json:"rating"`  // 评分汇总（被拒绝的评价不计入）
+	// Prerequisites 先修课程规则及当前学生是否满足，没有先修课程时不返回
+	Prerequisites *ops.PrerequisiteCheck `json:"prerequisites,omitempty"`
+	Chapter       []Chapter              `json:"chapter"`
+	// Teacher 课程负责人（兼容旧版页面），Teachers 为全部教师，按负责人、合作教师、助教的顺序排列
 	Teacher     TeacherInfo `json:"teacher"`
+	Teachers    []ops.CourseTeacher `json:"teachers"`
 }
 
 // Chapter 章节结构
+// 未到开放时间或未学完先修章节的章节仍然返回标题和课时列表，但不返回视频和讲义地址
 type Chapter struct {
-	ID       int        `json:"id"`
-	Title    string     `json:"title"`
-	State    int        `json:"state"`
-	Children []Lesson   `json:"children"`
+	ID            int                     `json:"id"`
+	Title         string                  `json:"title"`
+	State         int                     `json:"state"`
+	Locked        bool                    `json:"locked"`
+	ReleaseAt     *time.Time              `json:"releaseAt,omitempty"`
+	Prerequisites []ops.PrerequisiteGroup `json:"prerequisites,omitempty"` // 先修章节及是否已学完
+	Children      []Lesson                `json:"children"`
 }
 
+// 章节状态（Chapter.State）
+const (
+	ChapterOpen     = 0 // 已开放
+	ChapterFinished = 1 // 已学完（章节内的视频课时全部完成）
+	ChapterLocked   = 2 // 未到开放时间或未学完先修章节
+)
+
 // Lesson 课时结构
 type Lesson struct {
 	ID            int    `json:"id"`
 	Title         string `json:"title"`
 	VideoSourceID string `json:"videoSourceId"`
+	PdfURL        string `json:"pdfUrl,omitempty"`
 }
 
 // TeacherInfo 教师信息
@@ -107,6 +141,40 @@ func GetCourseList(w http.ResponseWriter, r *http.Request) {
 	
 	title := strings.TrimSpace(query.Get("title"))
 	order, _ := strconv.Atoi(query.Get("order"))
+	subjectID, _ := strconv.Atoi(query.Get("subjectId"))
+	
+	// 筛选条件：学分范围、任课教师、只看有名额、当前学生的选课状态
+	filter := ops.CourseListFilter{
+		Sort: order,
+		Now:  time.Now(),
+	}
+	filter.TeacherID, _ = strconv.Atoi(query.Get("teacherId"))
+	for _, p := range []struct {
+		name string
+		dest **float64
+	}{{"creditMin", &filter.CreditMin}, {"creditMax", &filter.CreditMax}} {
+		if v := query.Get(p.name); v != "" {
+			credit, err := strconv.ParseFloat(v, 64)
+			if err != nil || credit < 0 {
+				sendCourseError(w, http.StatusBadRequest, 400, "学分范围无效")
+				return
+			}
+			*p.dest = &credit
+		}
+	}
+	if filter.CreditMin != nil && filter.CreditMax != nil && *filter.CreditMin > *filter.CreditMax {
+		sendCourseError(w, http.StatusBadRequest, 400, "学分范围无效")
+		return
+	}
+	filter.Available, _ = strconv.ParseBool(query.Get("available"))
+	if v := query.Get("enrolled"); v != "" {
+		enrolled, err := strconv.ParseBool(v)
+		if err != nil {
+			sendCourseError(w, http.StatusBadRequest, 400, "enrolled 只能是 1 或 0")
+			return
+		}
+		filter.Enrolled = &enrolled
+	}
 	
 	// 计算偏移量
 	offset := (page - 1) * pageSize
@@ -118,13 +186,20 @@ func GetCourseList(w http.ResponseWriter, r *http.Request) {
 		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
 		return
 	}
+	// 选课状态和搜索记录按登录会话中的学生，未登录时为空
+	filter.StuID, err = sessionStudent(db, r)
+	if err != nil {
+		log.Printf("校验登录会话失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
 	
 	// 构建查询
 	var courses []Course
 	var total int
 	
 	// 基础查询
-	baseQuery := `
+	selectSQL := `
 		SELECT 
 			c.id,
 			c.title,
@@ -132,39 +207,70 @@ func GetCourseList(w http.ResponseWriter, r *http.Request) {
 			c.cover,
 			c.lesson_num,
 			c.credit,
-			c.limit_count
-		FROM courses c
-	`
+			c.limit_count`
+	fromSQL := " FROM courses c"
+	var selectParams []interface{}
 	
 	// 添加搜索条件
-	var whereClauses []string
+	// 学生只能看到已发布的课程
+	whereClauses := []string{"c.status = 'published'"}
 	var params []interface{}
 	
-	if title != "" {
+	// 搜索词：全文检索课程标题、简介、教师姓名和章节课时标题，按相关度排序；检索词过短时按标题模糊匹配
+	terms := search.Terms(title)
+	fulltext := search.BooleanQuery(terms)
+	if fulltext != "" {
+		selectSQL += `,
+			cs.teachers,
+			cs.outline,
+			MATCH(cs.title) AGAINST(? IN BOOLEAN MODE) * 4 +
+				MATCH(cs.teachers) AGAINST(? IN BOOLEAN MODE) * 2 +
+				MATCH(cs.outline) AGAINST(? IN BOOLEAN MODE) * 1.5 +
+				MATCH(cs.description) AGAINST(? IN BOOLEAN MODE) AS score`
+		selectParams = append(selectParams, fulltext, fulltext, fulltext, fulltext)
+		fromSQL += " JOIN course_search cs ON cs.course_id = c.id"
+		whereClauses = append(whereClauses, "MATCH(cs.title, cs.teachers, cs.outline, cs.description) AGAINST(? IN BOOLEAN MODE)")
+		params = append(params, fulltext)
+	} else if title != "" {
 		whereClauses = append(whereClauses, "c.title LIKE ?")
 		params = append(params, "%"+title+"%")
 	}
 	
-	// 构建WHERE子句
-	whereSQL := ""
-	if len(whereClauses) > 0 {
-		whereSQL = " WHERE " + strings.Join(whereClauses, " AND ")
+	// 按分类筛选，包含子分类下的课程
+	if subjectID > 0 {
+		subjectIDs, err := ops.SubjectSubtree(db, subjectID)
+		if err == ops.ErrSubjectNotFound {
+			subjectIDs = []int{subjectID}
+		} else if err != nil {
+			log.Printf("查询课程分类失败: %v", err)
+			sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+			return
+		}
+		filter.SubjectIDs = subjectIDs
 	}
 	
-	// 构建ORDER BY子句
-	orderSQL := " ORDER BY c.id DESC" // 默认排序
-	switch order {
-	case 1:
-		orderSQL = " ORDER BY c.id DESC" // 最新
-	case 2:
-		orderSQL = " ORDER BY c.lesson_num DESC" // 最热
+	// 选课人数、评分等列，以及其余筛选条件和排序
+	listSQL := filter.SQL()
+	selectSQL += listSQL.Columns
+	selectParams = append(selectParams, listSQL.ColumnArgs...)
+	fromSQL += listSQL.Joins
+	whereClauses = append(whereClauses, listSQL.Where...)
+	params = append(params, listSQL.WhereArgs...)
+	
+	// 构建WHERE子句
+	whereSQL := " WHERE " + strings.Join(whereClauses, " AND ")
+	
+	// 构建ORDER BY子句，搜索时默认按相关度排序
+	orderSQL := " ORDER BY " + listSQL.OrderBy
+	if order == ops.SortDefault && fulltext != "" {
+		orderSQL = " ORDER BY score DESC, c.id DESC"
 	}
 	
 	// 查询课程列表
-	querySQL := baseQuery + whereSQL + " GROUP BY c.id" + orderSQL + " LIMIT ? OFFSET ?"
-	params = append(params, pageSize, offset)
+	querySQL := selectSQL + fromSQL + whereSQL + orderSQL + " LIMIT ? OFFSET ?"
+	listParams := append(append(append([]interface{}{}, selectParams...), params...), pageSize, offset)
 	
-	rows, err := db.Query(querySQL, params...)
+	rows, err := db.Query(querySQL, listParams...)
 	if err != nil {
 		log.Printf("查询课程列表失败: %v", err)
 		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
@@ -175,37 +281,70 @@ func GetCourseList(w http.ResponseWriter, r *http.Request) {
 	// 解析结果
 	for rows.Next() {
 		var course Course
-		err := rows.Scan(
+		var description sql.NullString
+		dest := []interface{}{
 			&course.ID,
 			&course.Title,
-			&course.Description,
+			&description,
 			&course.Cover,
 			&course.LessonNum,
 			&course.Credit,
 			&course.LimitCount,
-		)
-		if err != nil {
+		}
+		var teachers, outline string
+		var score float64
+		var taken int
+		if fulltext != "" {
+			dest = append(dest, &teachers, &outline, &score)
+		}
+		dest = append(dest, &course.EnrolledCount, &taken, &course.Rating, &course.RatingCount)
+		for i := range course.RatingDistribution {
+			dest = append(dest, &course.RatingDistribution[i])
+		}
+		if filter.StuID != "" {
+			dest = append(dest, &course.Joined)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			log.Printf("解析课程数据失败: %v", err)
 			continue
 		}
+		course.Description = description.String
+		// 尚未过期的候补名额已经分配出去，不计入剩余名额
+		course.RemainingSeats = ops.RemainingSeats(course.LimitCount, taken)
+		if fulltext != "" {
+			highlightCourse(&course, terms, teachers, outline)
+		}
 		courses = append(courses, course)
 	}
 	
 	// 查询总数
-	countSQL := "SELECT COUNT(DISTINCT c.id) FROM courses c" + whereSQL
-	var countParams []interface{}
-	if len(params) > 2 { // 移除LIMIT和OFFSET参数
-		countParams = params[:len(params)-2]
-	}
-	
-	err = db.QueryRow(countSQL, countParams...).Scan(&total)
+	countSQL := "SELECT COUNT(*)" + fromSQL + whereSQL
+	err = db.QueryRow(countSQL, params...).Scan(&total)
 	if err != nil {
 		log.Printf("查询课程总数失败: %v", err)
 		total = len(courses) // 如果查询失败，使用当前页的数量
 	}
 	
-	// 热门搜索列表
-	hotList := []string{"网络安全", "渗透测试", "数据加密"}
+	// 记录搜索词（只记录第一页，翻页不重复计数），用于计算热门搜索
+	if title != "" && page == 1 {
+		go func(searcher string, results int) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := ops.LogSearch(ctx, db, title, searcher, results); err != nil {
+				log.Printf("记录搜索日志失败: %v", err)
+			}
+		}(searcherID(r, filter.StuID), total)
+	}
+	
+	// 热门搜索列表（后台定期计算）
+	hotList := hotsearch.Default().List()
+	
+	// 分类树及各分类下的课程数，用于分类导航
+	subjects, err := ops.SubjectTree(db, true)
+	if err != nil {
+		log.Printf("查询课程分类失败: %v", err)
+		subjects = []*ops.Subject{}
+	}
 	
 	// 构建响应
 	response := CourseListResponse{
@@ -214,7 +353,7 @@ func GetCourseList(w http.ResponseWriter, r *http.Request) {
 			CourseList:  courses,
 			Total:       total,
 			HotList:     hotList,
-			SubjectList: []interface{}{}, // 空数组，与原API保持一致
+			SubjectList: subjects,
 		},
 	}
 	
@@ -271,16 +410,16 @@ func GetCourseDetail(w http.ResponseWriter, r *http.Request) {
 			c.lesson_num,
 			c.credit,
 			c.limit_count,
-			t.id,
-			t.name,
-			t.career,
-			t.intro
+			c.enroll_mode,
+			c.rating_avg,
+			c.rating_count,
+			c.rating_1, c.rating_2, c.rating_3, c.rating_4, c.rating_5,
+			c.status
 		FROM courses c
-		LEFT JOIN teacher_courses tc ON c.id = tc.course_id
-		LEFT JOIN teachers t ON tc.teacher_id = t.id
 		WHERE c.id = ?
 	`
 	
+	var status string
 	err = db.QueryRow(query, courseID).Scan(
 		&courseDetail.ID,
 		&courseDetail.Title,
@@ -289,10 +428,15 @@ func GetCourseDetail(w http.ResponseWriter, r *http.Request) {
 		&courseDetail.LessonNum,
 		&courseDetail.Credit,
 		&courseDetail.LimitCount,
-		&courseDetail.Teacher.TeacherID,
-		&courseDetail.Teacher.TeacherName,
-		&courseDetail.Teacher.Career,
-		&courseDetail.Teacher.Intro,
+		&courseDetail.EnrollMode,
+		&courseDetail.Rating.Average,
+		&courseDetail.Rating.Count,
+		&courseDetail.Rating.Distribution[0],
+		&courseDetail.Rating.Distribution[1],
+		&courseDetail.Rating.Distribution[2],
+		&courseDetail.Rating.Distribution[3],
+		&courseDetail.Rating.Distribution[4],
+		&status,
 	)
 	
 	if err != nil {
@@ -305,13 +449,85 @@ func GetCourseDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// 已选课的学生才会拿到签名后的视频地址，学生由登录会话确定
+	stuID, err := sessionStudent(db, r)
+	if err != nil {
+		log.Printf("校验登录会话失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	enrolled, err := isEnrolled(db, stuID, courseID)
+	if err != nil {
+		log.Printf("检查选课状态失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	// 未发布的课程对学生不可见，已归档的课程只有已选修的学生可以查看
+	if status != "published" && !enrolled {
+		sendCourseError(w, http.StatusNotFound, 404, "课程不存在")
+		return
+	}
+	// 登录学生（未选课也算），用于检查先修课程；stuID 只保留已选课的学生，用于签名媒体地址
+	viewerID := stuID
+	if !enrolled {
+		stuID = ""
+	}
+
+	// 课程可以没有教师，也可以有多名教师
+	teachers, err := ops.CourseTeachers(db, courseID)
+	if err != nil {
+		log.Printf("查询课程教师失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	courseDetail.Teachers = teachers
+	if len(teachers) > 0 {
+		courseDetail.Teacher = TeacherInfo{
+			TeacherID:   teachers[0].TeacherID,
+			TeacherName: teachers[0].Name,
+			Career:      teachers[0].Career,
+			Intro:       teachers[0].Intro,
+		}
+	}
+
 	// 查询章节数据
-	chapters, err := getCourseChapters(db, courseID)
+	chapters, err := getCourseChapters(db, courseID, stuID)
 	if err != nil {
 		log.Printf("查询章节数据失败: %v", err)
 		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
 		return
 	}
+
+	// 已选课学生按学习进度标记已学完的章节
+	var cp *CourseProgress
+	if stuID != "" {
+		courses, err := courseProgress(r.Context(), db, stuID, []int{courseID})
+		if err != nil {
+			log.Printf("查询学习进度失败: %v", err)
+		} else if cp = courses[courseID]; cp != nil {
+			markFinishedChapters(chapters, cp)
+		}
+	}
+
+	// 未学完先修章节的章节锁定
+	rules, err := ops.ChapterPrerequisites(db, courseID)
+	if err != nil {
+		log.Printf("查询先修章节失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	lockChaptersByPrerequisites(chapters, rules, cp)
+
+	// 先修课程，登录学生同时返回是否满足
+	prereq, err := ops.CheckCoursePrerequisites(db, viewerID, courseID)
+	if err != nil {
+		log.Printf("查询先修课程失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	if len(prereq.Groups) > 0 {
+		courseDetail.Prerequisites = prereq
+	}
 	
 	courseDetail.Chapter = chapters
 	
@@ -330,20 +546,22 @@ func GetCourseDetail(w http.ResponseWriter, r *http.Request) {
 }
 
 // getCourseChapters 获取课程的章节数据
-func getCourseChapters(db *sql.DB, courseID int) ([]Chapter, error) {
+// stuID 为已选课学生的学号时，已开放章节中课时的视频地址签名后返回；为空时不返回视频地址
+func getCourseChapters(db *sql.DB, courseID int, stuID string) ([]Chapter, error) {
 	query := `
 		SELECT 
 			ch.id,
 			ch.title,
-			ch.state,
+			ch.release_at,
 			cc.id,
 			cc.title,
-			v.url
+			v.url,
+			cc.pdf_url
 		FROM chapters ch
 		LEFT JOIN chapter_children cc ON ch.id = cc.chapter_id
 		LEFT JOIN videos v ON cc.video_id = v.id
 		WHERE ch.course_id = ?
-		ORDER BY ch.id, cc.id
+		ORDER BY ch.sort_order, ch.id, cc.sort_order, cc.id
 	`
 	
 	rows, err := db.Query(query, courseID)
@@ -356,19 +574,22 @@ func getCourseChapters(db *sql.DB, courseID int) ([]Chapter, error) {
 	chaptersMap := make(map[int]*Chapter)
 	var chapters []Chapter
 	
+	now := time.Now()
 	for rows.Next() {
-		var chapterID, chapterState int
+		var chapterID int
 		var chapterTitle string
+		var releaseAt sql.NullTime
 		var childID sql.NullInt64
-		var childTitle, videoURL sql.NullString
+		var childTitle, videoURL, pdfURL sql.NullString
 		
 		err := rows.Scan(
 			&chapterID,
 			&chapterTitle,
-			&chapterState,
+			&releaseAt,
 			&childID,
 			&childTitle,
 			&videoURL,
+			&pdfURL,
 		)
 		if err != nil {
 			return nil, err
@@ -380,9 +601,16 @@ func getCourseChapters(db *sql.DB, courseID int) ([]Chapter, error) {
 			chapter = &Chapter{
 				ID:       chapterID,
 				Title:    chapterTitle,
-				State:    chapterState,
+				State:    ChapterOpen,
 				Children: []Lesson{},
 			}
+			if releaseAt.Valid {
+				chapter.ReleaseAt = &releaseAt.Time
+				if releaseAt.Time.After(now) {
+					chapter.State = ChapterLocked
+					chapter.Locked = true
+				}
+			}
 			chaptersMap[chapterID] = chapter
 			chapters = append(chapters, *chapter)
 		}
@@ -392,7 +620,10 @@ func getCourseChapters(db *sql.DB, courseID int) ([]Chapter, error) {
 			lesson := Lesson{
 				ID:            int(childID.Int64),
 				Title:         childTitle.String,
-				VideoSourceID: videoURL.String,
+			}
+			if stuID != "" && !chapter.Locked {
+				lesson.VideoSourceID = signMediaURL(videoURL.String, stuID, courseID)
+				lesson.PdfURL = signMediaURL(pdfURL.String, stuID, courseID)
 			}
 			
 			// 找到对应的章节并添加课时
@@ -408,6 +639,96 @@ func getCourseChapters(db *sql.DB, courseID int) ([]Chapter, error) {
 	return chapters, nil
 }
 
+// markFinishedChapters 将视频课时全部学完的已开放章节标记为已学完
+func markFinishedChapters(chapters []Chapter, cp *CourseProgress) {
+	finished := make(map[int]bool)
+	for _, ch := range cp.Chapters {
+		if ch.Total > 0 && ch.Completed == ch.Total {
+			finished[ch.ChapterID] = true
+		}
+	}
+	for i := range chapters {
+		if chapters[i].State == ChapterOpen && finished[chapters[i].ID] {
+			chapters[i].State = ChapterFinished
+		}
+	}
+}
+
+// chapterPrerequisiteChecks 按学习进度检查各章节的先修章节，返回有先修规则的章节的检查结果
+// 先修章节的视频课时全部学完才算学完，没有视频课时的章节视为已学完；cp 为 nil（未选课）时都未学完
+func chapterPrerequisiteChecks(rules map[int][]ops.PrerequisiteGroup, cp *CourseProgress) map[int]*ops.PrerequisiteCheck {
+	checks := make(map[int]*ops.PrerequisiteCheck, len(rules))
+	if len(rules) == 0 {
+		return checks
+	}
+	unfinished := make(map[int]bool)
+	if cp != nil {
+		for _, ch := range cp.Chapters {
+			if ch.Completed < ch.Total {
+				unfinished[ch.ChapterID] = true
+			}
+		}
+	}
+	finished := make(map[int]bool)
+	for _, groups := range rules {
+		for _, g := range groups {
+			for _, item := range g.Items {
+				finished[item.ID] = cp != nil && !unfinished[item.ID]
+			}
+		}
+	}
+	for chapterID, groups := range rules {
+		checks[chapterID] = ops.CheckChapterPrerequisites(groups, finished)
+	}
+	return checks
+}
+
+// lockChaptersByPrerequisites 先修章节未学完时锁定章节并去掉媒体地址（见 chapterPrerequisiteChecks）
+func lockChaptersByPrerequisites(chapters []Chapter, rules map[int][]ops.PrerequisiteGroup, cp *CourseProgress) {
+	checks := chapterPrerequisiteChecks(rules, cp)
+	for i := range chapters {
+		check, ok := checks[chapters[i].ID]
+		if !ok {
+			continue
+		}
+		chapters[i].Prerequisites = check.Groups
+		if check.Satisfied {
+			continue
+		}
+		chapters[i].State = ChapterLocked
+		chapters[i].Locked = true
+		for j := range chapters[i].Children {
+			chapters[i].Children[j].VideoSourceID = ""
+			chapters[i].Children[j].PdfURL = ""
+		}
+	}
+}
+
+// highlightCourse 标出课程标题中的检索词，并从简介、章节课时标题或教师姓名中截取命中的片段
+func highlightCourse(course *Course, terms []string, teachers, outline string) {
+	if h, ok := search.Highlight(course.Title, terms, 0); ok {
+		course.TitleHighlight = h
+	}
+	for _, text := range []string{course.Description, outline, teachers} {
+		if h, ok := search.Highlight(text, terms, 80); ok {
+			course.Snippet = h
+			return
+		}
+	}
+}
+
+// searcherID 搜索者标识：登录学生（stuID 取自会话）使用学号，否则使用客户端IP
+func searcherID(r *http.Request, stuID string) string {
+	if stuID != "" {
+		return "stu:" + stuID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
 // sendCourseError 发送课程相关错误响应
 func sendCourseError(w http.ResponseWriter, httpStatus, code int, message string) {
 	w.WriteHeader(httpStatus)
@@ -434,6 +755,12 @@ func RegisterCourseRoutes() *http.ServeMux {
 		}
 	})
 	
+	// 课程PDF下载地址（仅限已选课学生）
+	mux.HandleFunc("GET /api/courses/{id}/pdf", coursePdfURLHandler)
+	
+	// 课程公开的评价
+	mux.HandleFunc("GET /api/courses/{id}/reviews", courseReviewsHandler)
+	
 	// 课程详情
 	mux.HandleFunc("/api/courses/", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
@@ -0,0 +1,245 @@
+// internal/handlers/enrollment.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+	"cybersecurity-platform-go/internal/sheet"
+)
+
+// 名单文件最大大小
+const maxRosterFileSize = 10 << 20
+
+// dropCourseRequest 学生退课请求
+type dropCourseRequest struct {
+	CourseID int    `json:"courseId"`
+	Reason   string `json:"reason"`
+}
+
+// rosterStatusRequest 教师或管理员修改学生的选课状态
+type rosterStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// dropCourseHandler 学生退课
+func dropCourseHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var req dropCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数解析失败")
+		return
+	}
+	if req.CourseID <= 0 {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数不完整")
+		return
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+
+	var input ops.InputError
+	err = ops.DropCourse(db, stuID, req.CourseID, req.Reason)
+	switch {
+	case err == nil:
+	case err == ops.ErrCourseNotFound:
+		sendStudentError(w, http.StatusNotFound, 40400, "课程不存在")
+		return
+	case err == ops.ErrNotEnrolled:
+		sendStudentError(w, http.StatusOK, 40005, err.Error())
+		return
+	case err == ops.ErrDropDeadlinePassed:
+		sendStudentError(w, http.StatusOK, 40006, err.Error())
+		return
+	case errors.As(err, &input):
+		sendStudentError(w, http.StatusBadRequest, 40000, err.Error())
+		return
+	default:
+		log.Printf("退课失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(StudentJoinResponse{Code: 20000, Message: "退课成功"})
+}
+
+// enrollmentHistoryHandler 学生的选课记录变更历史
+func enrollmentHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+	list, err := ops.EnrollmentHistory(db, stuID)
+	if err != nil {
+		log.Printf("查询选课记录失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": 20000, "data": list})
+}
+
+func teacherRosterHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseRosterHandler(teacherID, w, r)
+}
+
+// courseRosterHandler 课程的学生名单，可按 status 筛选，teacherID 为0表示管理员
+func courseRosterHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", ops.EnrollActive, ops.EnrollDropped, ops.EnrollCompleted, ops.EnrollFailed:
+	default:
+		sendAdminError(w, http.StatusBadRequest, 40000, "无效的选课状态")
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	list, err := ops.CourseRoster(db, teacherID, courseID, status)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, list)
+}
+
+func teacherSetEnrollmentHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	setEnrollmentHandler(teacherID, w, r)
+}
+
+// setEnrollmentHandler 加入、移出学生或记录结课结果，teacherID 为0表示管理员
+func setEnrollmentHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	stuID := r.PathValue("stuId")
+	var req rosterStatusRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.SetEnrollment(db, teacherID, courseID, stuID, req.Status, req.Reason); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func teacherImportRosterHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	importRosterHandler(teacherID, w, r)
+}
+
+// importRosterHandler 导入学生名单，请求体为 CSV 或 XLSX 文件，teacherID 为0表示管理员
+// 查询参数 dryRun=1 只校验不写入，createAccounts=1 为不存在的学号创建账号
+func importRosterHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var opts ops.RosterImportOptions
+	opts.DryRun, _ = strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	opts.CreateAccounts, _ = strconv.ParseBool(r.URL.Query().Get("createAccounts"))
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRosterFileSize))
+	if err != nil {
+		sendAdminError(w, http.StatusRequestEntityTooLarge, 41300, "名单文件过大")
+		return
+	}
+	table, err := sheet.Read(data)
+	if err != nil {
+		sendAdminError(w, http.StatusUnsupportedMediaType, 41500, err.Error())
+		return
+	}
+	rows, err := ops.ParseRoster(table)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	result, err := ops.ImportRoster(db, teacherID, courseID, rows, opts)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, result)
+}
+
+func teacherExportRosterHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	exportRosterHandler(teacherID, w, r)
+}
+
+// exportRosterHandler 导出课程名单（学习进度和成绩），format 为 csv（默认）或 xlsx，teacherID 为0表示管理员
+func exportRosterHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = sheet.FormatCSV
+	case sheet.FormatCSV, sheet.FormatXLSX:
+	default:
+		sendAdminError(w, http.StatusBadRequest, 40000, "format 只能是 csv 或 xlsx")
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	report, err := ops.CourseRosterReport(db, teacherID, courseID)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+
+	name := fmt.Sprintf("course-%d-roster-%s.%s", courseID, time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Type", sheet.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	if err := sheet.Write(w, format, ops.RosterTable(report)); err != nil {
+		log.Printf("导出名单失败: %v", err)
+	}
+}
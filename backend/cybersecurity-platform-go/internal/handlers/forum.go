@@ -19,6 +19,23 @@ import (
 // 创建内存缓存，过期时间5分钟，清理间隔10分钟
 var forumCache = cache.New(5*time.Minute, 10*time.Minute)
 
+// PingForumCache 检查论坛内存缓存是否可正常读写
+func PingForumCache() error {
+	const probeKey = "__health_probe__"
+	forumCache.Set(probeKey, true, time.Minute)
+	defer forumCache.Delete(probeKey)
+
+	if _, found := forumCache.Get(probeKey); !found {
+		return fmt.Errorf("缓存写入后无法读取")
+	}
+	return nil
+}
+
+// FlushCaches 清空论坛内存缓存
+func FlushCaches() {
+	forumCache.Flush()
+}
+
 // ForumCategory 论坛分类
 type ForumCategory struct {
 	ID   int    `json:"id"`
@@ -3,18 +3,34 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"sync"
 )
 
-// InitGraphHandler 初始化图处理器
+// 全局图处理器实例（由 InitGraphHandler 设置）
+var (
+	graphHandler   *GraphHandler
+	graphHandlerMu sync.RWMutex
+)
+
+// InitGraphHandler 初始化图处理器，并保存为全局实例
 func InitGraphHandler(uri, username, password string) (*GraphHandler, error) {
-	return NewGraphHandler(uri, username, password)
+	gh, err := NewGraphHandler(uri, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	graphHandlerMu.Lock()
+	graphHandler = gh
+	graphHandlerMu.Unlock()
+
+	return gh, nil
 }
 
-// GetGraphHandler 获取图处理器实例
+// GetGraphHandler 获取图处理器实例，未初始化时返回nil
 func GetGraphHandler() *GraphHandler {
-	// 这里需要你实现单例模式或从某个地方获取实例
-	// 暂时返回nil，你需要根据实际情况修改
-	return nil
+	graphHandlerMu.RLock()
+	defer graphHandlerMu.RUnlock()
+	return graphHandler
 }
 
 // RegisterGraphRoutes 注册图数据库路由
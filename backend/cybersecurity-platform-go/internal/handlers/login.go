@@ -6,8 +6,10 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -22,6 +24,7 @@ type LoginResponse struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	User    *UserInfo   `json:"user,omitempty"`
+	Token   string      `json:"token,omitempty"` // 登录会话令牌，获取课程媒体地址时通过 X-Token 请求头传入
 }
 
 // UserInfo 用户信息结构体
@@ -79,7 +82,8 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 			a.email,
 			b.userName,
 			b.userHead,
-			b.nickName
+			b.nickName,
+			a.disabled
 		FROM students a 
 		JOIN userdetail b ON a.stuId = b.stuId 
 		WHERE a.stuId = ?
@@ -87,6 +91,7 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	
 	var user UserInfo
 	var hashedPassword string
+	var disabled bool
 	
 	err = db.QueryRow(query, req.StuID).Scan(
 		&user.ID,
@@ -96,6 +101,7 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		&user.UserName,
 		&user.UserHead,
 		&user.NickName,
+		&disabled,
 	)
 	
 	if err != nil {
@@ -116,11 +122,24 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// 登录成功
+	// 已禁用的账号不允许登录
+	if disabled {
+		sendLoginError(w, http.StatusForbidden, 40003, "账号已被禁用")
+		return
+	}
+	
+	// 登录成功，签发会话令牌
+	token, err := ops.IssueStudentSession(db, user.StuID, time.Now())
+	if err != nil {
+		log.Printf("创建登录会话失败: %v", err)
+		sendLoginError(w, http.StatusInternalServerError, 50000, "登录失败，请重试")
+		return
+	}
 	response := LoginResponse{
 		Code:    20000,
 		Message: "登录成功",
 		User:    &user,
+		Token:   token,
 	}
 	
 	w.WriteHeader(http.StatusOK)
@@ -148,6 +167,7 @@ func RegisterLoginRoutes() *http.ServeMux {
 	
 	// 登录路由
 	mux.HandleFunc("/api/login", LoginHandler)
+	mux.HandleFunc("/api/logout", LogoutHandler)
 	
 	return mux
 }
\ No newline at end of file
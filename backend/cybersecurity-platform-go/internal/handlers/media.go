@@ -0,0 +1,302 @@
+// internal/handlers/media.go
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/media"
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// 需要签名才能访问的媒体路径前缀
+var protectedMediaPrefixes = []string{"/api/videoing/", "/api/pdfs/"}
+
+// 学生端查询课程内容时共用的条件：课程已发布或已归档（已选修的学生仍可学习），章节已到开放时间
+// chapterReleasedSQL 需要传入当前时间作为参数
+const (
+	courseVisibleSQL   = "c.status IN ('published', 'archived')"
+	chapterReleasedSQL = "(ch.release_at IS NULL OR ch.release_at <= ?)"
+)
+
+// MediaAccess 检查学生是否可以访问课程媒体：学生必须存在，课程ID不为0时还必须已选修该课程
+func MediaAccess(ctx context.Context, stuID string, courseID int) (bool, error) {
+	db, err := database.GetDB()
+	if err != nil {
+		return false, err
+	}
+
+	var ok bool
+	if courseID == 0 {
+		err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)", stuID).Scan(&ok)
+	} else {
+		err = db.QueryRowContext(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM student_courses sc JOIN courses c ON sc.course_id = c.id
+				WHERE sc.stuId = ? AND sc.course_id = ? AND sc.status <> 'dropped' AND `+courseVisibleSQL+`
+			)`, stuID, courseID,
+		).Scan(&ok)
+	}
+	return ok, err
+}
+
+// isEnrolled 检查学生是否已选修课程
+func isEnrolled(db *sql.DB, stuID string, courseID int) (bool, error) {
+	if stuID == "" {
+		return false, nil
+	}
+	var enrolled bool
+	err := db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM student_courses sc JOIN courses c ON sc.course_id = c.id
+			WHERE sc.stuId = ? AND sc.course_id = ? AND sc.status <> 'dropped' AND `+courseVisibleSQL+`
+		)`, stuID, courseID,
+	).Scan(&enrolled)
+	return enrolled, err
+}
+
+// lockedChapters 课程中因先修章节未学完而对学生锁定的章节
+// 课程详情只是隐藏锁定章节的地址，视频详情、讲义地址和字幕检索都要用它检查，
+// 否则已选课的学生可以绕过课程详情直接获取锁定章节的签名地址
+func lockedChapters(ctx context.Context, db *sql.DB, stuID string, courseID int) (map[int]bool, error) {
+	rules, err := ops.ChapterPrerequisites(db, courseID)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	courses, err := courseProgress(ctx, db, stuID, []int{courseID})
+	if err != nil {
+		return nil, err
+	}
+	locked := make(map[int]bool)
+	for chapterID, check := range chapterPrerequisiteChecks(rules, courses[courseID]) {
+		if !check.Satisfied {
+			locked[chapterID] = true
+		}
+	}
+	return locked, nil
+}
+
+// chapterLockCache 按课程缓存 lockedChapters 的结果，一次请求涉及多门课程的多个章节时使用
+type chapterLockCache struct {
+	db     *sql.DB
+	stuID  string
+	locked map[int]map[int]bool
+}
+
+func newChapterLockCache(db *sql.DB, stuID string) *chapterLockCache {
+	return &chapterLockCache{db: db, stuID: stuID, locked: make(map[int]map[int]bool)}
+}
+
+// Locked 章节是否对学生锁定
+func (c *chapterLockCache) Locked(ctx context.Context, courseID, chapterID int) (bool, error) {
+	locked, ok := c.locked[courseID]
+	if !ok {
+		var err error
+		if locked, err = lockedChapters(ctx, c.db, c.stuID, courseID); err != nil {
+			return false, err
+		}
+		c.locked[courseID] = locked
+	}
+	return locked[chapterID], nil
+}
+
+// videoCourseForStudent 查找学生可以通过哪门课程访问视频
+// 视频属于学生已选修课程中已开放、且先修章节已学完的章节时返回该课程ID；
+// 不属于任何课程时返回0（学生存在即可访问）
+func videoCourseForStudent(ctx context.Context, db *sql.DB, videoID int, stuID string) (int, bool, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT ch.course_id, ch.id
+		FROM chapter_children cc
+		JOIN chapters ch ON cc.chapter_id = ch.id
+		JOIN courses c ON ch.course_id = c.id
+		JOIN student_courses sc ON sc.course_id = ch.course_id AND sc.stuId = ? AND sc.status <> 'dropped'
+		WHERE cc.video_id = ? AND `+courseVisibleSQL+` AND `+chapterReleasedSQL+`
+		ORDER BY ch.course_id, ch.id
+	`, stuID, videoID, time.Now())
+	if err != nil {
+		return 0, false, err
+	}
+	type placement struct{ courseID, chapterID int }
+	var found []placement
+	for rows.Next() {
+		var p placement
+		if err := rows.Scan(&p.courseID, &p.chapterID); err != nil {
+			rows.Close()
+			return 0, false, err
+		}
+		found = append(found, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, false, err
+	}
+
+	locks := newChapterLockCache(db, stuID)
+	for _, p := range found {
+		locked, err := locks.Locked(ctx, p.courseID, p.chapterID)
+		if err != nil {
+			return 0, false, err
+		}
+		if !locked {
+			return p.courseID, true, nil
+		}
+	}
+	if len(found) > 0 {
+		return 0, false, nil
+	}
+
+	var inCourse bool
+	if err := db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM chapter_children WHERE video_id = ?)", videoID,
+	).Scan(&inCourse); err != nil {
+		return 0, false, err
+	}
+	if inCourse {
+		return 0, false, nil
+	}
+
+	ok, err := MediaAccess(ctx, stuID, 0)
+	return 0, ok, err
+}
+
+// errPdfNotInCourse 课程的章节中没有这份讲义
+var errPdfNotInCourse = errors.New("讲义不属于该课程")
+
+// pdfUnlocked 讲义挂在课程的哪些章节下，至少有一个章节已开放且对学生未锁定才能下载；
+// 课程的章节中没有这份讲义时返回 errPdfNotInCourse，不能借选修的课程为其他课程的讲义签名
+func pdfUnlocked(ctx context.Context, db *sql.DB, stuID string, courseID int, file string) (bool, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT DISTINCT ch.id, `+chapterReleasedSQL+`
+		FROM chapter_children cc
+		JOIN chapters ch ON cc.chapter_id = ch.id
+		WHERE ch.course_id = ? AND cc.pdf_url IN (?, ?)
+	`, time.Now(), courseID, "/api/pdfs/"+url.PathEscape(file), "/api/pdfs/"+file)
+	if err != nil {
+		return false, err
+	}
+	released := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		var ok bool
+		if err := rows.Scan(&id, &ok); err != nil {
+			rows.Close()
+			return false, err
+		}
+		released[id] = ok
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	if len(released) == 0 {
+		return false, errPdfNotInCourse
+	}
+
+	locked, err := lockedChapters(ctx, db, stuID, courseID)
+	if err != nil {
+		return false, err
+	}
+	for id, ok := range released {
+		if ok && !locked[id] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// signMediaURL 为本服务提供的媒体地址签名，外部地址（CDN等）原样返回
+func signMediaURL(raw, stuID string, courseID int) string {
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return raw
+	}
+	for _, prefix := range protectedMediaPrefixes {
+		if strings.HasPrefix(u.Path, prefix) {
+			signed, err := media.Default().Sign(raw, media.Grant{StuID: stuID, CourseID: courseID})
+			if err != nil {
+				log.Printf("媒体地址签名失败: %v", err)
+				return ""
+			}
+			return signed
+		}
+	}
+	return raw
+}
+
+// coursePdfURLHandler 为已选课学生签发课程PDF的下载地址
+// GET /api/courses/{id}/pdf?file=xxx.pdf（需要登录会话令牌）
+func coursePdfURLHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	courseID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courseID <= 0 {
+		sendCourseError(w, http.StatusBadRequest, 400, "无效的课程ID")
+		return
+	}
+	file := r.URL.Query().Get("file")
+	if file == "" || path.Ext(file) != ".pdf" || strings.Contains(file, "/") || strings.Contains(file, "\\") {
+		sendCourseError(w, http.StatusBadRequest, 400, "无效的文件名")
+		return
+	}
+
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	// 下载地址绑定到当前登录的学生
+	stuID, err := sessionStudent(db, r)
+	if err != nil {
+		log.Printf("校验登录会话失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	if stuID == "" {
+		sendCourseError(w, http.StatusUnauthorized, 401, "请先登录")
+		return
+	}
+	enrolled, err := isEnrolled(db, stuID, courseID)
+	if err != nil {
+		log.Printf("检查选课状态失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	if !enrolled {
+		sendCourseError(w, http.StatusForbidden, 403, "请先选修该课程")
+		return
+	}
+	unlocked, err := pdfUnlocked(r.Context(), db, stuID, courseID, file)
+	if errors.Is(err, errPdfNotInCourse) {
+		sendCourseError(w, http.StatusNotFound, 404, "讲义不存在")
+		return
+	}
+	if err != nil {
+		log.Printf("检查章节先修要求失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	if !unlocked {
+		sendCourseError(w, http.StatusForbidden, 403, "讲义所在章节尚未开放")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": map[string]string{
+			"url": signMediaURL("/api/pdfs/"+url.PathEscape(file), stuID, courseID),
+		},
+	})
+}
@@ -0,0 +1,133 @@
+// internal/handlers/prerequisite.go
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// prerequisitesRequest 先修规则：groups 之间为“且”，同一组内为“或”
+type prerequisitesRequest struct {
+	Groups [][]int `json:"groups"`
+}
+
+// prerequisiteCheckHandler 学生是否满足课程的先修规则，以及还缺哪些先修课程
+// GET /api/student/prerequisites?courseId=3（学生取自登录会话）
+func prerequisiteCheckHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	courseID, err := strconv.Atoi(r.URL.Query().Get("courseId"))
+	if err != nil || courseID <= 0 {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数不完整")
+		return
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+	check, err := ops.CheckCoursePrerequisites(db, stuID, courseID)
+	if err != nil {
+		log.Printf("检查先修课程失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": 20000, "data": check})
+}
+
+// sendPrerequisiteError 选课时未满足先修课程，data 为未满足的条件组
+func sendPrerequisiteError(w http.ResponseWriter, err *ops.PrerequisiteError) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    40011,
+		"message": err.Error(),
+		"data":    err.Missing,
+	})
+}
+
+func teacherCoursePrerequisitesHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	coursePrerequisitesHandler(teacherID, w, r)
+}
+
+// coursePrerequisitesHandler 查询课程的先修规则，teacherID 为0表示管理员
+func coursePrerequisitesHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.CheckCourseAccess(db, teacherID, courseID, ""); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	groups, err := ops.CoursePrerequisites(db, courseID)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, groups)
+}
+
+func teacherSetCoursePrerequisitesHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	setCoursePrerequisitesHandler(teacherID, w, r)
+}
+
+// setCoursePrerequisitesHandler 设置课程的先修规则（整体替换），teacherID 为0表示管理员
+func setCoursePrerequisitesHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req prerequisitesRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.SetCoursePrerequisites(db, teacherID, courseID, req.Groups); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+// setChapterPrerequisitesHandler 设置章节的先修章节（整体替换）
+func setChapterPrerequisitesHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	chapterID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req prerequisitesRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.SetChapterPrerequisites(db, teacherID, chapterID, req.Groups); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
@@ -0,0 +1,485 @@
+// internal/handlers/progress.go
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/progress"
+)
+
+// 客户端上报的视频时长上限（视频记录没有时长时使用）
+const maxReportedDuration = 24 * time.Hour
+
+// errLessonNotFound 课时不存在或学生未选修该课程
+var errLessonNotFound = errors.New("课时不存在或未选修该课程")
+
+// LessonProgress 课时观看进度
+type LessonProgress struct {
+	LessonID       int                 `json:"lessonId"`
+	CourseID       int                 `json:"courseId"`
+	VideoID        int                 `json:"videoId"`
+	Position       float64             `json:"position"` // 最后播放位置（秒）
+	Resume         float64             `json:"resume"`   // 下次打开时的起始位置（秒）
+	Duration       float64             `json:"duration"`
+	Watched        []progress.Interval `json:"watched"`
+	WatchedSeconds float64             `json:"watchedSeconds"`
+	Percent        int                 `json:"percent"`
+	Completed      bool                `json:"completed"`
+}
+
+// LessonSummary 课程进度中的课时
+type LessonSummary struct {
+	LessonID  int     `json:"lessonId"`
+	Title     string  `json:"title"`
+	Percent   int     `json:"percent"`
+	Completed bool    `json:"completed"`
+	Resume    float64 `json:"resume"`
+}
+
+// ChapterProgress 章节进度
+type ChapterProgress struct {
+	ChapterID int    `json:"chapterId"`
+	Title     string `json:"title"`
+	progress.Rollup
+	Lessons []LessonSummary `json:"lessons"`
+}
+
+// CourseProgress 课程进度
+type CourseProgress struct {
+	CourseID int `json:"courseId"`
+	progress.Rollup
+	Chapters []ChapterProgress `json:"chapters"`
+	Last     *LessonSummary    `json:"last"` // 最近观看的课时，用于“继续学习”
+}
+
+// progressRequest 进度接口的参数，支持 JSON、表单和查询参数
+// 学生取自登录会话，不接受客户端传入的 stuId（进度决定章节解锁和能否评价）
+type progressRequest struct {
+	StuID    string  `json:"-"`
+	CourseID int     `json:"courseId"`
+	LessonID int     `json:"lessonId"`
+	VideoID  int     `json:"videoId"` // 未提供 lessonId 时按视频查找学生已选课程中的课时
+	Position float64 `json:"position"`
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Duration float64 `json:"duration"` // 播放器读取的视频时长，视频记录没有时长时使用
+}
+
+// RegisterProgressRoutes 注册学习进度路由（与前端 api/edu.js 的接口一致）
+//
+//	POST /api/app/edu/pub/progress/upProgress   上报观看区间和播放位置
+//	POST /api/app/edu/pub/progress/getProgress  查询课时进度和续播位置
+//	POST /api/app/edu/pub/progress              查询课程和各章节的完成度
+func RegisterProgressRoutes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/app/edu/pub/progress/upProgress", upProgressHandler)
+	mux.HandleFunc("POST /api/app/edu/pub/progress/getProgress", getProgressHandler)
+	mux.HandleFunc("POST /api/app/edu/pub/progress", courseProgressHandler)
+
+	return mux
+}
+
+// upProgressHandler 上报观看进度
+func upProgressHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	db, req, ok := parseProgressRequest(w, r)
+	if !ok {
+		return
+	}
+
+	lp, err := recordProgress(r.Context(), db, req, time.Now())
+	switch {
+	case errors.Is(err, errLessonNotFound):
+		sendStudentError(w, http.StatusNotFound, 40400, err.Error())
+		return
+	case errors.Is(err, progress.ErrInvalidInterval), errors.Is(err, progress.ErrImplausible):
+		sendStudentError(w, http.StatusBadRequest, 40000, err.Error())
+		return
+	case err != nil:
+		log.Printf("保存学习进度失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+
+	sendProgressData(w, lp)
+}
+
+// getProgressHandler 查询课时进度
+func getProgressHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	db, req, ok := parseProgressRequest(w, r)
+	if !ok {
+		return
+	}
+
+	lp, err := lessonProgress(r.Context(), db, req)
+	if errors.Is(err, errLessonNotFound) {
+		sendStudentError(w, http.StatusNotFound, 40400, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("查询学习进度失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+
+	sendProgressData(w, lp)
+}
+
+// courseProgressHandler 查询课程进度
+func courseProgressHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	db, req, ok := parseProgressRequest(w, r)
+	if !ok {
+		return
+	}
+	if req.CourseID <= 0 {
+		sendStudentError(w, http.StatusBadRequest, 40000, "缺少课程ID参数")
+		return
+	}
+
+	enrolled, err := isEnrolled(db, req.StuID, req.CourseID)
+	if err != nil {
+		log.Printf("检查选课状态失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	if !enrolled {
+		sendStudentError(w, http.StatusForbidden, 40300, "未选修该课程")
+		return
+	}
+
+	courses, err := courseProgress(r.Context(), db, req.StuID, []int{req.CourseID})
+	if err != nil {
+		log.Printf("查询课程进度失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+
+	cp := courses[req.CourseID]
+	if cp == nil {
+		cp = &CourseProgress{CourseID: req.CourseID, Chapters: []ChapterProgress{}}
+	}
+	sendProgressData(w, cp)
+}
+
+// parseProgressRequest 解析参数并从登录会话取得学生，失败时已写入错误响应
+func parseProgressRequest(w http.ResponseWriter, r *http.Request) (*sql.DB, progressRequest, bool) {
+	var req progressRequest
+
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct == "application/json" {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			sendStudentError(w, http.StatusBadRequest, 40000, "参数解析失败")
+			return nil, req, false
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		if err := r.ParseMultipartForm(1 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			sendStudentError(w, http.StatusBadRequest, 40000, "参数解析失败")
+			return nil, req, false
+		}
+		ints := map[string]*int{"courseId": &req.CourseID, "lessonId": &req.LessonID, "videoId": &req.VideoID}
+		for name, dst := range ints {
+			if v := r.FormValue(name); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					sendStudentError(w, http.StatusBadRequest, 40000, "无效的参数: "+name)
+					return nil, req, false
+				}
+				*dst = n
+			}
+		}
+		floats := map[string]*float64{"position": &req.Position, "start": &req.Start, "end": &req.End, "duration": &req.Duration}
+		for name, dst := range floats {
+			if v := r.FormValue(name); v != "" {
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					sendStudentError(w, http.StatusBadRequest, 40000, "无效的参数: "+name)
+					return nil, req, false
+				}
+				*dst = f
+			}
+		}
+	}
+
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return nil, req, false
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	req.StuID = stuID
+	return db, req, ok
+}
+
+// lessonInfo 课时所属课程、视频和时长
+type lessonInfo struct {
+	LessonID int
+	CourseID int
+	VideoID  int
+	Duration float64
+}
+
+// findLesson 查找学生已选课程中已开放的课时，按 lessonId 或 videoId 查找
+func findLesson(ctx context.Context, db *sql.DB, stuID string, lessonID, videoID int) (lessonInfo, error) {
+	var info lessonInfo
+	var video sql.NullInt64
+	var duration sql.NullInt64
+
+	query := `
+		SELECT cc.id, ch.course_id, cc.video_id, v.duration
+		FROM chapter_children cc
+		JOIN chapters ch ON cc.chapter_id = ch.id
+		JOIN courses c ON ch.course_id = c.id
+		JOIN student_courses sc ON sc.course_id = ch.course_id AND sc.stuId = ? AND sc.status <> 'dropped'
+		LEFT JOIN videos v ON cc.video_id = v.id
+		WHERE ` + courseVisibleSQL + ` AND ` + chapterReleasedSQL + `
+	`
+	now := time.Now()
+	var err error
+	switch {
+	case lessonID > 0:
+		err = db.QueryRowContext(ctx, query+" AND cc.id = ?", stuID, now, lessonID).
+			Scan(&info.LessonID, &info.CourseID, &video, &duration)
+	case videoID > 0:
+		err = db.QueryRowContext(ctx, query+" AND cc.video_id = ? ORDER BY cc.id LIMIT 1", stuID, now, videoID).
+			Scan(&info.LessonID, &info.CourseID, &video, &duration)
+	default:
+		return info, errLessonNotFound
+	}
+	if err == sql.ErrNoRows {
+		return info, errLessonNotFound
+	}
+	if err != nil {
+		return info, err
+	}
+	info.VideoID = int(video.Int64)
+	info.Duration = float64(duration.Int64)
+	return info, nil
+}
+
+// lessonProgress 查询课时进度，没有记录时返回零进度
+func lessonProgress(ctx context.Context, db *sql.DB, req progressRequest) (*LessonProgress, error) {
+	info, err := findLesson(ctx, db, req.StuID, req.LessonID, req.VideoID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &progress.Lesson{Duration: info.Duration}
+	row := db.QueryRowContext(ctx, `
+		SELECT position, duration, watched, completed, reported_at
+		FROM lesson_progress WHERE stuId = ? AND lesson_id = ?
+	`, req.StuID, info.LessonID)
+	if err := scanLesson(row, l); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return newLessonProgress(info, l), nil
+}
+
+// recordProgress 在事务中记录一次上报（锁定进度行，同一学生的并发上报串行处理）
+func recordProgress(ctx context.Context, db *sql.DB, req progressRequest, now time.Time) (*LessonProgress, error) {
+	info, err := findLesson(ctx, db, req.StuID, req.LessonID, req.VideoID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT IGNORE INTO lesson_progress (stuId, lesson_id, course_id, watched) VALUES (?, ?, ?, '[]')",
+		req.StuID, info.LessonID, info.CourseID,
+	); err != nil {
+		return nil, err
+	}
+
+	l := &progress.Lesson{}
+	row := tx.QueryRowContext(ctx, `
+		SELECT position, duration, watched, completed, reported_at
+		FROM lesson_progress WHERE stuId = ? AND lesson_id = ? FOR UPDATE
+	`, req.StuID, info.LessonID)
+	if err := scanLesson(row, l); err != nil {
+		return nil, err
+	}
+
+	// 优先使用视频记录的时长，没有时使用播放器上报的时长
+	wasCompleted := l.Completed
+	if info.Duration > 0 {
+		l.Duration = info.Duration
+	} else if l.Duration <= 0 && req.Duration > 0 && req.Duration <= maxReportedDuration.Seconds() {
+		l.Duration = req.Duration
+	}
+	info.Duration = l.Duration
+
+	if err := l.Apply(progress.Report{Position: req.Position, Start: req.Start, End: req.End}, now); err != nil {
+		return nil, err
+	}
+
+	watched, err := json.Marshal(l.Watched)
+	if err != nil {
+		return nil, err
+	}
+	var completedAt interface{}
+	if l.Completed && !wasCompleted {
+		completedAt = now
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE lesson_progress
+		SET position = ?, duration = ?, watched = ?, watched_seconds = ?, completed = ?,
+			completed_at = COALESCE(completed_at, ?), reported_at = ?
+		WHERE stuId = ? AND lesson_id = ?
+	`, l.Position, l.Duration, watched, progress.Covered(l.Watched), l.Completed, completedAt, now,
+		req.StuID, info.LessonID,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return newLessonProgress(info, l), nil
+}
+
+// scanLesson 读取进度行
+func scanLesson(row *sql.Row, l *progress.Lesson) error {
+	var watched string
+	var duration float64
+	var reported sql.NullTime
+	if err := row.Scan(&l.Position, &duration, &watched, &l.Completed, &reported); err != nil {
+		return err
+	}
+	if duration > 0 {
+		l.Duration = duration
+	}
+	if err := json.Unmarshal([]byte(watched), &l.Watched); err != nil {
+		return fmt.Errorf("解析已观看区间失败: %w", err)
+	}
+	l.Watched = progress.Merge(l.Watched)
+	l.Reported = reported.Time
+	return nil
+}
+
+func newLessonProgress(info lessonInfo, l *progress.Lesson) *LessonProgress {
+	watched := l.Watched
+	if watched == nil {
+		watched = []progress.Interval{}
+	}
+	return &LessonProgress{
+		LessonID:       info.LessonID,
+		CourseID:       info.CourseID,
+		VideoID:        info.VideoID,
+		Position:       l.Position,
+		Resume:         l.Resume(),
+		Duration:       l.Duration,
+		Watched:        watched,
+		WatchedSeconds: progress.Covered(watched),
+		Percent:        l.Percent(),
+		Completed:      l.Completed,
+	}
+}
+
+// courseProgress 汇总学生在多门课程中的进度，只统计有视频的课时
+func courseProgress(ctx context.Context, db *sql.DB, stuID string, courseIDs []int) (map[int]*CourseProgress, error) {
+	result := make(map[int]*CourseProgress, len(courseIDs))
+	if len(courseIDs) == 0 {
+		return result, nil
+	}
+
+	args := []interface{}{stuID}
+	for _, id := range courseIDs {
+		args = append(args, id)
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT ch.course_id, ch.id, ch.title, cc.id, cc.title,
+			COALESCE(lp.completed, 0), COALESCE(lp.watched_seconds, 0), COALESCE(lp.position, 0),
+			COALESCE(NULLIF(lp.duration, 0), v.duration, 0), lp.reported_at
+		FROM chapters ch
+		JOIN chapter_children cc ON cc.chapter_id = ch.id
+		LEFT JOIN videos v ON cc.video_id = v.id
+		LEFT JOIN lesson_progress lp ON lp.lesson_id = cc.id AND lp.stuId = ?
+		WHERE cc.video_id IS NOT NULL AND ch.course_id IN (?`+strings.Repeat(", ?", len(courseIDs)-1)+`)
+		ORDER BY ch.course_id, ch.sort_order, ch.id, cc.sort_order, cc.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lastReported = make(map[int]time.Time)
+	for rows.Next() {
+		var courseID, chapterID, lessonID int
+		var chapterTitle, lessonTitle string
+		var l progress.Lesson
+		var watchedSeconds float64
+		var reported sql.NullTime
+		if err := rows.Scan(&courseID, &chapterID, &chapterTitle, &lessonID, &lessonTitle,
+			&l.Completed, &watchedSeconds, &l.Position, &l.Duration, &reported); err != nil {
+			return nil, err
+		}
+
+		cp := result[courseID]
+		if cp == nil {
+			cp = &CourseProgress{CourseID: courseID, Chapters: []ChapterProgress{}}
+			result[courseID] = cp
+		}
+		if n := len(cp.Chapters); n == 0 || cp.Chapters[n-1].ChapterID != chapterID {
+			cp.Chapters = append(cp.Chapters, ChapterProgress{ChapterID: chapterID, Title: chapterTitle, Lessons: []LessonSummary{}})
+		}
+		chapter := &cp.Chapters[len(cp.Chapters)-1]
+
+		ratio := progress.Ratio(watchedSeconds, l.Duration)
+		lesson := LessonSummary{
+			LessonID:  lessonID,
+			Title:     lessonTitle,
+			Percent:   progress.Percent(ratio),
+			Completed: l.Completed,
+			Resume:    l.Resume(),
+		}
+		if l.Completed {
+			lesson.Percent = 100
+		}
+		chapter.Lessons = append(chapter.Lessons, lesson)
+		chapter.Add(l.Completed, ratio)
+		cp.Add(l.Completed, ratio)
+
+		if reported.Valid && reported.Time.After(lastReported[courseID]) {
+			lastReported[courseID] = reported.Time
+			last := lesson
+			cp.Last = &last
+		}
+	}
+	return result, rows.Err()
+}
+
+// sendProgressData 返回成功响应
+func sendProgressData(w http.ResponseWriter, data interface{}) {
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": data,
+	}); err != nil {
+		log.Printf("编码响应失败: %v", err)
+	}
+}
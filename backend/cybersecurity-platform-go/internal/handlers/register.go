@@ -4,15 +4,14 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"os"
 	"path/filepath"
 	"time"
 
 	"cybersecurity-platform-go/internal/config"
 	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/storage"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -111,16 +110,9 @@ func handleFormRegister(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// 加载配置获取用户头像目录
-		cfg := config.LoadConfig()
-		userImageDir := cfg.UserImageDir
-		if userImageDir == "" {
-			userImageDir = filepath.Join("assets", "image", "user")
-		}
-
-		// 确保目录存在
-		if err := os.MkdirAll(userImageDir, 0755); err != nil {
-			log.Printf("创建目录失败: %v", err)
+		store, err := uploadStorage()
+		if err != nil {
+			log.Printf("初始化存储失败: %v", err)
 			sendRegisterError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
 			return
 		}
@@ -131,19 +123,10 @@ func handleFormRegister(w http.ResponseWriter, r *http.Request) {
 			ext = ".jpg"
 		}
 		avatarFilename = fmt.Sprintf("avatar-%d%s", time.Now().UnixNano(), ext)
-		avatarPath := filepath.Join(userImageDir, avatarFilename)
 
 		// 保存文件
-		dst, err := os.Create(avatarPath)
-		if err != nil {
-			log.Printf("创建文件失败: %v", err)
-			sendRegisterError(w, http.StatusInternalServerError, 50000, "保存头像失败")
-			return
-		}
-		defer dst.Close()
-
-		if _, err := io.Copy(dst, file); err != nil {
-			log.Printf("保存文件内容失败: %v", err)
+		if err := store.Put(r.Context(), storage.PrefixUserImages+avatarFilename, file, handler.Size, handler.Header.Get("Content-Type")); err != nil {
+			log.Printf("保存头像失败: %v", err)
 			sendRegisterError(w, http.StatusInternalServerError, 50000, "保存头像失败")
 			return
 		}
@@ -161,6 +144,15 @@ func handleFormRegister(w http.ResponseWriter, r *http.Request) {
 	registerUser(w, req, avatarFilename)
 }
 
+// uploadStorage 获取上传文件使用的存储
+// 服务启动时会设置全局存储，未设置时（如单独使用处理器）根据当前配置创建
+func uploadStorage() (storage.Storage, error) {
+	if s := storage.Default(); s != nil {
+		return s, nil
+	}
+	return storage.Open(config.LoadConfig(), "")
+}
+
 // registerUser 用户注册逻辑
 func registerUser(w http.ResponseWriter, req RegisterRequest, avatarFilename string) {
 	// 获取数据库连接
@@ -0,0 +1,278 @@
+// internal/handlers/review.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// 公开评价列表每页最多条数
+const maxReviewPageSize = 50
+
+// submitReviewRequest 学生评分和评价
+type submitReviewRequest struct {
+	CourseID int    `json:"courseId"`
+	Rating   int    `json:"rating"`
+	Content  string `json:"content"`
+}
+
+// replyReviewRequest 教师回复评价，reply 为空表示删除回复
+type replyReviewRequest struct {
+	Reply string `json:"reply"`
+}
+
+// sendReviewError 学生提交或删除评价失败
+func sendReviewError(w http.ResponseWriter, err error) {
+	var input ops.InputError
+	switch {
+	case errors.As(err, &input):
+		sendStudentError(w, http.StatusBadRequest, 40000, err.Error())
+	case errors.Is(err, ops.ErrCourseNotFound):
+		sendStudentError(w, http.StatusNotFound, 40400, "课程不存在")
+	case errors.Is(err, ops.ErrNotEnrolled):
+		sendStudentError(w, http.StatusOK, 40005, "选修该课程后才能评价")
+	case errors.Is(err, ops.ErrReviewProgress):
+		sendStudentError(w, http.StatusOK, 40012, err.Error())
+	case errors.Is(err, ops.ErrReviewNotFound):
+		sendStudentError(w, http.StatusOK, 40013, "还没有评价该课程")
+	default:
+		log.Printf("保存课程评价失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+	}
+}
+
+// studentReviewHandler 学生能否评价课程、当前学习进度和已提交的评价
+// GET /api/student/review?courseId=3
+func studentReviewHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	courseID, err := strconv.Atoi(r.URL.Query().Get("courseId"))
+	if err != nil || courseID <= 0 {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数不完整")
+		return
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+	e, err := ops.StudentReviewEligibility(db, stuID, courseID)
+	if err != nil {
+		log.Printf("查询课程评价失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": 20000, "data": e})
+}
+
+// submitReviewHandler 学生评分和评价课程，已评价时修改原评价
+func submitReviewHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var req submitReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数解析失败")
+		return
+	}
+	if req.CourseID <= 0 {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数不完整")
+		return
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+	if err := ops.SubmitReview(db, stuID, req.CourseID, ops.ReviewInput{Rating: req.Rating, Content: req.Content}); err != nil {
+		sendReviewError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(StudentJoinResponse{Code: 20000, Message: "评价已提交"})
+}
+
+// deleteReviewHandler 学生删除自己的评价
+func deleteReviewHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var req studentCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数解析失败")
+		return
+	}
+	if req.CourseID <= 0 {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数不完整")
+		return
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+	if err := ops.DeleteReview(db, stuID, req.CourseID); err != nil {
+		sendReviewError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(StudentJoinResponse{Code: 20000, Message: "评价已删除"})
+}
+
+// courseReviewsHandler 课程公开的评价（已通过审核且有文字）
+// GET /api/courses/{id}/reviews?page=1&pageSize=10
+func courseReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	courseID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || courseID <= 0 {
+		sendCourseError(w, http.StatusBadRequest, 400, "无效的课程ID")
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > maxReviewPageSize {
+		pageSize = maxReviewPageSize
+	}
+
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	list, total, err := ops.CourseReviews(db, courseID, page, pageSize)
+	if err != nil {
+		log.Printf("查询课程评价失败: %v", err)
+		sendCourseError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": map[string]interface{}{"reviewList": list, "total": total},
+	})
+}
+
+func teacherCourseReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseReviewsManageHandler(teacherID, w, r)
+}
+
+// courseReviewsManageHandler 课程的全部评价（含审核中和未通过的），teacherID 为0表示管理员
+func courseReviewsManageHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	list, err := ops.TeacherCourseReviews(db, teacherID, courseID)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, list)
+}
+
+func teacherReplyReviewHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	replyReviewHandler(teacherID, w, r)
+}
+
+// replyReviewHandler 回复课程评价，teacherID 为0表示管理员
+func replyReviewHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	reviewID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req replyReviewRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.ReplyReview(db, teacherID, int64(reviewID), req.Reply); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+// adminReviewsHandler 评价审核队列，默认待审核
+func adminReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = ops.ReviewPending
+	case ops.ReviewPending, ops.ReviewApproved, ops.ReviewRejected:
+	default:
+		sendAdminError(w, http.StatusBadRequest, 40000, "无效的评价状态")
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	list, err := ops.ReviewsByStatus(db, status)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, list)
+}
+
+// moderateReviewHandler 通过或拒绝课程评价
+func moderateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	reviewID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req reviewRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.ModerateReview(db, int64(reviewID), req.Approve, req.Note); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
@@ -2,25 +2,33 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
 )
 
 // StudentJoinRequest 学生加入课程请求
+// InviteCode 用于凭邀请码加入的课程，Message 为需要审核的课程的申请说明
 type StudentJoinRequest struct {
-	CourseID int    `json:"courseId"`
-	StuID    string `json:"stuId"`
+	CourseID   int    `json:"courseId"`
+	StuID      string `json:"stuId"`
+	InviteCode string `json:"inviteCode,omitempty"`
+	Message    string `json:"message,omitempty"`
 }
 
-// StudentJoinResponse 学生加入课程响应
+// StudentJoinResponse 学生加入课程响应，提交选课申请时 status 为 pending
 type StudentJoinResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	Status  string `json:"status,omitempty"`
 }
 
 // MyCourse 我的课程信息
@@ -32,6 +40,10 @@ type MyCourse struct {
 	Count2      int    `json:"count2"`  // 限制人数
 	Cover       string `json:"cover"`
 	Career      string `json:"career"`
+	Progress    int    `json:"progress"`         // 课程完成度（百分比）
+	Completed   int    `json:"completedLessons"` // 已完成的课时数
+	Lessons     int    `json:"totalLessons"`     // 有视频的课时数
+	Status      string `json:"status"`           // 选课状态：active、completed、failed 或 dropped
 }
 
 // MyCoursesResponse 我的课程响应
@@ -43,11 +55,17 @@ type MyCoursesResponse struct {
 	} `json:"data"`
 }
 
-// CheckEnrollmentResponse 检查选课状态响应
+// CheckEnrollmentResponse 检查选课状态响应，未选课时 waitlist 为候补状态（不在候补名单中为 null）
+// enrollment 为选课记录（从未选课为 null），已退课的学生 isEnrolled 为 false；canDrop 表示现在是否可以退课
+// request 为需要审核的课程的选课申请（没有申请为 null）
 type CheckEnrollmentResponse struct {
 	Code int `json:"code"`
 	Data struct {
-		IsEnrolled bool `json:"isEnrolled"`
+		IsEnrolled bool                   `json:"isEnrolled"`
+		Enrollment *ops.Enrollment        `json:"enrollment"`
+		CanDrop    bool                   `json:"canDrop"`
+		Waitlist   *ops.WaitlistEntry     `json:"waitlist"`
+		Request    *ops.EnrollmentRequest `json:"request"`
 	} `json:"data"`
 }
 
@@ -64,6 +82,30 @@ func RegisterStudentRoutes() *http.ServeMux {
 	// 检查选课状态
 	mux.HandleFunc("GET /api/student/checkEnrollment", checkEnrollmentHandler)
 
+	// 退课和选课记录
+	mux.HandleFunc("POST /api/student/dropCourse", dropCourseHandler)
+	mux.HandleFunc("GET /api/student/enrollmentHistory", enrollmentHistoryHandler)
+
+	// 先修课程检查：是否满足、还缺哪些课程
+	mux.HandleFunc("GET /api/student/prerequisites", prerequisiteCheckHandler)
+
+	// 撤回待审核的选课申请
+	mux.HandleFunc("POST /api/student/enrollmentRequest/cancel", cancelEnrollmentRequestHandler)
+
+	// 课程评分和评价
+	mux.HandleFunc("GET /api/student/review", studentReviewHandler)
+	mux.HandleFunc("POST /api/student/review", submitReviewHandler)
+	mux.HandleFunc("POST /api/student/review/delete", deleteReviewHandler)
+
+	// 满员课程的候补名单
+	mux.HandleFunc("GET /api/student/waitlist", waitlistStatusHandler)
+	mux.HandleFunc("POST /api/student/waitlist", joinWaitlistHandler)
+	mux.HandleFunc("POST /api/student/waitlist/leave", leaveWaitlistHandler)
+
+	// 站内通知
+	mux.HandleFunc("GET /api/student/notifications", notificationsHandler)
+	mux.HandleFunc("POST /api/student/notifications/read", readNotificationsHandler)
+
 	return mux
 }
 
@@ -104,11 +146,18 @@ func myCoursesHandler(w http.ResponseWriter, r *http.Request) {
 	stuID := r.URL.Query().Get("stuId")
 	pageStr := r.URL.Query().Get("page")
 	sizeStr := r.URL.Query().Get("size")
+	status := r.URL.Query().Get("status")
 
 	if stuID == "" {
 		sendStudentError(w, http.StatusBadRequest, 40000, "缺少学生ID参数")
 		return
 	}
+	switch status {
+	case "", "all", ops.EnrollActive, ops.EnrollDropped, ops.EnrollCompleted, ops.EnrollFailed:
+	default:
+		sendStudentError(w, http.StatusBadRequest, 40000, "无效的选课状态")
+		return
+	}
 
 	// 设置默认值
 	page := 1
@@ -127,7 +176,7 @@ func myCoursesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 调用获取我的课程逻辑
-	myCourses(w, stuID, page, size)
+	myCourses(w, stuID, status, page, size)
 }
 
 // checkEnrollmentHandler 检查选课状态处理器
@@ -189,48 +238,28 @@ func joinCourse(w http.ResponseWriter, req StudentJoinRequest) {
 		return
 	}
 
-	// 检查课程是否存在
-	var courseExists bool
-	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM courses WHERE id = ?)", req.CourseID).Scan(&courseExists)
-	if err != nil {
-		log.Printf("检查课程存在失败: %v", err)
-		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+	// 加入课程：锁定课程行后检查人数并插入，并发选课不会超出人数限制；重复提交不会重复加入
+	// 需要审核的课程只提交选课申请，凭邀请码加入的课程先检查邀请码
+	pending, err := ops.Enroll(db, req.StuID, req.CourseID, ops.EnrollOptions{InviteCode: req.InviteCode, Message: req.Message})
+	var input ops.InputError
+	if errors.As(err, &input) {
+		sendStudentError(w, http.StatusBadRequest, 40000, err.Error())
 		return
 	}
-
-	if !courseExists {
-		sendStudentError(w, http.StatusNotFound, 40400, "课程不存在")
+	var prereq *ops.PrerequisiteError
+	if errors.As(err, &prereq) {
+		sendPrerequisiteError(w, prereq)
 		return
 	}
-
-	// 检查学生是否存在
-	var studentExists bool
-	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)", req.StuID).Scan(&studentExists)
-	if err != nil {
-		log.Printf("检查学生存在失败: %v", err)
-		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+	switch err {
+	case nil:
+	case ops.ErrCourseNotFound:
+		sendStudentError(w, http.StatusNotFound, 40400, "课程不存在")
 		return
-	}
-
-	if !studentExists {
+	case ops.ErrUserNotFound:
 		sendStudentError(w, http.StatusNotFound, 40401, "学生不存在")
 		return
-	}
-
-	// 检查是否已加入
-	var alreadyJoined bool
-	err = db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM student_courses WHERE stuId = ? AND course_id = ?)",
-		req.StuID, req.CourseID,
-	).Scan(&alreadyJoined)
-
-	if err != nil {
-		log.Printf("检查选课状态失败: %v", err)
-		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
-		return
-	}
-
-	if alreadyJoined {
+	case ops.ErrAlreadyEnrolled:
 		response := StudentJoinResponse{
 			Code:    40001,
 			Message: "已加入该课程",
@@ -238,41 +267,22 @@ func joinCourse(w http.ResponseWriter, req StudentJoinRequest) {
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(response)
 		return
-	}
-
-	// 检查课程人数是否已满
-	var limitCount, currentCount int
-	err = db.QueryRow(`
-		SELECT c.limit_count, 
-		       (SELECT COUNT(*) FROM student_courses WHERE course_id = ?) as current_count
-		FROM courses c
-		WHERE c.id = ?
-	`, req.CourseID, req.CourseID).Scan(&limitCount, &currentCount)
-
-	if err != nil {
-		log.Printf("检查课程人数失败: %v", err)
-		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
-		return
-	}
-
-	if currentCount >= limitCount {
+	case ops.ErrCourseFull:
 		response := StudentJoinResponse{
 			Code:    40002,
-			Message: "课程人数已满",
+			Message: "课程人数已满，可以加入候补名单",
 		}
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(response)
 		return
-	}
-
-	// 插入选课记录
-	_, err = db.Exec(
-		"INSERT INTO student_courses (stuId, course_id) VALUES (?, ?)",
-		req.StuID, req.CourseID,
-	)
-
-	if err != nil {
-		log.Printf("插入选课记录失败: %v", err)
+	case ops.ErrInviteRequired:
+		sendStudentError(w, http.StatusOK, 40007, err.Error())
+		return
+	case ops.ErrInviteInvalid, ops.ErrInviteExpired, ops.ErrInviteUsedUp:
+		sendStudentError(w, http.StatusOK, 40008, err.Error())
+		return
+	default:
+		log.Printf("加入课程失败: %v", err)
 		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
 		return
 	}
@@ -282,12 +292,17 @@ func joinCourse(w http.ResponseWriter, req StudentJoinRequest) {
 		Code:    20000,
 		Message: "加入课程成功",
 	}
+	if pending {
+		response.Message = "已提交选课申请，等待教师审核"
+		response.Status = ops.RequestPending
+	}
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
 // myCourses 获取我的课程的核心逻辑
-func myCourses(w http.ResponseWriter, stuID string, page, size int) {
+// status 为空时返回已退课以外的课程，all 返回全部，其他值只返回该状态的课程
+func myCourses(w http.ResponseWriter, stuID, status string, page, size int) {
 	// 获取数据库连接
 	db, err := database.GetDB()
 	if err != nil {
@@ -298,18 +313,34 @@ func myCourses(w http.ResponseWriter, stuID string, page, size int) {
 
 	offset := (page - 1) * size
 
-	// 查询我的课程
+	where := "sc.stuId = ? AND c.status IN ('published', 'archived')"
+	args := []interface{}{stuID}
+	switch status {
+	case "":
+		where += " AND sc.status <> 'dropped'"
+	case "all":
+	default:
+		where += " AND sc.status = ?"
+		args = append(args, status)
+	}
+
+	// 查询我的课程，多名教师时只显示排在最前的负责人
 	query := `
-		SELECT c.*, t.name as teacher_name, t.career as teacher_career
+		SELECT c.id, c.title, c.cover, c.lesson_num, c.limit_count,
+		       t.name as teacher_name, t.career as teacher_career, sc.status
 		FROM student_courses sc
 		JOIN courses c ON sc.course_id = c.id
-		LEFT JOIN teacher_courses tc ON c.id = tc.course_id
+		LEFT JOIN teacher_courses tc ON tc.id = (
+			SELECT tc2.id FROM teacher_courses tc2 WHERE tc2.course_id = c.id
+			ORDER BY FIELD(tc2.role, 'lead', 'co_teacher', 'ta'), tc2.sort_order, tc2.id LIMIT 1
+		)
 		LEFT JOIN teachers t ON tc.teacher_id = t.id
-		WHERE sc.stuId = ?
+		WHERE ` + where + `
+		ORDER BY sc.joined_at DESC, sc.id DESC
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := db.Query(query, stuID, size, offset)
+	rows, err := db.Query(query, append(args, size, offset)...)
 	if err != nil {
 		log.Printf("查询我的课程失败: %v", err)
 		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
@@ -327,21 +358,18 @@ func myCourses(w http.ResponseWriter, stuID string, page, size int) {
 			LimitCount   int
 			TeacherName  sql.NullString
 			TeacherCareer sql.NullString
+			Status       string
 		}
 
-		// 跳过不需要的字段：description, credit, created_at, updated_at
 		err := rows.Scan(
 			&course.ID,
 			&course.Title,
-			new(interface{}), // description - 跳过
 			&course.Cover,
 			&course.LessonNum,
-			new(interface{}), // credit - 跳过
 			&course.LimitCount,
-			new(interface{}), // created_at - 跳过
-			new(interface{}), // updated_at - 跳过
 			&course.TeacherName,
 			&course.TeacherCareer,
+			&course.Status,
 		)
 
 		if err != nil {
@@ -357,6 +385,7 @@ func myCourses(w http.ResponseWriter, stuID string, page, size int) {
 			Count2:      course.LimitCount,
 			Cover:       course.Cover,
 			Career:      course.TeacherCareer.String,
+			Status:      course.Status,
 		}
 
 		if myCourse.TeacherName == "" {
@@ -366,11 +395,28 @@ func myCourses(w http.ResponseWriter, stuID string, page, size int) {
 		courses = append(courses, myCourse)
 	}
 
+	// 汇总学习进度
+	courseIDs := make([]int, len(courses))
+	for i := range courses {
+		courseIDs[i] = courses[i].ID
+	}
+	if rollups, err := courseProgress(context.Background(), db, stuID, courseIDs); err != nil {
+		log.Printf("查询课程进度失败: %v", err)
+	} else {
+		for i := range courses {
+			if cp := rollups[courses[i].ID]; cp != nil {
+				courses[i].Progress = cp.Percent
+				courses[i].Completed = cp.Completed
+				courses[i].Lessons = cp.Total
+			}
+		}
+	}
+
 	// 获取总数
 	var total int
 	err = db.QueryRow(
-		"SELECT COUNT(*) FROM student_courses WHERE stuId = ?",
-		stuID,
+		"SELECT COUNT(*) FROM student_courses sc JOIN courses c ON sc.course_id = c.id WHERE "+where,
+		args...,
 	).Scan(&total)
 
 	if err != nil {
@@ -399,24 +445,32 @@ func checkEnrollment(w http.ResponseWriter, courseID int, stuID string) {
 		return
 	}
 
-	// 检查是否已选课
-	var isEnrolled bool
-	err = db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM student_courses WHERE stuId = ? AND course_id = ?)",
-		stuID, courseID,
-	).Scan(&isEnrolled)
-
+	// 检查选课记录，已退课视为未选课
+	enrollment, err := ops.StudentEnrollment(db, stuID, courseID)
 	if err != nil {
 		log.Printf("检查选课状态失败: %v", err)
 		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
 		return
 	}
+	isEnrolled := enrollment != nil && enrollment.Status != ops.EnrollDropped
 
 	// 构建响应
 	response := CheckEnrollmentResponse{
 		Code: 20000,
 	}
 	response.Data.IsEnrolled = isEnrolled
+	response.Data.Enrollment = enrollment
+	response.Data.CanDrop = enrollment != nil && enrollment.CanDrop(time.Now())
+	if !isEnrolled {
+		response.Data.Waitlist, err = ops.WaitlistStatus(db, stuID, courseID)
+		if err != nil {
+			log.Printf("查询候补状态失败: %v", err)
+		}
+		response.Data.Request, err = ops.StudentEnrollmentRequest(db, stuID, courseID)
+		if err != nil {
+			log.Printf("查询选课申请失败: %v", err)
+		}
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
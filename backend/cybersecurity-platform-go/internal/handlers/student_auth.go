@@ -0,0 +1,74 @@
+// internal/handlers/student_auth.go
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// sessionStudent 返回请求中登录会话对应的学号，没有令牌或会话无效时返回空字符串
+// 令牌由登录接口签发，与教师令牌一样通过 "Authorization: Bearer <token>" 或 "X-Token" 请求头传入
+// 媒体地址的签名绑定到该学号，不能使用客户端传入的 stuId 参数
+func sessionStudent(db *sql.DB, r *http.Request) (string, error) {
+	token := requestToken(r)
+	if token == "" {
+		return "", nil
+	}
+	stuID, err := ops.StudentSession(db, token, time.Now())
+	if errors.Is(err, ops.ErrSessionInvalid) {
+		return "", nil
+	}
+	return stuID, err
+}
+
+// studentCourseRequest 学生对某门课程的操作，学生取自登录会话
+type studentCourseRequest struct {
+	CourseID int `json:"courseId"`
+}
+
+// studentFromSession 返回登录会话中的学号，没有登录时返回401
+// 学生接口只认会话中的学生，不使用客户端传入的 stuId，否则知道学号就能以他人身份操作
+func studentFromSession(w http.ResponseWriter, r *http.Request, db *sql.DB) (string, bool) {
+	stuID, err := sessionStudent(db, r)
+	if err != nil {
+		log.Printf("校验登录会话失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return "", false
+	}
+	if stuID == "" {
+		sendStudentError(w, http.StatusUnauthorized, 40100, "请先登录")
+		return "", false
+	}
+	return stuID, true
+}
+
+// LogoutHandler 退出登录，删除请求中的会话令牌
+// POST /api/logout
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost {
+		sendLoginError(w, http.StatusMethodNotAllowed, 405, "方法不允许")
+		return
+	}
+
+	if token := requestToken(r); token != "" {
+		db, err := database.GetDB()
+		if err != nil {
+			log.Printf("获取数据库连接失败: %v", err)
+			sendLoginError(w, http.StatusInternalServerError, 50000, "退出失败，请重试")
+			return
+		}
+		if err := ops.RevokeStudentSession(db, token); err != nil {
+			log.Printf("删除登录会话失败: %v", err)
+			sendLoginError(w, http.StatusInternalServerError, 50000, "退出失败，请重试")
+			return
+		}
+	}
+	sendLoginError(w, http.StatusOK, 20000, "已退出登录")
+}
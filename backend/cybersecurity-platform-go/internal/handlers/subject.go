@@ -0,0 +1,103 @@
+// internal/handlers/subject.go
+package handlers
+
+import (
+	"net/http"
+
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// RegisterSubjectRoutes 注册课程分类的公开路由
+//
+//	GET /api/app/edu/pub/subject/get   分类树及各分类下已发布的课程数
+func RegisterSubjectRoutes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/app/edu/pub/subject/get", subjectTreeHandler)
+
+	return mux
+}
+
+// subjectTreeHandler 学生端的分类树
+func subjectTreeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	tree, err := ops.SubjectTree(db, true)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, map[string]interface{}{"list": tree})
+}
+
+// adminSubjectsHandler 管理员查看分类树，课程数包含未发布的课程
+func adminSubjectsHandler(w http.ResponseWriter, r *http.Request) {
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	tree, err := ops.SubjectTree(db, false)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, map[string]interface{}{"list": tree})
+}
+
+func createSubjectHandler(w http.ResponseWriter, r *http.Request) {
+	var in ops.SubjectInput
+	if !decodeAuthoring(w, r, &in) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	id, err := ops.CreateSubject(db, in)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, map[string]int64{"id": id})
+}
+
+func updateSubjectHandler(w http.ResponseWriter, r *http.Request) {
+	subjectID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var in ops.SubjectInput
+	if !decodeAuthoring(w, r, &in) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.UpdateSubject(db, subjectID, in); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func deleteSubjectHandler(w http.ResponseWriter, r *http.Request) {
+	subjectID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.DeleteSubject(db, subjectID); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
@@ -0,0 +1,405 @@
+// internal/handlers/subtitle.go
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+	"cybersecurity-platform-go/internal/storage"
+	"cybersecurity-platform-go/internal/subtitle"
+)
+
+// 字幕文件最大大小
+const maxSubtitleSize = 2 << 20
+
+// 每条 INSERT 语句写入的字幕条数
+const cueBatchSize = 500
+
+// Subtitle 字幕轨道
+type Subtitle struct {
+	Lang     string `json:"lang"`
+	Label    string `json:"label"`
+	URL      string `json:"url"`
+	CueCount int    `json:"cueCount"`
+}
+
+// TranscriptHit 字幕检索结果
+type TranscriptHit struct {
+	VideoID     int     `json:"videoId"`
+	CourseID    int     `json:"courseId"`
+	LessonTitle string  `json:"lessonTitle"`
+	Lang        string  `json:"lang"`
+	Start       float64 `json:"start"` // 秒
+	End         float64 `json:"end"`
+	Text        string  `json:"text"`
+}
+
+// RegisterSubtitleRoutes 注册教师管理字幕的路由
+//
+//	GET    /api/teacher/videos/{id}/subtitles         字幕轨道列表
+//	PUT    /api/teacher/videos/{id}/subtitles/{lang}  上传或替换字幕（请求体为 WebVTT 或 SRT 文件）
+//	DELETE /api/teacher/videos/{id}/subtitles/{lang}  删除字幕
+func RegisterSubtitleRoutes(store storage.Storage) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/teacher/videos/{id}/subtitles", TeacherAuth(listSubtitlesHandler))
+	mux.HandleFunc("PUT /api/teacher/videos/{id}/subtitles/{lang}", TeacherAuth(func(w http.ResponseWriter, r *http.Request) {
+		putSubtitleHandler(store, w, r)
+	}))
+	mux.HandleFunc("DELETE /api/teacher/videos/{id}/subtitles/{lang}", TeacherAuth(func(w http.ResponseWriter, r *http.Request) {
+		deleteSubtitleHandler(store, w, r)
+	}))
+
+	return mux
+}
+
+// teacherVideo 解析路径中的视频ID并检查教师是否可以管理该视频：
+// 视频由该教师上传，或属于该教师有修改内容权限的课程
+func teacherVideo(w http.ResponseWriter, r *http.Request) (*sql.DB, int, int, bool) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	videoID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || videoID <= 0 {
+		sendAdminError(w, http.StatusBadRequest, 40000, "无效的视频ID")
+		return nil, 0, 0, false
+	}
+
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return nil, 0, 0, false
+	}
+
+	allowed, err := ops.CanEditVideo(db, teacherID, videoID)
+	if err == sql.ErrNoRows {
+		sendAdminError(w, http.StatusNotFound, 40400, "视频不存在")
+		return nil, 0, 0, false
+	}
+	if err != nil {
+		log.Printf("检查视频权限失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return nil, 0, 0, false
+	}
+	if !allowed {
+		sendAdminError(w, http.StatusForbidden, 40300, "没有管理该视频的权限")
+		return nil, 0, 0, false
+	}
+	return db, teacherID, videoID, true
+}
+
+// listSubtitlesHandler 字幕轨道列表
+func listSubtitlesHandler(w http.ResponseWriter, r *http.Request) {
+	db, _, videoID, ok := teacherVideo(w, r)
+	if !ok {
+		return
+	}
+	subtitles, err := listSubtitles(r.Context(), db, videoID)
+	if err != nil {
+		log.Printf("查询字幕失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": subtitles,
+	})
+}
+
+// putSubtitleHandler 上传字幕，SRT 转换为 WebVTT 后保存，并为字幕文本建立检索索引
+// 格式由 format 参数、Content-Type 或文件内容确定；label 参数为显示名称（默认为语言标签）
+func putSubtitleHandler(store storage.Storage, w http.ResponseWriter, r *http.Request) {
+	db, teacherID, videoID, ok := teacherVideo(w, r)
+	if !ok {
+		return
+	}
+
+	lang := r.PathValue("lang")
+	if !subtitle.ValidLang(lang) {
+		sendAdminError(w, http.StatusBadRequest, 40000, "无效的语言标签，例如 zh、en、zh-Hans")
+		return
+	}
+	label := strings.TrimSpace(r.URL.Query().Get("label"))
+	if label == "" {
+		label = lang
+	}
+	if utf8.RuneCountInString(label) > 64 {
+		sendAdminError(w, http.StatusBadRequest, 40000, "显示名称过长")
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		switch ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct {
+		case "text/vtt":
+			format = subtitle.FormatVTT
+		case "application/x-subrip", "text/srt":
+			format = subtitle.FormatSRT
+		}
+	}
+	if format != "" && format != subtitle.FormatVTT && format != subtitle.FormatSRT {
+		sendAdminError(w, http.StatusBadRequest, 40000, "只支持 vtt 和 srt 格式")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSubtitleSize))
+	if err != nil {
+		sendAdminError(w, http.StatusRequestEntityTooLarge, 41300, "字幕文件过大")
+		return
+	}
+	cues, err := subtitle.Parse(data, format)
+	if err != nil {
+		sendAdminError(w, http.StatusBadRequest, 40000, "字幕格式错误: "+err.Error())
+		return
+	}
+
+	// 字幕不能超出视频时长（时长未知时不检查）
+	var duration int
+	if err := db.QueryRowContext(r.Context(), "SELECT COALESCE(duration, 0) FROM videos WHERE id = ?", videoID).Scan(&duration); err != nil {
+		log.Printf("查询视频时长失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	if last := cues[len(cues)-1]; duration > 0 && last.Start > time.Duration(duration+1)*time.Second {
+		sendAdminError(w, http.StatusBadRequest, 40000,
+			fmt.Sprintf("字幕时间 %s 超出视频时长", subtitle.FormatTimestamp(last.Start)))
+		return
+	}
+
+	key := fmt.Sprintf("%ssubtitles/%d/%s.vtt", storage.PrefixVideos, videoID, lang)
+	vtt := subtitle.WriteVTT(cues)
+	if err := store.Put(r.Context(), key, strings.NewReader(string(vtt)), int64(len(vtt)), "text/vtt; charset=utf-8"); err != nil {
+		log.Printf("保存字幕失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+
+	sub := Subtitle{
+		Lang:     lang,
+		Label:    label,
+		URL:      "/api/videoing/" + strings.TrimPrefix(key, storage.PrefixVideos),
+		CueCount: len(cues),
+	}
+	if err := saveSubtitle(r.Context(), db, videoID, teacherID, sub, cues); err != nil {
+		log.Printf("保存字幕索引失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": sub,
+	})
+}
+
+// saveSubtitle 保存字幕轨道并重建该轨道的文本索引
+func saveSubtitle(ctx context.Context, db *sql.DB, videoID, teacherID int, sub Subtitle, cues []subtitle.Cue) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO video_subtitles (video_id, lang, label, url, cue_count, teacher_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), label = VALUES(label), url = VALUES(url),
+			cue_count = VALUES(cue_count), teacher_id = VALUES(teacher_id)
+	`, videoID, sub.Lang, sub.Label, sub.URL, sub.CueCount, teacherID)
+	if err != nil {
+		return err
+	}
+	subtitleID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM subtitle_cues WHERE subtitle_id = ?", subtitleID); err != nil {
+		return err
+	}
+	for start := 0; start < len(cues); start += cueBatchSize {
+		batch := cues[start:min(start+cueBatchSize, len(cues))]
+		args := make([]interface{}, 0, len(batch)*5)
+		for _, c := range batch {
+			args = append(args, subtitleID, videoID, c.Start.Milliseconds(), c.End.Milliseconds(), c.PlainText())
+		}
+		query := "INSERT INTO subtitle_cues (subtitle_id, video_id, start_ms, end_ms, text) VALUES " +
+			strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?),", len(batch)), ",")
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// deleteSubtitleHandler 删除字幕轨道及其索引
+func deleteSubtitleHandler(store storage.Storage, w http.ResponseWriter, r *http.Request) {
+	db, _, videoID, ok := teacherVideo(w, r)
+	if !ok {
+		return
+	}
+	lang := r.PathValue("lang")
+
+	result, err := db.ExecContext(r.Context(), "DELETE FROM video_subtitles WHERE video_id = ? AND lang = ?", videoID, lang)
+	if err != nil {
+		log.Printf("删除字幕失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		sendAdminError(w, http.StatusNotFound, 40400, "字幕不存在")
+		return
+	}
+	if subtitle.ValidLang(lang) {
+		key := fmt.Sprintf("%ssubtitles/%d/%s.vtt", storage.PrefixVideos, videoID, lang)
+		if err := store.Delete(r.Context(), key); err != nil {
+			log.Printf("删除字幕文件失败: %v", err)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    20000,
+		"message": "字幕已删除",
+	})
+}
+
+// listSubtitles 查询视频的字幕轨道（地址未签名）
+func listSubtitles(ctx context.Context, db *sql.DB, videoID int) ([]Subtitle, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT lang, label, url, cue_count FROM video_subtitles WHERE video_id = ? ORDER BY lang", videoID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subtitles := []Subtitle{}
+	for rows.Next() {
+		var s Subtitle
+		if err := rows.Scan(&s.Lang, &s.Label, &s.URL, &s.CueCount); err != nil {
+			return nil, err
+		}
+		subtitles = append(subtitles, s)
+	}
+	return subtitles, rows.Err()
+}
+
+// SearchTranscripts 在学生已选课程的视频字幕中检索，返回命中的字幕及其时间点
+// GET /api/videos/search?q=SQL注入&stuId=xxx[&courseId=1]
+func SearchTranscripts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	stuID := r.URL.Query().Get("stuId")
+	if stuID == "" {
+		sendError(w, http.StatusUnauthorized, 401, "请先登录")
+		return
+	}
+	if q == "" || utf8.RuneCountInString(q) > 100 {
+		sendError(w, http.StatusBadRequest, 400, "请输入1-100个字的检索内容")
+		return
+	}
+	courseID := 0
+	if v := r.URL.Query().Get("courseId"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			sendError(w, http.StatusBadRequest, 400, "无效的课程ID")
+			return
+		}
+		courseID = id
+	}
+
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+
+	query := `
+		SELECT sc.video_id, ch.course_id, ch.id, cc.title, s.lang, sc.start_ms, sc.end_ms, sc.text
+		FROM subtitle_cues sc
+		JOIN video_subtitles s ON s.id = sc.subtitle_id
+		JOIN chapter_children cc ON cc.video_id = sc.video_id
+		JOIN chapters ch ON ch.id = cc.chapter_id
+		JOIN courses c ON c.id = ch.course_id
+		JOIN student_courses stc ON stc.course_id = ch.course_id AND stc.stuId = ? AND stc.status <> 'dropped'
+		WHERE MATCH(sc.text) AGAINST(? IN NATURAL LANGUAGE MODE)
+			AND ` + courseVisibleSQL + ` AND ` + chapterReleasedSQL
+	args := []interface{}{stuID, q, time.Now()}
+	if courseID > 0 {
+		query += " AND ch.course_id = ?"
+		args = append(args, courseID)
+	}
+	query += `
+		ORDER BY MATCH(sc.text) AGAINST(? IN NATURAL LANGUAGE MODE) DESC, sc.video_id, sc.start_ms
+		LIMIT 20`
+	args = append(args, q)
+
+	rows, err := db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		log.Printf("检索字幕失败: %v", err)
+		sendError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	defer rows.Close()
+
+	type match struct {
+		hit       TranscriptHit
+		chapterID int
+	}
+	var matches []match
+	for rows.Next() {
+		var m match
+		var startMS, endMS int64
+		if err := rows.Scan(&m.hit.VideoID, &m.hit.CourseID, &m.chapterID, &m.hit.LessonTitle, &m.hit.Lang, &startMS, &endMS, &m.hit.Text); err != nil {
+			log.Printf("读取检索结果失败: %v", err)
+			sendError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+			return
+		}
+		m.hit.Start, m.hit.End = float64(startMS)/1000, float64(endMS)/1000
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("读取检索结果失败: %v", err)
+	}
+	rows.Close()
+
+	// 先修章节未学完的章节不返回字幕内容
+	locks := newChapterLockCache(db, stuID)
+	hits := []TranscriptHit{}
+	for _, m := range matches {
+		locked, err := locks.Locked(r.Context(), m.hit.CourseID, m.chapterID)
+		if err != nil {
+			log.Printf("检查章节先修要求失败: %v", err)
+			sendError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+			return
+		}
+		if !locked {
+			hits = append(hits, m.hit)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": map[string]interface{}{
+			"list": hits,
+		},
+	})
+}
@@ -183,7 +183,7 @@ func getTeacherDetail(w http.ResponseWriter, teacherID int) {
 			c.description
 		FROM courses c
 		INNER JOIN teacher_courses tc ON c.id = tc.course_id
-		WHERE tc.teacher_id = ?
+		WHERE tc.teacher_id = ? AND c.status = 'published'
 	`
 	
 	rows, err := db.Query(coursesQuery, teacherID)
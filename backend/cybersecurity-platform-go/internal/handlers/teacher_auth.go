@@ -0,0 +1,57 @@
+// internal/handlers/teacher_auth.go
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+)
+
+type teacherIDKey struct{}
+
+// TeacherAuth 教师令牌校验中间件
+// 令牌由命令行 "teacher token" 签发，通过 "Authorization: Bearer <token>" 或 "X-Token" 请求头传入
+func TeacherAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		token := requestToken(r)
+		if token == "" {
+			sendAdminError(w, http.StatusUnauthorized, 40100, "缺少教师令牌")
+			return
+		}
+
+		db, err := database.GetDB()
+		if err != nil {
+			log.Printf("获取数据库连接失败: %v", err)
+			sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+			return
+		}
+
+		var teacherID int
+		err = db.QueryRowContext(r.Context(),
+			"SELECT id FROM teachers WHERE token_hash = ?", ops.TeacherTokenHash(token),
+		).Scan(&teacherID)
+		if err == sql.ErrNoRows {
+			sendAdminError(w, http.StatusUnauthorized, 40100, "教师认证失败")
+			return
+		}
+		if err != nil {
+			log.Printf("校验教师令牌失败: %v", err)
+			sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), teacherIDKey{}, teacherID)))
+	}
+}
+
+// TeacherIDFromContext 获取 TeacherAuth 校验通过的教师ID
+func TeacherIDFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(teacherIDKey{}).(int)
+	return id, ok
+}
@@ -0,0 +1,94 @@
+// internal/handlers/teaching.go
+package handlers
+
+import (
+	"net/http"
+
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// courseTeachersRequest 设置课程教师，数组顺序即显示顺序
+type courseTeachersRequest struct {
+	Teachers []ops.CourseTeacherInput `json:"teachers"`
+}
+
+// rolePermissionsRequest 设置合作教师或助教的权限，permissions 为 null 表示恢复默认值
+type rolePermissionsRequest struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+func teacherCourseTeamHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	courseTeamHandler(teacherID, w, r)
+}
+
+// courseTeamHandler 课程的教师、角色和各角色的权限，teacherID 为0表示管理员
+func courseTeamHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	team, err := ops.CourseTeamFor(db, teacherID, courseID)
+	if err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, team)
+}
+
+func teacherSetCourseTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	setCourseTeachersHandler(teacherID, w, r)
+}
+
+// setCourseTeachersHandler 设置课程的教师和角色（整体替换），teacherID 为0表示管理员
+func setCourseTeachersHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req courseTeachersRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.SetCourseTeachers(db, teacherID, courseID, req.Teachers); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
+
+func teacherSetRolePermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+	setRolePermissionsHandler(teacherID, w, r)
+}
+
+// setRolePermissionsHandler 设置课程中合作教师或助教的权限，teacherID 为0表示管理员
+func setRolePermissionsHandler(teacherID int, w http.ResponseWriter, r *http.Request) {
+	courseID, ok := authoringPathID(w, r)
+	if !ok {
+		return
+	}
+	var req rolePermissionsRequest
+	if !decodeAuthoring(w, r, &req) {
+		return
+	}
+	db, ok := authoringDB(w)
+	if !ok {
+		return
+	}
+	if err := ops.SetRolePermissions(db, teacherID, courseID, req.Role, req.Permissions); err != nil {
+		sendAuthoringError(w, err)
+		return
+	}
+	sendAuthoringData(w, nil)
+}
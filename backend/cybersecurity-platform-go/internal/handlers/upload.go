@@ -0,0 +1,225 @@
+// internal/handlers/upload.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/mediainfo"
+	"cybersecurity-platform-go/internal/upload"
+)
+
+// uploadChecksumMismatch 分片校验失败的状态码（与 tus 协议一致）
+const uploadChecksumMismatch = 460
+
+// createUploadRequest 创建上传会话的请求体
+type createUploadRequest struct {
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	Sha256      string `json:"sha256"`
+	Description string `json:"description"`
+}
+
+// RegisterUploadRoutes 注册教师视频分片上传路由
+//
+//	POST   /api/uploads/videos       创建上传会话
+//	HEAD   /api/uploads/videos/{id}  查询已接收的字节数（Upload-Offset）
+//	GET    /api/uploads/videos/{id}  查询会话详情
+//	PATCH  /api/uploads/videos/{id}  从 Upload-Offset 处追加分片
+//	DELETE /api/uploads/videos/{id}  取消上传
+func RegisterUploadRoutes(svc *upload.Service) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/uploads/videos", TeacherAuth(func(w http.ResponseWriter, r *http.Request) {
+		createUploadHandler(svc, w, r)
+	}))
+	mux.HandleFunc("HEAD /api/uploads/videos/{id}", TeacherAuth(func(w http.ResponseWriter, r *http.Request) {
+		uploadOffsetHandler(svc, w, r)
+	}))
+	mux.HandleFunc("GET /api/uploads/videos/{id}", TeacherAuth(func(w http.ResponseWriter, r *http.Request) {
+		uploadStatusHandler(svc, w, r)
+	}))
+	mux.HandleFunc("PATCH /api/uploads/videos/{id}", TeacherAuth(func(w http.ResponseWriter, r *http.Request) {
+		uploadChunkHandler(svc, w, r)
+	}))
+	mux.HandleFunc("DELETE /api/uploads/videos/{id}", TeacherAuth(func(w http.ResponseWriter, r *http.Request) {
+		abortUploadHandler(svc, w, r)
+	}))
+
+	return mux
+}
+
+// createUploadHandler 创建上传会话
+func createUploadHandler(svc *upload.Service, w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+
+	var req createUploadRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		sendAdminError(w, http.StatusBadRequest, 40000, "无效的请求数据")
+		return
+	}
+	if req.Filename == "" || req.Size <= 0 {
+		sendAdminError(w, http.StatusBadRequest, 40000, "文件名和文件大小不能为空")
+		return
+	}
+
+	sess, err := svc.Create(r.Context(), teacherID, req.Filename, req.Size, req.Sha256, req.Description)
+	if err != nil {
+		sendUploadError(w, sess, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/uploads/videos/"+sess.ID)
+	setUploadHeaders(w, sess)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": map[string]interface{}{
+			"uploadId":  sess.ID,
+			"offset":    sess.Offset,
+			"size":      sess.Size,
+			"chunkSize": svc.ChunkSize(),
+		},
+	})
+}
+
+// uploadOffsetHandler 返回已接收的字节数，客户端据此断点续传
+func uploadOffsetHandler(svc *upload.Service, w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+
+	sess, err := svc.Status(r.Context(), r.PathValue("id"), teacherID)
+	if err != nil {
+		sendUploadError(w, nil, err)
+		return
+	}
+	setUploadHeaders(w, sess)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// uploadStatusHandler 查询上传会话
+func uploadStatusHandler(svc *upload.Service, w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+
+	sess, err := svc.Status(r.Context(), r.PathValue("id"), teacherID)
+	if err != nil {
+		sendUploadError(w, nil, err)
+		return
+	}
+	setUploadHeaders(w, sess)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": sess,
+	})
+}
+
+// uploadChunkHandler 追加分片
+// 请求头 Upload-Offset 为分片的起始偏移量，可选的 Upload-Checksum 为 "sha256 <base64>"
+func uploadChunkHandler(svc *upload.Service, w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		sendAdminError(w, http.StatusBadRequest, 40000, "缺少或无效的 Upload-Offset 请求头")
+		return
+	}
+
+	sess, err := svc.Append(r.Context(), r.PathValue("id"), teacherID, offset, r.Body, r.Header.Get("Upload-Checksum"))
+	if err != nil {
+		sendUploadError(w, sess, err)
+		return
+	}
+
+	setUploadHeaders(w, sess)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": 20000,
+		"data": sess,
+	})
+}
+
+// abortUploadHandler 取消上传
+func abortUploadHandler(svc *upload.Service, w http.ResponseWriter, r *http.Request) {
+	teacherID, _ := TeacherIDFromContext(r.Context())
+
+	if err := svc.Abort(r.Context(), r.PathValue("id"), teacherID); err != nil {
+		sendUploadError(w, nil, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    20000,
+		"message": "上传已取消",
+	})
+}
+
+// setUploadHeaders 设置断点续传相关的响应头
+func setUploadHeaders(w http.ResponseWriter, sess *upload.Session) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(sess.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(sess.Size, 10))
+}
+
+// sendUploadError 将上传错误转换为响应
+func sendUploadError(w http.ResponseWriter, sess *upload.Session, err error) {
+	var mismatch *upload.OffsetMismatchError
+	switch {
+	case errors.As(err, &mismatch):
+		w.Header().Set("Upload-Offset", strconv.FormatInt(mismatch.Offset, 10))
+		sendAdminError(w, http.StatusConflict, 40900, err.Error())
+	case errors.Is(err, upload.ErrNotFound):
+		sendAdminError(w, http.StatusNotFound, 40400, err.Error())
+	case errors.Is(err, upload.ErrCompleted):
+		if sess != nil {
+			setUploadHeaders(w, sess)
+		}
+		sendAdminError(w, http.StatusConflict, 40901, err.Error())
+	case errors.Is(err, upload.ErrTooLarge), errors.Is(err, upload.ErrChunkTooLarge), errors.Is(err, upload.ErrQuotaExceeded):
+		sendAdminError(w, http.StatusRequestEntityTooLarge, 41300, err.Error())
+	case errors.Is(err, upload.ErrUnsupportedType), errors.Is(err, upload.ErrInvalidVideo):
+		sendAdminError(w, http.StatusUnsupportedMediaType, 41500, err.Error())
+	case errors.Is(err, upload.ErrBadChecksum):
+		sendAdminError(w, http.StatusBadRequest, 40000, err.Error())
+	case errors.Is(err, upload.ErrChecksumMismatch):
+		if sess != nil {
+			setUploadHeaders(w, sess)
+		}
+		sendAdminError(w, uploadChecksumMismatch, 46000, err.Error())
+	default:
+		log.Printf("视频上传失败: %v", err)
+		sendAdminError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+	}
+}
+
+// CreateUploadedVideo 上传完成后创建视频记录（upload.FinalizeFunc）
+func CreateUploadedVideo(ctx context.Context, sess *upload.Session, key string) (int64, error) {
+	db, err := database.GetDB()
+	if err != nil {
+		return 0, err
+	}
+	description := sess.Description
+	if description == "" {
+		description = sess.Filename
+	}
+	info := sess.Media
+	if info == nil {
+		info = &mediainfo.Info{}
+	}
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO videos (url, description, duration, teacher_id, size, width, height, video_codec, audio_codec, bitrate)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, "/api/videoing/"+path.Base(key), description, info.Seconds(), sess.TeacherID, sess.Size,
+		info.Width, info.Height, info.VideoCodec, info.AudioCodec, info.Bitrate,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
@@ -14,11 +14,18 @@ import (
 
 // Video 视频结构体，对应数据库中的videos表
 type Video struct {
-	ID          int    `json:"id"`
-	URL         string `json:"url"`
-	Description string `json:"description"`
-	Duration    int    `json:"duration"`
-	CreatedAt   string `json:"createdAt" db:"created_at"`
+	ID          int        `json:"id"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Duration    int        `json:"duration"`
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+	VideoCodec  string     `json:"videoCodec"`
+	AudioCodec  string     `json:"audioCodec"`
+	Bitrate     int64      `json:"bitrate"`
+	HLSURL      string     `json:"hlsUrl,omitempty"` // HLS 主播放列表，未打包时为空
+	Subtitles   []Subtitle `json:"subtitles"`
+	CreatedAt   string     `json:"createdAt" db:"created_at"`
 }
 
 // VideoResponse 视频API响应格式
@@ -77,6 +84,12 @@ func GetVideoByID(w http.ResponseWriter, r *http.Request) {
 			url,
 			description,
 			duration,
+			width,
+			height,
+			video_codec,
+			audio_codec,
+			bitrate,
+			hls_url,
 			created_at
 		FROM videos
 		WHERE id = ?
@@ -87,6 +100,12 @@ func GetVideoByID(w http.ResponseWriter, r *http.Request) {
 		&video.URL,
 		&video.Description,
 		&video.Duration,
+		&video.Width,
+		&video.Height,
+		&video.VideoCodec,
+		&video.AudioCodec,
+		&video.Bitrate,
+		&video.HLSURL,
 		&video.CreatedAt,
 	)
 	
@@ -100,6 +119,43 @@ func GetVideoByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// 视频地址签名后只对当前登录的学生有效，属于课程的视频还要求已选修该课程
+	stuID, err := sessionStudent(db, r)
+	if err != nil {
+		log.Printf("校验登录会话失败: %v", err)
+		sendError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	if stuID == "" {
+		sendError(w, http.StatusUnauthorized, 401, "请先登录")
+		return
+	}
+	courseID, allowed, err := videoCourseForStudent(r.Context(), db, videoID, stuID)
+	if err != nil {
+		log.Printf("检查视频访问权限失败: %v", err)
+		sendError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	if !allowed {
+		sendError(w, http.StatusForbidden, 403, "请先选修该课程")
+		return
+	}
+	video.URL = signMediaURL(video.URL, stuID, courseID)
+	if video.HLSURL != "" {
+		video.HLSURL = signMediaURL(video.HLSURL, stuID, courseID)
+	}
+	
+	// 字幕轨道
+	video.Subtitles, err = listSubtitles(r.Context(), db, videoID)
+	if err != nil {
+		log.Printf("查询字幕失败: %v", err)
+		sendError(w, http.StatusInternalServerError, 500, "服务器内部错误")
+		return
+	}
+	for i := range video.Subtitles {
+		video.Subtitles[i].URL = signMediaURL(video.Subtitles[i].URL, stuID, courseID)
+	}
+	
 	// 构建响应
 	response := VideoResponse{
 		Code: 20000,
@@ -138,6 +194,9 @@ func sendError(w http.ResponseWriter, httpStatus, code int, message string) {
 func RegisterVideoRoutes() *http.ServeMux {
 	mux := http.NewServeMux()
 	
+	// 字幕检索
+	mux.HandleFunc("GET /api/videos/search", SearchTranscripts)
+	
 	mux.HandleFunc("/api/videos/", func(w http.ResponseWriter, r *http.Request) {
 		// 记录请求信息（这里使用了fmt包）
 		fmt.Printf("收到视频API请求：%s %s\n", r.Method, r.URL.Path)
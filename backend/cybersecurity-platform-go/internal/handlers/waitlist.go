@@ -0,0 +1,196 @@
+// internal/handlers/waitlist.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"cybersecurity-platform-go/internal/database"
+	"cybersecurity-platform-go/internal/ops"
+)
+
+// WaitlistResponse 候补状态响应，data 为 null 表示不在候补名单中
+type WaitlistResponse struct {
+	Code    int                `json:"code"`
+	Message string             `json:"message,omitempty"`
+	Data    *ops.WaitlistEntry `json:"data"`
+}
+
+// NotificationsResponse 站内通知响应
+type NotificationsResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		List   []ops.Notification `json:"list"`
+		Unread int                `json:"unread"`
+	} `json:"data"`
+}
+
+// notificationsReadRequest 标记通知已读，ids 为空时全部标记
+type notificationsReadRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// sendWaitlistError 候补相关的错误
+func sendWaitlistError(w http.ResponseWriter, err error) {
+	switch err {
+	case ops.ErrCourseNotFound:
+		sendStudentError(w, http.StatusNotFound, 40400, "课程不存在")
+	case ops.ErrAlreadyEnrolled:
+		sendStudentError(w, http.StatusOK, 40001, "已加入该课程")
+	case ops.ErrCourseNotFull:
+		sendStudentError(w, http.StatusOK, 40003, err.Error())
+	case ops.ErrNotOnWaitlist:
+		sendStudentError(w, http.StatusOK, 40004, err.Error())
+	case ops.ErrWaitlistClosed:
+		sendStudentError(w, http.StatusOK, 40009, err.Error())
+	default:
+		log.Printf("处理候补失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+	}
+}
+
+// waitlistRequest 解析 courseId（GET 从查询参数，POST 从请求体），学生取自登录会话
+func waitlistRequest(w http.ResponseWriter, r *http.Request) (*sql.DB, string, int, bool) {
+	var req studentCourseRequest
+	if r.Method == http.MethodGet {
+		req.CourseID, _ = strconv.Atoi(r.URL.Query().Get("courseId"))
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数解析失败")
+		return nil, "", 0, false
+	}
+	if req.CourseID <= 0 {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数不完整")
+		return nil, "", 0, false
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return nil, "", 0, false
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	return db, stuID, req.CourseID, ok
+}
+
+// joinWaitlistHandler 加入满员课程的候补名单，返回排队位置
+func joinWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	db, stuID, courseID, ok := waitlistRequest(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := ops.JoinWaitlist(db, stuID, courseID)
+	if err != nil {
+		sendWaitlistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WaitlistResponse{Code: 20000, Message: "已加入候补", Data: entry})
+}
+
+// leaveWaitlistHandler 退出候补
+func leaveWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	db, stuID, courseID, ok := waitlistRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := ops.LeaveWaitlist(db, stuID, courseID); err != nil {
+		sendWaitlistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WaitlistResponse{Code: 20000, Message: "已退出候补"})
+}
+
+// waitlistStatusHandler 查询候补状态和排队位置
+func waitlistStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	db, stuID, courseID, ok := waitlistRequest(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := ops.WaitlistStatus(db, stuID, courseID)
+	if err != nil {
+		sendWaitlistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WaitlistResponse{Code: 20000, Data: entry})
+}
+
+// notificationsHandler 学生的站内通知，unread=1 时只返回未读通知
+func notificationsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	unreadOnly, _ := strconv.ParseBool(r.URL.Query().Get("unread"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("size"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+	list, unread, err := ops.Notifications(db, stuID, unreadOnly, limit)
+	if err != nil {
+		log.Printf("查询通知失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+
+	response := NotificationsResponse{Code: 20000}
+	response.Data.List = list
+	response.Data.Unread = unread
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// readNotificationsHandler 标记通知已读
+func readNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var req notificationsReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendStudentError(w, http.StatusBadRequest, 40000, "参数解析失败")
+		return
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		log.Printf("获取数据库连接失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	stuID, ok := studentFromSession(w, r, db)
+	if !ok {
+		return
+	}
+	if err := ops.MarkNotificationsRead(db, stuID, req.IDs); err != nil {
+		log.Printf("标记通知已读失败: %v", err)
+		sendStudentError(w, http.StatusInternalServerError, 50000, "服务器内部错误")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(StudentJoinResponse{Code: 20000, Message: "已标记为已读"})
+}
@@ -0,0 +1,78 @@
+// internal/health/checks.go
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// PingCheck 基于 Ping 函数的检查（MySQL、图数据库等）
+func PingCheck(name string, critical bool, ping func(ctx context.Context) error) Check {
+	return Check{
+		Name:     name,
+		Critical: critical,
+		Run:      ping,
+	}
+}
+
+// FuncCheck 基于无上下文函数的检查（如内存缓存读写）
+func FuncCheck(name string, critical bool, fn func() error) Check {
+	return Check{
+		Name:     name,
+		Critical: critical,
+		Run: func(ctx context.Context) error {
+			return fn()
+		},
+	}
+}
+
+// WritableDirCheck 检查目录是否存在且可写
+// 通过创建并删除一个临时文件来验证，而不是只检查权限位
+func WritableDirCheck(name, dir string) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			if dir == "" {
+				return errors.New("目录未配置")
+			}
+			info, err := os.Stat(dir)
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("%s 不是目录", dir)
+			}
+
+			f, err := os.CreateTemp(dir, ".health-*")
+			if err != nil {
+				return fmt.Errorf("目录不可写: %v", err)
+			}
+			name := f.Name()
+			_, writeErr := f.Write([]byte("ok"))
+			closeErr := f.Close()
+			removeErr := os.Remove(name)
+
+			return errors.Join(writeErr, closeErr, removeErr)
+		},
+	}
+}
+
+// DiskSpaceCheck 检查目录所在磁盘的剩余空间是否不低于 minFreeBytes
+func DiskSpaceCheck(name, dir string, minFreeBytes uint64) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			free, err := freeDiskSpace(dir)
+			if err != nil {
+				return err
+			}
+			if free < minFreeBytes {
+				return fmt.Errorf("磁盘剩余空间不足: 剩余 %d MB，要求至少 %d MB",
+					free>>20, minFreeBytes>>20)
+			}
+			return nil
+		},
+	}
+}
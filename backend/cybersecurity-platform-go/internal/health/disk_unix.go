@@ -0,0 +1,15 @@
+//go:build !windows
+
+// internal/health/disk_unix.go
+package health
+
+import "syscall"
+
+// freeDiskSpace 返回目录所在文件系统对非特权用户可用的字节数
+func freeDiskSpace(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
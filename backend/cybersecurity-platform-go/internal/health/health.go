@@ -0,0 +1,256 @@
+// internal/health/health.go
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status 检查状态
+type Status string
+
+const (
+	StatusUp       Status = "up"       // 所有依赖正常
+	StatusDegraded Status = "degraded" // 非关键依赖异常，服务仍可用
+	StatusDown     Status = "down"     // 关键依赖异常
+)
+
+// Check 单项依赖检查
+type Check struct {
+	Name     string                          // 检查名称，如 "mysql"
+	Critical bool                            // 关键依赖失败时服务视为未就绪
+	Timeout  time.Duration                   // 单项超时，0 表示使用 Checker 的默认值
+	Run      func(ctx context.Context) error // 返回nil表示正常
+}
+
+// Result 单项检查结果
+type Result struct {
+	Name       string    `json:"name"`
+	Status     Status    `json:"status"`
+	Critical   bool      `json:"critical"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// Report 汇总检查结果
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Checker 依赖检查器
+// 检查结果会缓存 ttl 时长，避免探针高频请求时反复访问数据库等依赖
+type Checker struct {
+	timeout time.Duration
+	ttl     time.Duration
+	checks  []Check
+	info    map[string]string
+
+	mu    sync.Mutex
+	cache map[string]Result
+	now   func() time.Time
+}
+
+// NewChecker 创建依赖检查器
+func NewChecker(timeout, ttl time.Duration, checks ...Check) *Checker {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Checker{
+		timeout: timeout,
+		ttl:     ttl,
+		checks:  checks,
+		info:    map[string]string{},
+		cache:   make(map[string]Result),
+		now:     time.Now,
+	}
+}
+
+// SetInfo 设置附加在响应中的服务信息（如 service、version、environment）
+func (c *Checker) SetInfo(key, value string) {
+	c.info[key] = value
+}
+
+// SetClock 替换时间来源（测试用）
+func (c *Checker) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Run 执行检查并返回汇总结果
+// criticalOnly 为 true 时只执行关键依赖检查（用于就绪探针）
+func (c *Checker) Run(ctx context.Context, criticalOnly bool) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	results := make([]Result, len(c.checks))
+	var stale []int
+
+	for i, check := range c.checks {
+		if criticalOnly && !check.Critical {
+			continue
+		}
+		if cached, ok := c.cache[check.Name]; ok && now.Sub(cached.CheckedAt) < c.ttl {
+			results[i] = cached
+			continue
+		}
+		stale = append(stale, i)
+	}
+
+	// 并发执行过期的检查，每项都有独立的超时
+	var wg sync.WaitGroup
+	for _, i := range stale {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = c.runOne(ctx, c.checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: []Result{}}
+	for i, check := range c.checks {
+		if criticalOnly && !check.Critical {
+			continue
+		}
+		result := results[i]
+		if containsIndex(stale, i) {
+			c.cache[check.Name] = result
+		}
+		if result.Status == StatusDown {
+			if check.Critical {
+				report.Status = StatusDown
+			} else if report.Status == StatusUp {
+				report.Status = StatusDegraded
+			}
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	return report
+}
+
+// Result 获取单项检查的结果（使用缓存）
+func (c *Checker) Result(ctx context.Context, name string) (Result, bool) {
+	for _, result := range c.Run(ctx, false).Checks {
+		if result.Name == name {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+// runOne 执行单项检查
+func (c *Checker) runOne(ctx context.Context, check Check) Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := c.now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Run(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-checkCtx.Done():
+		err = checkCtx.Err()
+	}
+
+	result := Result{
+		Name:       check.Name,
+		Status:     StatusUp,
+		Critical:   check.Critical,
+		DurationMs: c.now().Sub(start).Milliseconds(),
+		CheckedAt:  start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+		log.Printf("健康检查失败: %s: %v", check.Name, err)
+	}
+	return result
+}
+
+// LivenessHandler 存活探针：进程能响应即返回200，不检查任何依赖
+func (c *Checker) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status": StatusUp,
+		})
+	}
+}
+
+// ReadinessHandler 就绪探针：关键依赖异常时返回503
+// 响应中只包含各项检查的状态，不暴露错误详情
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context(), true)
+
+		checks := make(map[string]Status, len(report.Checks))
+		for _, result := range report.Checks {
+			checks[result.Name] = result.Status
+		}
+
+		c.writeJSON(w, statusCode(report), map[string]interface{}{
+			"status": report.Status,
+			"checks": checks,
+		})
+	}
+}
+
+// DetailsHandler 详细健康信息：执行所有检查并返回错误详情与耗时
+// 该接口包含内部信息，注册时应放在管理员认证之后
+func (c *Checker) DetailsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context(), false)
+		c.writeJSON(w, statusCode(report), map[string]interface{}{
+			"status": report.Status,
+			"checks": report.Checks,
+		})
+	}
+}
+
+// writeJSON 写入JSON响应，并附加服务信息
+func (c *Checker) writeJSON(w http.ResponseWriter, httpStatus int, body map[string]interface{}) {
+	for key, value := range c.info {
+		body[key] = value
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(httpStatus)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("编码健康检查响应失败: %v", err)
+	}
+}
+
+// statusCode 将汇总状态映射为HTTP状态码（降级仍视为可用）
+func statusCode(report Report) int {
+	if report.Status == StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+func containsIndex(indexes []int, i int) bool {
+	for _, idx := range indexes {
+		if idx == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+// internal/hls/boxes.go
+package hls
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// box 内存中的 MP4 box
+type box struct {
+	typ  string
+	data []byte // 不含头部的内容
+	raw  []byte // 包含头部的完整 box
+}
+
+// parseBoxes 解析内存中的连续 box
+func parseBoxes(b []byte) ([]box, error) {
+	var boxes []box
+	for len(b) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(b[:4]))
+		hdr := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(b))
+		case 1:
+			if len(b) < 16 {
+				return nil, ErrInvalidSource
+			}
+			size = binary.BigEndian.Uint64(b[8:16])
+			hdr = 16
+		}
+		if size < hdr || size > uint64(len(b)) {
+			return nil, ErrInvalidSource
+		}
+		boxes = append(boxes, box{typ: string(b[4:8]), data: b[hdr:size], raw: b[:size]})
+		b = b[size:]
+	}
+	return boxes, nil
+}
+
+// find 查找第一个指定类型的 box
+func find(boxes []box, typ string) *box {
+	for i := range boxes {
+		if boxes[i].typ == typ {
+			return &boxes[i]
+		}
+	}
+	return nil
+}
+
+// findPath 按路径查找嵌套的 box，例如 findPath(trak, "mdia", "minf", "stbl")
+func findPath(b *box, path ...string) *box {
+	for _, typ := range path {
+		children, err := parseBoxes(b.data)
+		if err != nil {
+			return nil
+		}
+		if b = find(children, typ); b == nil {
+			return nil
+		}
+	}
+	return b
+}
+
+// topLevel 顶层 box 的位置
+type topLevel struct {
+	typ          string
+	offset, size int64
+	header       int64
+}
+
+// scanTopLevel 遍历文件的顶层 box（不读取内容，mdat 可能有数 GB）
+func scanTopLevel(r io.ReaderAt, size int64) ([]topLevel, error) {
+	var boxes []topLevel
+	var hdr [16]byte
+	for off := int64(0); size-off >= 8; {
+		if _, err := r.ReadAt(hdr[:8], off); err != nil {
+			return nil, err
+		}
+		b := topLevel{typ: string(hdr[4:8]), offset: off, size: int64(binary.BigEndian.Uint32(hdr[:4])), header: 8}
+		switch b.size {
+		case 0:
+			b.size = size - off
+		case 1:
+			if _, err := r.ReadAt(hdr[8:16], off+8); err != nil {
+				return nil, err
+			}
+			b.size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+			b.header = 16
+		}
+		if b.size < b.header || b.size > size-off {
+			return nil, ErrInvalidSource
+		}
+		boxes = append(boxes, b)
+		off += b.size
+	}
+	return boxes, nil
+}
+
+// mkbox 构造 box
+func mkbox(typ string, parts ...[]byte) []byte {
+	n := 8
+	for _, p := range parts {
+		n += len(p)
+	}
+	b := make([]byte, 8, n)
+	binary.BigEndian.PutUint32(b, uint32(n))
+	copy(b[4:], typ)
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// fullbox 构造带 version 和 flags 的 box
+func fullbox(typ string, version byte, flags uint32, parts ...[]byte) []byte {
+	vf := u32(flags)
+	vf[0] = version
+	return mkbox(typ, append([][]byte{vf}, parts...)...)
+}
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+func u64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,158 @@
+// internal/hls/hls.go
+package hls
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultSegmentDuration 默认分段时长
+const DefaultSegmentDuration = 6 * time.Second
+
+// MasterPlaylist 主播放列表文件名，各清晰度的播放列表位于 <清晰度>/index.m3u8
+const MasterPlaylist = "master.m3u8"
+
+func init() {
+	// 静态文件服务和对象存储按扩展名设置 Content-Type
+	mime.AddExtensionType(".m3u8", "application/vnd.apple.mpegurl")
+	mime.AddExtensionType(".m4s", "video/iso.segment")
+}
+
+// Rendition 转码的清晰度
+type Rendition struct {
+	Name         string // 子目录名，例如 720p
+	Height       int
+	VideoBitrate int // kbit/s
+}
+
+// DefaultRenditions 默认的清晰度阶梯（只使用不高于源视频的清晰度）
+var DefaultRenditions = []Rendition{
+	{Name: "1080p", Height: 1080, VideoBitrate: 5000},
+	{Name: "720p", Height: 720, VideoBitrate: 2800},
+	{Name: "480p", Height: 480, VideoBitrate: 1400},
+	{Name: "360p", Height: 360, VideoBitrate: 800},
+}
+
+// Options 打包选项
+type Options struct {
+	SegmentDuration time.Duration
+	// Transcoder ffmpeg 兼容的转码程序路径，配置后生成多个清晰度；为空时只重新封装源视频
+	Transcoder string
+	Renditions []Rendition // 为空时使用 DefaultRenditions
+}
+
+// Variant 主播放列表中的一个码流
+type Variant struct {
+	Name             string
+	Width, Height    int
+	Bandwidth        int64 // 峰值码率（bit/s）
+	AverageBandwidth int64
+}
+
+// Package 将 MP4 视频打包为 HLS，在 outDir 中生成 master.m3u8 和各清晰度的子目录
+func Package(ctx context.Context, src, outDir string, opts Options) ([]Variant, error) {
+	if opts.SegmentDuration <= 0 {
+		opts.SegmentDuration = DefaultSegmentDuration
+	}
+
+	var variants []Variant
+	if opts.Transcoder != "" {
+		var err error
+		if variants, err = transcode(ctx, src, outDir, opts); err != nil {
+			return nil, err
+		}
+	} else {
+		f, err := os.Open(src)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		st, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		v, err := Remux(f, st.Size(), filepath.Join(outDir, "source"), opts.SegmentDuration)
+		if err != nil {
+			return nil, err
+		}
+		variants = []Variant{*v}
+	}
+
+	if err := writeMasterPlaylist(filepath.Join(outDir, MasterPlaylist), variants); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// writeMediaPlaylist 写入点播媒体播放列表
+func writeMediaPlaylist(name string, segments []segment) error {
+	target := 1.0
+	for _, s := range segments {
+		target = math.Max(target, s.duration)
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:7\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(target)))
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+	b.WriteString("#EXT-X-MAP:URI=\"init.mp4\"\n")
+	for i, s := range segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\nseg_%05d.m4s\n", s.duration, i)
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return os.WriteFile(name, []byte(b.String()), 0644)
+}
+
+// writeMasterPlaylist 写入主播放列表
+func writeMasterPlaylist(name string, variants []Variant) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	w.WriteString("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+	for _, v := range variants {
+		fmt.Fprintf(w, "#EXT-X-STREAM-INF:BANDWIDTH=%d", v.Bandwidth)
+		if v.AverageBandwidth > 0 {
+			fmt.Fprintf(w, ",AVERAGE-BANDWIDTH=%d", v.AverageBandwidth)
+		}
+		if v.Width > 0 && v.Height > 0 {
+			fmt.Fprintf(w, ",RESOLUTION=%dx%d", v.Width, v.Height)
+		}
+		fmt.Fprintf(w, "\n%s/index.m3u8\n", v.Name)
+	}
+	err = w.Flush()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// bandwidth 根据分段大小计算峰值码率和平均码率
+func bandwidth(segments []segment) (peak, average int64) {
+	var bytes int64
+	var duration float64
+	for _, s := range segments {
+		bytes += s.bytes
+		duration += s.duration
+		if s.duration > 0 {
+			if b := int64(float64(s.bytes*8) / s.duration); b > peak {
+				peak = b
+			}
+		}
+	}
+	if duration > 0 {
+		average = int64(float64(bytes*8) / duration)
+	}
+	if peak == 0 {
+		peak = average
+	}
+	return peak, average
+}
@@ -0,0 +1,45 @@
+// internal/hls/queue.go
+package hls
+
+import (
+	"context"
+	"log"
+)
+
+// Queue 后台打包队列
+// 打包是 CPU 和磁盘密集型任务，同一时间只处理一个视频
+type Queue struct {
+	jobs chan int64
+	run  func(ctx context.Context, videoID int64) error
+}
+
+// NewQueue 创建打包队列，size 为最多等待的任务数
+func NewQueue(size int, run func(ctx context.Context, videoID int64) error) *Queue {
+	return &Queue{jobs: make(chan int64, size), run: run}
+}
+
+// Start 启动后台任务，ctx 取消后停止
+func (q *Queue) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case id := <-q.jobs:
+				if err := q.run(ctx, id); err != nil {
+					log.Printf("视频 %d 打包HLS失败: %v", id, err)
+				}
+			}
+		}
+	}()
+}
+
+// Enqueue 添加任务，队列已满时返回 false（可以稍后用命令行补充打包）
+func (q *Queue) Enqueue(videoID int64) bool {
+	select {
+	case q.jobs <- videoID:
+		return true
+	default:
+		return false
+	}
+}
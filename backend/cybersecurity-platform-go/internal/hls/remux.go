@@ -0,0 +1,549 @@
+// internal/hls/remux.go
+package hls
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// ErrUnsupported 源文件无法直接重新封装（非 MP4、已分片的 MP4 等），需要配置外部转码程序
+	ErrUnsupported = errors.New("该视频格式不支持直接封装为HLS")
+	// ErrInvalidSource 源文件结构错误
+	ErrInvalidSource = errors.New("视频文件结构错误")
+)
+
+// moov 的最大读取大小（样本表随时长增长，数小时的视频通常只有几十MB）
+const maxMoovSize = 256 << 20
+
+// 分片中的样本标志（ISO/IEC 14496-12 8.8.3.1）
+const (
+	syncSampleFlags    = 0x02000000 // sample_depends_on=2：不依赖其他帧（关键帧）
+	nonSyncSampleFlags = 0x01010000 // sample_depends_on=1，sample_is_non_sync_sample=1
+)
+
+// sample 样本在源文件中的位置和时间
+type sample struct {
+	offset   int64
+	size     uint32
+	dts      uint64
+	duration uint32
+	cto      int32 // 显示时间与解码时间的差
+	sync     bool
+}
+
+// track 轨道
+type track struct {
+	id        uint32
+	handler   string // vide 或 soun
+	timescale uint32
+	width     int
+	height    int
+	trak      []byte // 原始 trak box，用于生成初始化分段
+	samples   []sample
+}
+
+// end 轨道结束时间（秒）
+func (t *track) end() float64 {
+	if len(t.samples) == 0 {
+		return 0
+	}
+	last := t.samples[len(t.samples)-1]
+	return float64(last.dts+uint64(last.duration)) / float64(t.timescale)
+}
+
+// segment 一个媒体分段
+type segment struct {
+	start, duration float64
+	bytes           int64
+}
+
+// Remux 将普通 MP4 重新封装为 fMP4 分段的 HLS 播放列表（不转码）
+// 在 outDir 中生成 init.mp4、seg_00000.m4s... 和 index.m3u8，分段在关键帧处切分，时长约为 segmentDuration
+func Remux(src io.ReaderAt, size int64, outDir string, segmentDuration time.Duration) (*Variant, error) {
+	if segmentDuration <= 0 {
+		segmentDuration = DefaultSegmentDuration
+	}
+
+	movie, tracks, err := readMovie(src, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var video *track
+	for _, t := range tracks {
+		if t.handler == "vide" {
+			video = t
+			break
+		}
+	}
+	if video == nil {
+		return nil, ErrUnsupported
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "init.mp4"), initSegment(movie, tracks), 0644); err != nil {
+		return nil, err
+	}
+
+	// 按视频轨道的关键帧确定分段边界
+	target := segmentDuration.Seconds()
+	cuts := []float64{0}
+	for i, s := range video.samples {
+		t := float64(s.dts) / float64(video.timescale)
+		if i > 0 && s.sync && t >= cuts[len(cuts)-1]+target-0.001 {
+			cuts = append(cuts, t)
+		}
+	}
+	end := 0.0
+	for _, t := range tracks {
+		end = math.Max(end, t.end())
+	}
+
+	next := make([]int, len(tracks)) // 每个轨道下一个未写入的样本
+	segments := make([]segment, 0, len(cuts))
+	for k, start := range cuts {
+		limit := math.Inf(1)
+		segEnd := end
+		if k+1 < len(cuts) {
+			limit, segEnd = cuts[k+1], cuts[k+1]
+		}
+
+		var parts []fragmentPart
+		for i, t := range tracks {
+			first := next[i]
+			for next[i] < len(t.samples) && float64(t.samples[next[i]].dts)/float64(t.timescale) < limit {
+				next[i]++
+			}
+			if next[i] > first {
+				parts = append(parts, fragmentPart{track: t, samples: t.samples[first:next[i]]})
+			}
+		}
+
+		name := filepath.Join(outDir, fmt.Sprintf("seg_%05d.m4s", k))
+		n, err := writeFragmentFile(name, src, uint32(k+1), parts)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment{start: start, duration: segEnd - start, bytes: n})
+	}
+
+	variant := &Variant{Name: filepath.Base(outDir), Width: video.width, Height: video.height}
+	variant.Bandwidth, variant.AverageBandwidth = bandwidth(segments)
+	if err := writeMediaPlaylist(filepath.Join(outDir, "index.m3u8"), segments); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// readMovie 读取 moov 并解析视频和音频轨道的样本表
+func readMovie(src io.ReaderAt, size int64) (*box, []*track, error) {
+	top, err := scanTopLevel(src, size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var moovLoc *topLevel
+	for i := range top {
+		switch top[i].typ {
+		case "moov":
+			moovLoc = &top[i]
+		case "moof":
+			// 已经是分片 MP4，样本表不在 moov 中
+			return nil, nil, ErrUnsupported
+		}
+	}
+	if moovLoc == nil {
+		return nil, nil, ErrUnsupported
+	}
+	if moovLoc.size > maxMoovSize {
+		return nil, nil, ErrInvalidSource
+	}
+
+	raw := make([]byte, moovLoc.size)
+	if _, err := src.ReadAt(raw, moovLoc.offset); err != nil {
+		return nil, nil, err
+	}
+	moov := &box{typ: "moov", data: raw[moovLoc.header:], raw: raw}
+
+	children, err := parseBoxes(moov.data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tracks []*track
+	for i := range children {
+		if children[i].typ != "trak" {
+			continue
+		}
+		t, err := readTrack(&children[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if t != nil {
+			tracks = append(tracks, t)
+		}
+	}
+	return moov, tracks, nil
+}
+
+// readTrack 解析轨道的样本表，不是视频或音频的轨道（字幕、时间码等）返回 nil
+func readTrack(trak *box) (*track, error) {
+	t := &track{trak: trak.raw}
+
+	hdlr := findPath(trak, "mdia", "hdlr")
+	if hdlr == nil || len(hdlr.data) < 12 {
+		return nil, ErrInvalidSource
+	}
+	t.handler = string(hdlr.data[8:12])
+	if t.handler != "vide" && t.handler != "soun" {
+		return nil, nil
+	}
+
+	tkhd := findPath(trak, "tkhd")
+	if tkhd == nil || len(tkhd.data) < 84 {
+		return nil, ErrInvalidSource
+	}
+	wh := 76
+	if tkhd.data[0] == 1 {
+		if len(tkhd.data) < 96 {
+			return nil, ErrInvalidSource
+		}
+		t.id = binary.BigEndian.Uint32(tkhd.data[20:24])
+		wh = 88
+	} else {
+		t.id = binary.BigEndian.Uint32(tkhd.data[12:16])
+	}
+	t.width = int(binary.BigEndian.Uint32(tkhd.data[wh:]) >> 16)
+	t.height = int(binary.BigEndian.Uint32(tkhd.data[wh+4:]) >> 16)
+
+	mdhd := findPath(trak, "mdia", "mdhd")
+	if mdhd == nil || len(mdhd.data) < 24 {
+		return nil, ErrInvalidSource
+	}
+	if mdhd.data[0] == 1 {
+		t.timescale = binary.BigEndian.Uint32(mdhd.data[20:24])
+	} else {
+		t.timescale = binary.BigEndian.Uint32(mdhd.data[12:16])
+	}
+	if t.timescale == 0 {
+		return nil, ErrInvalidSource
+	}
+
+	stbl := findPath(trak, "mdia", "minf", "stbl")
+	if stbl == nil {
+		return nil, ErrInvalidSource
+	}
+	tables, err := parseBoxes(stbl.data)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := readSampleTables(tables)
+	if err != nil {
+		return nil, err
+	}
+	t.samples = samples
+	return t, nil
+}
+
+// readSampleTables 根据 stts/ctts/stsz/stsc/stco/stss 计算每个样本的位置和时间
+func readSampleTables(tables []box) ([]sample, error) {
+	stsz := find(tables, "stsz")
+	stts := find(tables, "stts")
+	stsc := find(tables, "stsc")
+	if stsz == nil || stts == nil || stsc == nil {
+		return nil, ErrUnsupported
+	}
+
+	// 样本大小
+	if len(stsz.data) < 12 {
+		return nil, ErrInvalidSource
+	}
+	uniform := binary.BigEndian.Uint32(stsz.data[4:8])
+	count := int(binary.BigEndian.Uint32(stsz.data[8:12]))
+	if uniform == 0 && len(stsz.data) < 12+4*count {
+		return nil, ErrInvalidSource
+	}
+	if count == 0 {
+		return nil, ErrUnsupported
+	}
+	samples := make([]sample, count)
+	for i := range samples {
+		if uniform != 0 {
+			samples[i].size = uniform
+		} else {
+			samples[i].size = binary.BigEndian.Uint32(stsz.data[12+4*i:])
+		}
+		samples[i].sync = true
+	}
+
+	// 解码时间
+	entries, err := tableEntries(stts, 8)
+	if err != nil {
+		return nil, err
+	}
+	var dts uint64
+	i := 0
+	for _, e := range entries {
+		n, delta := binary.BigEndian.Uint32(e), binary.BigEndian.Uint32(e[4:])
+		for j := uint32(0); j < n && i < count; j++ {
+			samples[i].dts, samples[i].duration = dts, delta
+			dts += uint64(delta)
+			i++
+		}
+	}
+	if i != count {
+		return nil, ErrInvalidSource
+	}
+
+	// 显示时间偏移（B帧）
+	if ctts := find(tables, "ctts"); ctts != nil {
+		entries, err := tableEntries(ctts, 8)
+		if err != nil {
+			return nil, err
+		}
+		i := 0
+		for _, e := range entries {
+			n, offset := binary.BigEndian.Uint32(e), int32(binary.BigEndian.Uint32(e[4:]))
+			for j := uint32(0); j < n && i < count; j++ {
+				samples[i].cto = offset
+				i++
+			}
+		}
+	}
+
+	// 关键帧，没有 stss 时所有样本都是关键帧
+	if stss := find(tables, "stss"); stss != nil {
+		entries, err := tableEntries(stss, 4)
+		if err != nil {
+			return nil, err
+		}
+		for i := range samples {
+			samples[i].sync = false
+		}
+		for _, e := range entries {
+			if n := int(binary.BigEndian.Uint32(e)); n >= 1 && n <= count {
+				samples[n-1].sync = true
+			}
+		}
+	}
+
+	// 块偏移
+	var chunks []int64
+	if stco := find(tables, "stco"); stco != nil {
+		entries, err := tableEntries(stco, 4)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			chunks = append(chunks, int64(binary.BigEndian.Uint32(e)))
+		}
+	} else if co64 := find(tables, "co64"); co64 != nil {
+		entries, err := tableEntries(co64, 8)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			chunks = append(chunks, int64(binary.BigEndian.Uint64(e)))
+		}
+	} else {
+		return nil, ErrUnsupported
+	}
+
+	// 样本到块的映射
+	entries, err = tableEntries(stsc, 12)
+	if err != nil {
+		return nil, err
+	}
+	i = 0
+	for k, e := range entries {
+		first := int(binary.BigEndian.Uint32(e))
+		perChunk := int(binary.BigEndian.Uint32(e[4:]))
+		last := len(chunks)
+		if k+1 < len(entries) {
+			last = int(binary.BigEndian.Uint32(entries[k+1])) - 1
+		}
+		if first < 1 || last > len(chunks) {
+			return nil, ErrInvalidSource
+		}
+		for c := first; c <= last; c++ {
+			offset := chunks[c-1]
+			for j := 0; j < perChunk && i < count; j++ {
+				samples[i].offset = offset
+				offset += int64(samples[i].size)
+				i++
+			}
+		}
+	}
+	if i != count {
+		return nil, ErrInvalidSource
+	}
+	return samples, nil
+}
+
+// tableEntries 拆分样本表的条目（version/flags 和 entry_count 之后的定长记录）
+func tableEntries(b *box, size int) ([][]byte, error) {
+	if len(b.data) < 8 {
+		return nil, ErrInvalidSource
+	}
+	n := int(binary.BigEndian.Uint32(b.data[4:8]))
+	if n < 0 || len(b.data)-8 < n*size {
+		return nil, ErrInvalidSource
+	}
+	entries := make([][]byte, n)
+	for i := range entries {
+		entries[i] = b.data[8+i*size : 8+(i+1)*size]
+	}
+	return entries, nil
+}
+
+// initSegment 生成初始化分段：轨道的编码参数不变，样本表清空，并声明使用分片（mvex）
+func initSegment(moov *box, tracks []*track) []byte {
+	children, _ := parseBoxes(moov.data)
+
+	var parts [][]byte
+	if mvhd := find(children, "mvhd"); mvhd != nil {
+		parts = append(parts, mvhd.raw)
+	}
+	var trex [][]byte
+	for _, t := range tracks {
+		parts = append(parts, emptyTrak(t.trak))
+		trex = append(trex, fullbox("trex", 0, 0, u32(t.id), u32(1), u32(0), u32(0), u32(0)))
+	}
+	parts = append(parts, mkbox("mvex", trex...))
+
+	return append(
+		mkbox("ftyp", []byte("iso6"), u32(1), []byte("iso6"), []byte("mp41")),
+		mkbox("moov", parts...)...,
+	)
+}
+
+// emptyTrak 复制 trak，只保留 stbl 中的 stsd
+func emptyTrak(raw []byte) []byte {
+	var rebuild func(typ string, data []byte) []byte
+	rebuild = func(typ string, data []byte) []byte {
+		children, err := parseBoxes(data)
+		if err != nil {
+			return mkbox(typ, data)
+		}
+		var parts [][]byte
+		for _, c := range children {
+			switch {
+			case typ == "stbl" && c.typ == "stsd":
+				parts = append(parts, c.raw)
+			case typ == "stbl":
+				// 其余样本表在后面统一写入空表
+			case c.typ == "mdia" || c.typ == "minf" || c.typ == "stbl":
+				parts = append(parts, rebuild(c.typ, c.data))
+			default:
+				parts = append(parts, c.raw)
+			}
+		}
+		if typ == "stbl" {
+			parts = append(parts,
+				fullbox("stts", 0, 0, u32(0)),
+				fullbox("stsc", 0, 0, u32(0)),
+				fullbox("stsz", 0, 0, u32(0), u32(0)),
+				fullbox("stco", 0, 0, u32(0)),
+			)
+		}
+		return mkbox(typ, parts...)
+	}
+
+	boxes, err := parseBoxes(raw)
+	if err != nil || len(boxes) != 1 {
+		return raw
+	}
+	return rebuild("trak", boxes[0].data)
+}
+
+// fragmentPart 分段中一个轨道的样本
+type fragmentPart struct {
+	track   *track
+	samples []sample
+}
+
+// writeFragmentFile 写入一个 moof+mdat 分段，返回文件大小
+func writeFragmentFile(name string, src io.ReaderAt, seq uint32, parts []fragmentPart) (int64, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriterSize(f, 1<<20)
+
+	n, err := writeFragment(w, src, seq, parts)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+// writeFragment 写入 moof 和 mdat，mdat 中的样本数据直接从源文件复制
+func writeFragment(w io.Writer, src io.ReaderAt, seq uint32, parts []fragmentPart) (int64, error) {
+	var dataSize int64
+	for _, p := range parts {
+		for _, s := range p.samples {
+			dataSize += int64(s.size)
+		}
+	}
+
+	// trun 中的 data_offset 相对于 moof 开头，moof 的大小与偏移量的值无关，先用0计算大小
+	moof := buildMoof(seq, parts, 0)
+	moof = buildMoof(seq, parts, len(moof)+8)
+
+	if _, err := w.Write(moof); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(append(u32(uint32(8+dataSize)), "mdat"...)); err != nil {
+		return 0, err
+	}
+	for _, p := range parts {
+		for _, s := range p.samples {
+			if _, err := io.Copy(w, io.NewSectionReader(src, s.offset, int64(s.size))); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return int64(len(moof)) + 8 + dataSize, nil
+}
+
+// buildMoof 构造 moof，dataStart 为 mdat 数据相对 moof 开头的偏移量
+func buildMoof(seq uint32, parts []fragmentPart, dataStart int) []byte {
+	trafs := [][]byte{fullbox("mfhd", 0, 0, u32(seq))}
+	offset := dataStart
+	for _, p := range parts {
+		// trun：data_offset、sample_duration、sample_size、sample_flags、sample_composition_time_offset（有符号）
+		run := make([]byte, 0, 8+16*len(p.samples))
+		run = append(run, u32(uint32(len(p.samples)))...)
+		run = append(run, u32(uint32(offset))...)
+		for _, s := range p.samples {
+			flags := uint32(nonSyncSampleFlags)
+			if s.sync {
+				flags = syncSampleFlags
+			}
+			run = append(run, u32(s.duration)...)
+			run = append(run, u32(s.size)...)
+			run = append(run, u32(flags)...)
+			run = append(run, u32(uint32(s.cto))...)
+			offset += int(s.size)
+		}
+
+		trafs = append(trafs, mkbox("traf",
+			fullbox("tfhd", 0, 0x020000, u32(p.track.id)), // default-base-is-moof
+			fullbox("tfdt", 1, 0, u64(p.samples[0].dts)),
+			fullbox("trun", 1, 0x000F01, run),
+		))
+	}
+	return mkbox("moof", trafs...)
+}
@@ -0,0 +1,102 @@
+// internal/hls/transcode.go
+package hls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"cybersecurity-platform-go/internal/mediainfo"
+)
+
+// 转码后的音频码率（kbit/s）
+const audioBitrate = 128
+
+// transcode 调用外部转码程序（ffmpeg 兼容的参数）生成多个清晰度的 fMP4 分段
+func transcode(ctx context.Context, src, outDir string, opts Options) ([]Variant, error) {
+	info, err := mediainfo.ProbeFile(src)
+	if err != nil {
+		return nil, err
+	}
+
+	renditions := selectRenditions(opts.Renditions, info.Height)
+	seconds := fmt.Sprintf("%g", opts.SegmentDuration.Seconds())
+
+	var variants []Variant
+	for _, r := range renditions {
+		dir := filepath.Join(outDir, r.Name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+
+		args := []string{
+			"-hide_banner", "-loglevel", "error", "-y",
+			"-i", src,
+			"-map", "0:v:0", "-map", "0:a:0?",
+			"-vf", fmt.Sprintf("scale=-2:%d", r.Height),
+			"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "high",
+			"-b:v", fmt.Sprintf("%dk", r.VideoBitrate),
+			"-maxrate", fmt.Sprintf("%dk", r.VideoBitrate*107/100),
+			"-bufsize", fmt.Sprintf("%dk", r.VideoBitrate*3/2),
+			// 所有清晰度在相同时间点插入关键帧，切换清晰度时分段对齐
+			"-force_key_frames", "expr:gte(t,n_forced*" + seconds + ")", "-sc_threshold", "0",
+			"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", audioBitrate), "-ac", "2",
+			"-f", "hls", "-hls_time", seconds, "-hls_playlist_type", "vod",
+			"-hls_segment_type", "fmp4", "-hls_fmp4_init_filename", "init.mp4",
+			"-hls_segment_filename", filepath.Join(dir, "seg_%05d.m4s"),
+			filepath.Join(dir, "index.m3u8"),
+		}
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, opts.Transcoder, args...)
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("转码 %s 失败: %v: %s", r.Name, err, lastLine(stderr.String()))
+		}
+
+		width := 0
+		if info.Height > 0 {
+			width = (info.Width*r.Height/info.Height + 1) &^ 1
+		}
+		bandwidth := int64(r.VideoBitrate+audioBitrate) * 1000
+		variants = append(variants, Variant{
+			Name:             r.Name,
+			Width:            width,
+			Height:           r.Height,
+			Bandwidth:        bandwidth * 107 / 100,
+			AverageBandwidth: bandwidth,
+		})
+	}
+	return variants, nil
+}
+
+// selectRenditions 选择不高于源视频的清晰度，源视频低于所有清晰度时按源分辨率输出最低一档
+func selectRenditions(ladder []Rendition, sourceHeight int) []Rendition {
+	if len(ladder) == 0 {
+		ladder = DefaultRenditions
+	}
+	var selected []Rendition
+	for _, r := range ladder {
+		if sourceHeight == 0 || r.Height <= sourceHeight {
+			selected = append(selected, r)
+		}
+	}
+	if len(selected) == 0 {
+		lowest := ladder[len(ladder)-1]
+		lowest.Height = sourceHeight &^ 1
+		selected = []Rendition{lowest}
+	}
+	return selected
+}
+
+// lastLine 转码程序错误输出的最后一行
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,219 @@
+// internal/hotsearch/hotsearch.go
+package hotsearch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxTermLen 记录的搜索词最大长度（字符），更长的部分截断
+const MaxTermLen = 50
+
+// stopList 不进入热门搜索的常见词
+var stopList = map[string]bool{
+	"课程": true, "教程": true, "视频": true, "学习": true, "资料": true,
+	"下载": true, "入门": true, "test": true, "测试一下": true, "asdf": true,
+}
+
+// Normalize 规范化搜索词：全角转半角（NFKC）、转小写、合并空白并截断
+// 不包含字母或汉字的搜索词（纯数字、标点）返回空字符串
+func Normalize(q string) string {
+	q = strings.ToLower(norm.NFKC.String(q))
+	q = strings.Join(strings.Fields(q), " ")
+	if utf8.RuneCountInString(q) > MaxTermLen {
+		q = strings.TrimSpace(string([]rune(q)[:MaxTermLen]))
+	}
+	for _, r := range q {
+		if unicode.IsLetter(r) {
+			return q
+		}
+	}
+	return ""
+}
+
+// Stopped 搜索词是否在停用词表中或过短，这些词只记录、不参与热门排行
+func Stopped(term string) bool {
+	return utf8.RuneCountInString(term) < 2 || stopList[term]
+}
+
+// SearcherKey 搜索者标识（学号或IP）的摘要，用于按人去重，日志中不保存原始值
+func SearcherKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Stats 滑动窗口内搜索某个词的人数
+type Stats struct {
+	Term   string
+	Recent int // 最近窗口（默认24小时）
+	Window int // 完整窗口（默认7天）
+}
+
+// Term 热门搜索词
+type Term struct {
+	Term   string  `json:"term"`
+	Score  float64 `json:"score"`
+	Pinned bool    `json:"pinned"`
+}
+
+// Options 热门搜索计算参数
+type Options struct {
+	Recent       time.Duration // 最近窗口，人数按 RecentWeight 加权，使新近升温的词排在前面
+	Window       time.Duration // 完整窗口
+	RecentWeight float64
+	MinSearchers int // 完整窗口内至少有多少人搜索过
+	Limit        int
+}
+
+// DefaultOptions 默认参数：最近24小时加权3倍，统计7天，至少2人搜索，取前10个
+func DefaultOptions() Options {
+	return Options{
+		Recent:       24 * time.Hour,
+		Window:       7 * 24 * time.Hour,
+		RecentWeight: 3,
+		MinSearchers: 2,
+		Limit:        10,
+	}
+}
+
+// Rank 计算热门搜索列表：置顶词按给定顺序排在最前，其余按得分排序
+// 停用词和屏蔽词不会出现在结果中（置顶优先于停用词表，但不优先于屏蔽）
+func Rank(stats []Stats, pinned []string, blocked map[string]bool, opts Options) []Term {
+	terms := []Term{}
+	seen := make(map[string]bool)
+	for _, p := range pinned {
+		if len(terms) >= opts.Limit {
+			return terms
+		}
+		if blocked[p] || seen[p] {
+			continue
+		}
+		seen[p] = true
+		terms = append(terms, Term{Term: p, Pinned: true})
+	}
+
+	ranked := []Term{}
+	for _, s := range stats {
+		if s.Window < opts.MinSearchers || seen[s.Term] || blocked[s.Term] || Stopped(s.Term) {
+			continue
+		}
+		ranked = append(ranked, Term{Term: s.Term, Score: float64(s.Recent)*opts.RecentWeight + float64(s.Window)})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Term < ranked[j].Term
+	})
+
+	for _, t := range ranked {
+		if len(terms) >= opts.Limit {
+			break
+		}
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+// Board 当前的热门搜索列表，由后台任务定期更新
+type Board struct {
+	mu      sync.RWMutex
+	opts    Options
+	terms   []Term
+	updated time.Time
+}
+
+// NewBoard 创建热门搜索列表
+func NewBoard(opts Options) *Board {
+	return &Board{opts: opts, terms: []Term{}}
+}
+
+// Options 计算参数
+func (b *Board) Options() Options {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.opts
+}
+
+// Set 替换热门搜索列表
+func (b *Board) Set(terms []Term, at time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.terms = terms
+	b.updated = at
+}
+
+// Terms 热门搜索词及得分，以及最近一次计算的时间
+func (b *Board) Terms() ([]Term, time.Time) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Term(nil), b.terms...), b.updated
+}
+
+// List 热门搜索词
+func (b *Board) List() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	list := make([]string, len(b.terms))
+	for i, t := range b.terms {
+		list[i] = t.Term
+	}
+	return list
+}
+
+var (
+	defaultMu    sync.RWMutex
+	defaultBoard *Board
+)
+
+// SetDefault 设置全局热门搜索列表（服务启动时根据配置调用）
+func SetDefault(b *Board) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultBoard = b
+}
+
+// Default 获取全局热门搜索列表，未设置时使用默认参数创建
+func Default() *Board {
+	defaultMu.RLock()
+	b := defaultBoard
+	defaultMu.RUnlock()
+	if b != nil {
+		return b
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultBoard == nil {
+		defaultBoard = NewBoard(DefaultOptions())
+	}
+	return defaultBoard
+}
+
+// Run 每隔 interval 调用一次 refresh（启动时立即执行一次），ctx 取消后停止
+func Run(ctx context.Context, interval time.Duration, refresh func(ctx context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := refresh(ctx); err != nil {
+				log.Printf("计算热门搜索失败: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
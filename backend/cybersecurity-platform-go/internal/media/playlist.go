@@ -0,0 +1,102 @@
+// internal/media/playlist.go
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// 播放列表的最大大小
+const maxPlaylistSize = 4 << 20
+
+// 标签属性中的地址，例如 #EXT-X-MAP:URI="init.mp4"
+var playlistURIAttr = regexp.MustCompile(`URI="([^"]*)"`)
+
+// PlaylistOpener 读取播放列表，name 为去掉路由前缀后的路径；文件不存在时返回 fs.ErrNotExist
+type PlaylistOpener func(ctx context.Context, name string) (io.ReadCloser, error)
+
+// SignPlaylists HLS 播放列表签名处理器，放在 Protect 之后使用
+// .m3u8 文件由 open 读取，其中的子播放列表、初始化分段和媒体分段地址使用当前请求的授权重新签名，
+// 播放器按播放列表请求的每个文件都能通过 Protect 校验；其他请求交给 next
+func SignPlaylists(s *Signer, prefix string, open PlaylistOpener, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.ToLower(path.Ext(r.URL.Path)) != ".m3u8" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		grant, ok := GrantFromContext(r.Context())
+		if !ok {
+			http.Error(w, "缺少访问授权", http.StatusForbidden)
+			return
+		}
+
+		rc, err := open(r.Context(), strings.TrimPrefix(r.URL.Path, prefix))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				http.NotFound(w, r)
+				return
+			}
+			log.Printf("读取播放列表失败: %v", err)
+			http.Error(w, "服务器内部错误", http.StatusInternalServerError)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(rc, maxPlaylistSize))
+		rc.Close()
+		if err != nil {
+			log.Printf("读取播放列表失败: %v", err)
+			http.Error(w, "服务器内部错误", http.StatusInternalServerError)
+			return
+		}
+
+		signed := s.SignPlaylist(body, r.URL.Path, grant)
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(signed)
+		}
+	})
+}
+
+// SignPlaylist 为播放列表中的相对地址签名，playlistPath 为播放列表自身的路径（用于解析相对地址）
+// 外部地址（带协议或主机名）保持不变
+func (s *Signer) SignPlaylist(playlist []byte, playlistPath string, grant Grant) []byte {
+	base := &url.URL{Path: playlistPath}
+	sign := func(ref string) string {
+		u, err := url.Parse(ref)
+		if err != nil || u.Scheme != "" || u.Host != "" {
+			return ref
+		}
+		signed, err := s.Sign(base.ResolveReference(u).String(), grant)
+		if err != nil {
+			return ref
+		}
+		return signed
+	}
+
+	lines := strings.Split(string(playlist), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"):
+			lines[i] = playlistURIAttr.ReplaceAllStringFunc(line, func(attr string) string {
+				return `URI="` + sign(playlistURIAttr.FindStringSubmatch(attr)[1]) + `"`
+			})
+		default:
+			lines[i] = sign(trimmed)
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,74 @@
+// internal/media/protect.go
+package media
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// AccessFunc 检查学生是否有权访问课程的媒体文件（courseID 为 0 时只需确认学生存在）
+type AccessFunc func(ctx context.Context, stuID string, courseID int) (bool, error)
+
+// Protect 媒体文件访问控制中间件
+// 校验签名和过期时间，再检查学生的选课状态（退课后已签发的链接立即失效），通过后交给 next 处理（支持 Range 请求）；
+// exts 非空时只保护这些扩展名的文件，其余文件（如公开的封面图片）直接放行
+func Protect(s *Signer, access AccessFunc, next http.Handler, exts ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || !protected(r.URL.Path, exts) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		grant, err := s.Verify(r.URL.Path, r.URL.Query())
+		if err != nil {
+			status := http.StatusForbidden
+			if errors.Is(err, ErrExpired) {
+				// 过期时返回401，前端可以重新获取链接
+				status = http.StatusUnauthorized
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		ok, err := access(r.Context(), grant.StuID, grant.CourseID)
+		if err != nil {
+			log.Printf("检查媒体访问权限失败: %v", err)
+			http.Error(w, "服务器内部错误", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "没有访问该课程资源的权限", http.StatusForbidden)
+			return
+		}
+
+		// 链接绑定用户，不允许共享缓存保存（静态文件处理器不会覆盖已设置的缓存策略）
+		w.Header().Set("Cache-Control", "private, no-store")
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), grantKey{}, grant)))
+	})
+}
+
+type grantKey struct{}
+
+// GrantFromContext 获取 Protect 校验通过的授权
+func GrantFromContext(ctx context.Context) (Grant, bool) {
+	g, ok := ctx.Value(grantKey{}).(Grant)
+	return g, ok
+}
+
+// protected 判断路径是否需要签名
+func protected(p string, exts []string) bool {
+	if len(exts) == 0 {
+		return true
+	}
+	ext := strings.ToLower(path.Ext(p))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
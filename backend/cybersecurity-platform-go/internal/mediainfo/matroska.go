@@ -0,0 +1,289 @@
+// internal/mediainfo/matroska.go
+package mediainfo
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Matroska/WebM 元素ID
+const (
+	idEBML          = 0x1A45DFA3
+	idDocType       = 0x4282
+	idSegment       = 0x18538067
+	idInfo          = 0x1549A966
+	idTimecodeScale = 0x2AD7B1
+	idDuration      = 0x4489
+	idTracks        = 0x1654AE6B
+	idTrackEntry    = 0xAE
+	idTrackType     = 0x83
+	idCodecID       = 0x86
+	idVideo         = 0xE0
+	idPixelWidth    = 0xB0
+	idPixelHeight   = 0xBA
+	idCluster       = 0x1F43B675
+)
+
+// unknownSize 未知大小的元素（直播流录制的文件中常见），延伸到父元素末尾
+const unknownSize = -1
+
+// Matroska 编码ID
+var matroskaCodecs = map[string]string{
+	"V_VP8": "vp8", "V_VP9": "vp9", "V_AV1": "av1",
+	"V_MPEG4/ISO/AVC": "h264", "V_MPEGH/ISO/HEVC": "hevc",
+	"A_OPUS": "opus", "A_VORBIS": "vorbis", "A_AAC": "aac", "A_MPEG/L3": "mp3",
+}
+
+// element EBML 元素
+type element struct {
+	id        uint64
+	data, end int64
+}
+
+// readVint 读取 EBML 变长整数，keepMarker 为 true 时保留长度标记位（元素ID）
+func readVint(r io.ReaderAt, off int64, keepMarker bool) (uint64, int, error) {
+	var buf [8]byte
+	if _, err := r.ReadAt(buf[:1], off); err != nil {
+		return 0, 0, err
+	}
+	n := 1
+	for mask := byte(0x80); buf[0]&mask == 0; mask >>= 1 {
+		n++
+		if mask == 1 {
+			return 0, 0, ErrInvalidContainer
+		}
+	}
+	if n > 1 {
+		if _, err := r.ReadAt(buf[1:n], off+1); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	v := uint64(buf[0])
+	if !keepMarker {
+		v &= uint64(0xFF >> n)
+	}
+	allOnes := v == uint64(0xFF>>n)
+	for i := 1; i < n; i++ {
+		v = v<<8 | uint64(buf[i])
+		allOnes = allOnes && buf[i] == 0xFF
+	}
+	if !keepMarker && allOnes {
+		return math.MaxUint64, n, nil
+	}
+	return v, n, nil
+}
+
+// readElements 遍历 [start, end) 范围内的元素，fn 返回 false 时停止
+func readElements(r io.ReaderAt, start, end int64, fn func(e element) (bool, error)) error {
+	for off := start; off < end; {
+		id, idLen, err := readVint(r, off, true)
+		if err != nil {
+			return err
+		}
+		size, sizeLen, err := readVint(r, off+int64(idLen), false)
+		if err != nil {
+			return err
+		}
+
+		e := element{id: id, data: off + int64(idLen+sizeLen)}
+		if size == math.MaxUint64 {
+			e.end = end
+		} else {
+			if size > uint64(end-e.data) {
+				return ErrInvalidContainer
+			}
+			e.end = e.data + int64(size)
+		}
+
+		more, err := fn(e)
+		if err != nil || !more {
+			return err
+		}
+		off = e.end
+	}
+	return nil
+}
+
+// readElementData 读取元素内容
+func readElementData(r io.ReaderAt, e element, max int64) ([]byte, error) {
+	if e.end-e.data > max {
+		return nil, ErrInvalidContainer
+	}
+	buf := make([]byte, e.end-e.data)
+	_, err := r.ReadAt(buf, e.data)
+	return buf, err
+}
+
+// readUint 读取无符号整数元素
+func readUint(r io.ReaderAt, e element) (uint64, error) {
+	buf, err := readElementData(r, e, 8)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, b := range buf {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+// probeMatroska 解析 WebM/Matroska 文件
+func probeMatroska(r io.ReaderAt, size int64) (*Info, error) {
+	var header, segment *element
+	err := readElements(r, 0, size, func(e element) (bool, error) {
+		switch e.id {
+		case idEBML:
+			header = &e
+		case idSegment:
+			segment = &e
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if header == nil || segment == nil {
+		return nil, ErrInvalidContainer
+	}
+
+	docType := "matroska" // EBML 头中 DocType 的默认值
+	err = readElements(r, header.data, header.end, func(e element) (bool, error) {
+		if e.id == idDocType {
+			buf, err := readElementData(r, e, 64)
+			if err != nil {
+				return false, err
+			}
+			docType = string(trimNUL(buf))
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if docType != "webm" && docType != "matroska" {
+		return nil, ErrInvalidContainer
+	}
+
+	info := &Info{Container: "webm"}
+	timecodeScale := uint64(1000000)
+	var duration float64
+	hasTracks := false
+	err = readElements(r, segment.data, segment.end, func(e element) (bool, error) {
+		switch e.id {
+		case idInfo:
+			return true, readElements(r, e.data, e.end, func(c element) (bool, error) {
+				switch c.id {
+				case idTimecodeScale:
+					v, err := readUint(r, c)
+					if err != nil {
+						return false, err
+					}
+					if v > 0 {
+						timecodeScale = v
+					}
+				case idDuration:
+					buf, err := readElementData(r, c, 8)
+					if err != nil {
+						return false, err
+					}
+					switch len(buf) {
+					case 4:
+						duration = float64(math.Float32frombits(binary.BigEndian.Uint32(buf)))
+					case 8:
+						duration = math.Float64frombits(binary.BigEndian.Uint64(buf))
+					}
+				}
+				return true, nil
+			})
+		case idTracks:
+			hasTracks = true
+			return true, readElements(r, e.data, e.end, func(c element) (bool, error) {
+				if c.id == idTrackEntry {
+					return true, readTrackEntry(r, c, info)
+				}
+				return true, nil
+			})
+		case idCluster:
+			// 元数据位于媒体数据之前；未知大小的 Cluster 无法跳过
+			return !hasTracks && e.end < segment.end, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if duration > 0 && !math.IsInf(duration, 0) {
+		info.Duration = duration * float64(timecodeScale) / 1e9
+	}
+	return info, nil
+}
+
+// readTrackEntry 解析轨道，记录第一个视频轨道和第一个音频轨道
+func readTrackEntry(r io.ReaderAt, e element, info *Info) error {
+	var trackType uint64
+	var codec string
+	var width, height int
+	err := readElements(r, e.data, e.end, func(c element) (bool, error) {
+		switch c.id {
+		case idTrackType:
+			v, err := readUint(r, c)
+			if err != nil {
+				return false, err
+			}
+			trackType = v
+		case idCodecID:
+			buf, err := readElementData(r, c, 64)
+			if err != nil {
+				return false, err
+			}
+			codec = string(trimNUL(buf))
+		case idVideo:
+			return true, readElements(r, c.data, c.end, func(v element) (bool, error) {
+				switch v.id {
+				case idPixelWidth, idPixelHeight:
+					n, err := readUint(r, v)
+					if err != nil {
+						return false, err
+					}
+					if v.id == idPixelWidth {
+						width = int(n)
+					} else {
+						height = int(n)
+					}
+				}
+				return true, nil
+			})
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if name, ok := matroskaCodecs[codec]; ok {
+		codec = name
+	}
+	switch trackType {
+	case 1:
+		if info.VideoCodec == "" {
+			info.VideoCodec, info.Width, info.Height = codec, width, height
+		}
+	case 2:
+		if info.AudioCodec == "" {
+			info.AudioCodec = codec
+		}
+	}
+	return nil
+}
+
+// trimNUL 去掉字符串元素末尾的填充字节
+func trimNUL(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
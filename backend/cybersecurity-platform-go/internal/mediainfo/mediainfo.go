@@ -0,0 +1,81 @@
+// internal/mediainfo/mediainfo.go
+package mediainfo
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+)
+
+// ErrInvalidContainer 文件不是可播放的 MP4/WebM 视频
+var ErrInvalidContainer = errors.New("不是有效的视频文件")
+
+// Info 视频元数据
+type Info struct {
+	Container  string  `json:"container"` // mp4 或 webm（Matroska）
+	Duration   float64 `json:"duration"`  // 秒，未知时为0
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	VideoCodec string  `json:"videoCodec"`
+	AudioCodec string  `json:"audioCodec,omitempty"`
+	Bitrate    int64   `json:"bitrate"` // 平均码率（bit/s），时长未知时为0
+}
+
+// Seconds 时长（秒，四舍五入），对应 videos.duration
+func (i *Info) Seconds() int {
+	return int(math.Round(i.Duration))
+}
+
+// Probe 解析视频容器
+// 只读取容器头部的元数据（MP4 的 moov、WebM 的 Info/Tracks），不解码媒体数据
+func Probe(r io.ReaderAt, size int64) (*Info, error) {
+	var magic [8]byte
+	if size < int64(len(magic)) {
+		return nil, ErrInvalidContainer
+	}
+	if _, err := r.ReadAt(magic[:], 0); err != nil {
+		return nil, err
+	}
+
+	var info *Info
+	var err error
+	switch {
+	case magic[0] == 0x1A && magic[1] == 0x45 && magic[2] == 0xDF && magic[3] == 0xA3:
+		info, err = probeMatroska(r, size)
+	case isMP4Box(string(magic[4:8])):
+		info, err = probeMP4(r, size)
+	default:
+		return nil, ErrInvalidContainer
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrInvalidContainer
+		}
+		return nil, err
+	}
+
+	// 没有视频轨道的文件无法作为课程视频播放
+	if info.VideoCodec == "" {
+		return nil, ErrInvalidContainer
+	}
+	if info.Duration > 0 {
+		info.Bitrate = int64(float64(size) * 8 / info.Duration)
+	}
+	return info, nil
+}
+
+// ProbeFile 解析视频文件
+func ProbeFile(name string) (*Info, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Probe(f, st.Size())
+}
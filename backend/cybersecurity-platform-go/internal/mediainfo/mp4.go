@@ -0,0 +1,265 @@
+// internal/mediainfo/mp4.go
+package mediainfo
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// MP4（ISO BMFF / QuickTime）文件开头可能出现的顶层 box
+var mp4TopLevel = map[string]bool{
+	"ftyp": true, "moov": true, "mdat": true, "free": true, "skip": true, "wide": true, "pnot": true,
+}
+
+func isMP4Box(typ string) bool {
+	return mp4TopLevel[typ]
+}
+
+// MP4 样本描述中的编码格式
+var mp4Codecs = map[string]string{
+	"avc1": "h264", "avc3": "h264",
+	"hvc1": "hevc", "hev1": "hevc",
+	"av01": "av1",
+	"vp08": "vp8", "vp09": "vp9",
+	"mp4v": "mpeg4",
+	"mp4a": "aac",
+	"Opus": "opus",
+	"ac-3": "ac3", "ec-3": "eac3",
+	".mp3": "mp3",
+}
+
+// box MP4 的 box 位置
+type box struct {
+	typ       string
+	data, end int64 // 数据开始和结束位置
+}
+
+// mp4Track 轨道信息
+type mp4Track struct {
+	handler   string
+	codec     string
+	width     int
+	height    int
+	timescale uint32
+	duration  uint64
+}
+
+// readBoxes 遍历 [start, end) 范围内的 box
+func readBoxes(r io.ReaderAt, start, end int64, fn func(b box) error) error {
+	var hdr [16]byte
+	for off := start; end-off >= 8; {
+		if _, err := r.ReadAt(hdr[:8], off); err != nil {
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:4]))
+		b := box{typ: string(hdr[4:8]), data: off + 8}
+		switch size {
+		case 0: // 延伸到文件末尾
+			size = end - off
+		case 1: // 64位大小
+			if _, err := r.ReadAt(hdr[8:16], off+8); err != nil {
+				return err
+			}
+			size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+			b.data = off + 16
+		}
+		if size < b.data-off || size > end-off {
+			// 大小不合法或文件被截断
+			return ErrInvalidContainer
+		}
+		b.end = off + size
+		if err := fn(b); err != nil {
+			return err
+		}
+		off = b.end
+	}
+	return nil
+}
+
+// readBoxData 读取 box 开头的 n 个字节
+func readBoxData(r io.ReaderAt, b box, n int) ([]byte, error) {
+	if b.end-b.data < int64(n) {
+		return nil, ErrInvalidContainer
+	}
+	buf := make([]byte, n)
+	_, err := r.ReadAt(buf, b.data)
+	return buf, err
+}
+
+// probeMP4 解析 MP4 文件
+func probeMP4(r io.ReaderAt, size int64) (*Info, error) {
+	var moov *box
+	hasMedia := false
+	err := readBoxes(r, 0, size, func(b box) error {
+		switch b.typ {
+		case "moov":
+			moov = &b
+		case "mdat", "moof":
+			hasMedia = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if moov == nil || !hasMedia {
+		return nil, ErrInvalidContainer
+	}
+
+	var timescale uint32
+	var duration uint64
+	var tracks []*mp4Track
+	err = readBoxes(r, moov.data, moov.end, func(b box) error {
+		switch b.typ {
+		case "mvhd":
+			var err error
+			timescale, duration, err = readMediaHeader(r, b)
+			return err
+		case "mvex":
+			// 分片 MP4 的总时长在 mehd 中
+			return readBoxes(r, b.data, b.end, func(c box) error {
+				if c.typ != "mehd" {
+					return nil
+				}
+				buf, err := readBoxData(r, c, 12)
+				if err != nil {
+					return err
+				}
+				if buf[0] == 1 {
+					duration = binary.BigEndian.Uint64(buf[4:12])
+				} else {
+					duration = uint64(binary.BigEndian.Uint32(buf[4:8]))
+				}
+				return nil
+			})
+		case "trak":
+			t, err := readTrack(r, b)
+			if err != nil {
+				return err
+			}
+			tracks = append(tracks, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{Container: "mp4"}
+	if timescale > 0 {
+		info.Duration = float64(duration) / float64(timescale)
+	}
+	for _, t := range tracks {
+		switch t.handler {
+		case "vide":
+			if info.VideoCodec != "" {
+				continue
+			}
+			info.VideoCodec = t.codec
+			info.Width, info.Height = t.width, t.height
+		case "soun":
+			if info.AudioCodec == "" {
+				info.AudioCodec = t.codec
+			}
+		default:
+			continue
+		}
+		if info.Duration == 0 && t.timescale > 0 {
+			info.Duration = float64(t.duration) / float64(t.timescale)
+		}
+	}
+	return info, nil
+}
+
+// readMediaHeader 读取 mvhd/mdhd 中的时间刻度和时长
+func readMediaHeader(r io.ReaderAt, b box) (uint32, uint64, error) {
+	buf, err := readBoxData(r, b, 20)
+	if err != nil {
+		return 0, 0, err
+	}
+	if buf[0] == 1 {
+		if buf, err = readBoxData(r, b, 32); err != nil {
+			return 0, 0, err
+		}
+		return binary.BigEndian.Uint32(buf[20:24]), binary.BigEndian.Uint64(buf[24:32]), nil
+	}
+	return binary.BigEndian.Uint32(buf[12:16]), uint64(binary.BigEndian.Uint32(buf[16:20])), nil
+}
+
+// readTrack 解析 trak
+func readTrack(r io.ReaderAt, trak box) (*mp4Track, error) {
+	t := &mp4Track{}
+	err := readBoxes(r, trak.data, trak.end, func(b box) error {
+		switch b.typ {
+		case "tkhd":
+			buf, err := readBoxData(r, b, 84)
+			if err != nil {
+				return err
+			}
+			pos := 76
+			if buf[0] == 1 {
+				if buf, err = readBoxData(r, b, 96); err != nil {
+					return err
+				}
+				pos = 88
+			}
+			// 16.16 定点数
+			t.width = int(binary.BigEndian.Uint32(buf[pos:pos+4]) >> 16)
+			t.height = int(binary.BigEndian.Uint32(buf[pos+4:pos+8]) >> 16)
+		case "mdia":
+			return readBoxes(r, b.data, b.end, func(c box) error {
+				switch c.typ {
+				case "mdhd":
+					var err error
+					t.timescale, t.duration, err = readMediaHeader(r, c)
+					return err
+				case "hdlr":
+					buf, err := readBoxData(r, c, 12)
+					if err != nil {
+						return err
+					}
+					t.handler = string(buf[8:12])
+				case "minf":
+					return readSampleDescription(r, c, t)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return t, err
+}
+
+// readSampleDescription 从 minf/stbl/stsd 读取第一个样本描述的编码格式
+func readSampleDescription(r io.ReaderAt, minf box, t *mp4Track) error {
+	return readBoxes(r, minf.data, minf.end, func(b box) error {
+		if b.typ != "stbl" {
+			return nil
+		}
+		return readBoxes(r, b.data, b.end, func(c box) error {
+			if c.typ != "stsd" {
+				return nil
+			}
+			// version/flags(4) entry_count(4)，第一个样本描述的 size(4) type(4)，
+			// 视频样本描述中宽高位于第 32 字节
+			buf, err := readBoxData(r, c, 8+36)
+			if err != nil {
+				buf, err = readBoxData(r, c, 16)
+				if err != nil {
+					return err
+				}
+			}
+			fourcc := string(buf[12:16])
+			t.codec = mp4Codecs[fourcc]
+			if t.codec == "" {
+				t.codec = strings.TrimSpace(fourcc)
+			}
+			if len(buf) >= 44 && t.width == 0 {
+				t.width = int(binary.BigEndian.Uint16(buf[40:42]))
+				t.height = int(binary.BigEndian.Uint16(buf[42:44]))
+			}
+			return nil
+		})
+	})
+}
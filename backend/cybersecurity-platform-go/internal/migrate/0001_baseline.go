@@ -0,0 +1,142 @@
+// internal/migrate/0001_baseline.go
+package migrate
+
+// 基线结构：与 scripts/ 下各初始化脚本创建的表保持一致
+// 全部使用 IF NOT EXISTS，已有数据库执行时不会产生变化
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "baseline",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS students (
+				stuId VARCHAR(50) PRIMARY KEY,
+				password VARCHAR(255) NOT NULL,
+				email VARCHAR(100) NOT NULL UNIQUE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS userdetail (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				stuId VARCHAR(50) NOT NULL UNIQUE,
+				nickName VARCHAR(50),
+				userHead VARCHAR(500),
+				userName VARCHAR(50),
+				userEmail VARCHAR(100),
+				FOREIGN KEY (stuId) REFERENCES students(stuId)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS courses (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				title VARCHAR(255) NOT NULL,
+				description TEXT,
+				cover VARCHAR(500),
+				lesson_num INT DEFAULT 0,
+				credit DECIMAL(3,1) DEFAULT 0,
+				limit_count INT DEFAULT 100,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS teachers (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				name VARCHAR(100) NOT NULL,
+				career VARCHAR(200),
+				intro TEXT,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS teacher_courses (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				teacher_id INT NOT NULL,
+				course_id INT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (teacher_id) REFERENCES teachers(id),
+				FOREIGN KEY (course_id) REFERENCES courses(id),
+				UNIQUE KEY unique_teacher_course (teacher_id, course_id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS videos (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				url VARCHAR(500) NOT NULL,
+				description TEXT,
+				duration INT DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS chapters (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				course_id INT,
+				title VARCHAR(100) NOT NULL,
+				state INT DEFAULT 0,
+				FOREIGN KEY (course_id) REFERENCES courses(id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS chapter_children (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				chapter_id INT,
+				title VARCHAR(100) NOT NULL,
+				video_id INT,
+				FOREIGN KEY (chapter_id) REFERENCES chapters(id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS student_courses (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				stuId VARCHAR(50) NOT NULL,
+				course_id INT NOT NULL,
+				joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE,
+				UNIQUE KEY unique_student_course (stuId, course_id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS forum_categories (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				name VARCHAR(50) NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS forum_tags (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				name VARCHAR(50) NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS forum_articles (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				title VARCHAR(200) NOT NULL,
+				stuId VARCHAR(50) NOT NULL,
+				stuName VARCHAR(50) NOT NULL,
+				stuHead VARCHAR(500),
+				cateId INT DEFAULT 0,
+				isTop TINYINT(1) DEFAULT 0,
+				isEss TINYINT(1) DEFAULT 0,
+				viewCount INT DEFAULT 0,
+				createTime TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updateTime TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FOREIGN KEY (cateId) REFERENCES forum_categories(id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS article_tags (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				article_id INT NOT NULL,
+				tag_id INT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (article_id) REFERENCES forum_articles(id) ON DELETE CASCADE,
+				FOREIGN KEY (tag_id) REFERENCES forum_tags(id),
+				UNIQUE KEY unique_article_tag (article_id, tag_id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS forum_comments (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				article_id INT NOT NULL,
+				content TEXT NOT NULL,
+				author_id VARCHAR(50) NOT NULL,
+				author_name VARCHAR(50) NOT NULL,
+				author_head VARCHAR(500),
+				parent_id INT DEFAULT NULL,
+				status TINYINT(1) DEFAULT 1,
+				like_count INT DEFAULT 0,
+				create_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (article_id) REFERENCES forum_articles(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
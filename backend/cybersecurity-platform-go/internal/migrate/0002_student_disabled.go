@@ -0,0 +1,13 @@
+// internal/migrate/0002_student_disabled.go
+package migrate
+
+// 学生账号禁用标记（命令行 user disable 使用，登录时检查）
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "student_disabled",
+		Statements: []string{
+			`ALTER TABLE students ADD COLUMN disabled TINYINT(1) NOT NULL DEFAULT 0`,
+		},
+	})
+}
@@ -0,0 +1,14 @@
+// internal/migrate/0003_teacher_tokens.go
+package migrate
+
+// 教师接口令牌（只保存 SHA-256 摘要，令牌由命令行 teacher token 签发）
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "teacher_tokens",
+		Statements: []string{
+			`ALTER TABLE teachers ADD COLUMN token_hash CHAR(64) NULL`,
+			`ALTER TABLE teachers ADD UNIQUE KEY unique_teacher_token (token_hash)`,
+		},
+	})
+}
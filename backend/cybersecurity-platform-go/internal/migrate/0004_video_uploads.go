@@ -0,0 +1,29 @@
+// internal/migrate/0004_video_uploads.go
+package migrate
+
+// 视频分片上传会话，以及视频的上传者和文件大小（用于配额统计）
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "video_uploads",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS video_uploads (
+				id CHAR(32) PRIMARY KEY,
+				teacher_id INT NOT NULL,
+				filename VARCHAR(255) NOT NULL,
+				size BIGINT NOT NULL,
+				received BIGINT NOT NULL DEFAULT 0,
+				checksum CHAR(64) NOT NULL DEFAULT '',
+				description TEXT,
+				status VARCHAR(20) NOT NULL DEFAULT 'uploading',
+				video_id INT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				KEY idx_video_uploads_teacher (teacher_id, status),
+				FOREIGN KEY (teacher_id) REFERENCES teachers(id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+			`ALTER TABLE videos ADD COLUMN teacher_id INT NULL`,
+			`ALTER TABLE videos ADD COLUMN size BIGINT NOT NULL DEFAULT 0`,
+		},
+	})
+}
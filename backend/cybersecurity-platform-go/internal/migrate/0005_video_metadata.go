@@ -0,0 +1,17 @@
+// internal/migrate/0005_video_metadata.go
+package migrate
+
+// 视频元数据：分辨率、编码和码率（时长沿用 duration 列）
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "video_metadata",
+		Statements: []string{
+			`ALTER TABLE videos ADD COLUMN width INT NOT NULL DEFAULT 0`,
+			`ALTER TABLE videos ADD COLUMN height INT NOT NULL DEFAULT 0`,
+			`ALTER TABLE videos ADD COLUMN video_codec VARCHAR(32) NOT NULL DEFAULT ''`,
+			`ALTER TABLE videos ADD COLUMN audio_codec VARCHAR(32) NOT NULL DEFAULT ''`,
+			`ALTER TABLE videos ADD COLUMN bitrate BIGINT NOT NULL DEFAULT 0`,
+		},
+	})
+}
@@ -0,0 +1,14 @@
+// internal/migrate/0006_video_hls.go
+package migrate
+
+// 视频的 HLS 打包状态和主播放列表地址
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "video_hls",
+		Statements: []string{
+			`ALTER TABLE videos ADD COLUMN hls_status VARCHAR(20) NOT NULL DEFAULT ''`,
+			`ALTER TABLE videos ADD COLUMN hls_url VARCHAR(500) NOT NULL DEFAULT ''`,
+		},
+	})
+}
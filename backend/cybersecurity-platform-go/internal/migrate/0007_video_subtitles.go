@@ -0,0 +1,36 @@
+// internal/migrate/0007_video_subtitles.go
+package migrate
+
+// 视频字幕轨道和字幕文本索引（ngram 分词，支持中文检索）
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "video_subtitles",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS video_subtitles (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				video_id INT NOT NULL,
+				lang VARCHAR(35) NOT NULL,
+				label VARCHAR(64) NOT NULL DEFAULT '',
+				url VARCHAR(500) NOT NULL,
+				cue_count INT NOT NULL DEFAULT 0,
+				teacher_id INT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				UNIQUE KEY unique_video_lang (video_id, lang),
+				FOREIGN KEY (video_id) REFERENCES videos(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+			`CREATE TABLE IF NOT EXISTS subtitle_cues (
+				id BIGINT PRIMARY KEY AUTO_INCREMENT,
+				subtitle_id INT NOT NULL,
+				video_id INT NOT NULL,
+				start_ms INT NOT NULL,
+				end_ms INT NOT NULL,
+				text TEXT NOT NULL,
+				KEY idx_subtitle_cues_video (video_id, start_ms),
+				FULLTEXT KEY ft_subtitle_cues_text (text) WITH PARSER ngram,
+				FOREIGN KEY (subtitle_id) REFERENCES video_subtitles(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
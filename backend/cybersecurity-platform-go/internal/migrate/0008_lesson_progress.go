@@ -0,0 +1,30 @@
+// internal/migrate/0008_lesson_progress.go
+package migrate
+
+// 学生的课时观看进度：播放位置和已观看区间（JSON，单位秒）
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "lesson_progress",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS lesson_progress (
+				id BIGINT PRIMARY KEY AUTO_INCREMENT,
+				stuId VARCHAR(50) NOT NULL,
+				lesson_id INT NOT NULL,
+				course_id INT NOT NULL,
+				position DOUBLE NOT NULL DEFAULT 0,
+				duration DOUBLE NOT NULL DEFAULT 0,
+				watched TEXT NOT NULL,
+				watched_seconds DOUBLE NOT NULL DEFAULT 0,
+				completed TINYINT(1) NOT NULL DEFAULT 0,
+				completed_at TIMESTAMP NULL,
+				reported_at DATETIME(3) NULL,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				UNIQUE KEY unique_student_lesson (stuId, lesson_id),
+				KEY idx_lesson_progress_course (stuId, course_id),
+				FOREIGN KEY (lesson_id) REFERENCES chapter_children(id) ON DELETE CASCADE,
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
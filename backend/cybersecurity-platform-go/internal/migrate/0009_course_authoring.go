@@ -0,0 +1,18 @@
+// internal/migrate/0009_course_authoring.go
+package migrate
+
+// 教师编辑课程：章节和课时的显示顺序，课时可以附带PDF讲义
+// 已有数据按ID初始化顺序，与之前按ID排序的显示效果一致
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "course_authoring",
+		Statements: []string{
+			`ALTER TABLE chapters ADD COLUMN sort_order INT NOT NULL DEFAULT 0`,
+			`ALTER TABLE chapter_children ADD COLUMN sort_order INT NOT NULL DEFAULT 0`,
+			`ALTER TABLE chapter_children ADD COLUMN pdf_url VARCHAR(500) NULL`,
+			`UPDATE chapters SET sort_order = id WHERE sort_order = 0`,
+			`UPDATE chapter_children SET sort_order = id WHERE sort_order = 0`,
+		},
+	})
+}
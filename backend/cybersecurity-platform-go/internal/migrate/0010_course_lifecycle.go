@@ -0,0 +1,19 @@
+// internal/migrate/0010_course_lifecycle.go
+package migrate
+
+// 课程发布流程（草稿、审核中、已发布、已归档）和章节定时开放
+// 已有课程视为已发布；chapters.state 不再表示章节状态，接口返回的状态由开放时间和学习进度计算
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "course_lifecycle",
+		Statements: []string{
+			`ALTER TABLE courses ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published'`,
+			`ALTER TABLE courses ADD COLUMN status_note VARCHAR(500) NOT NULL DEFAULT ''`,
+			`ALTER TABLE courses ADD COLUMN published_at TIMESTAMP NULL`,
+			`ALTER TABLE courses ADD KEY idx_courses_status (status)`,
+			`UPDATE courses SET published_at = created_at WHERE status = 'published' AND published_at IS NULL`,
+			`ALTER TABLE chapters ADD COLUMN release_at DATETIME NULL`,
+		},
+	})
+}
@@ -0,0 +1,31 @@
+// internal/migrate/0011_subjects.go
+package migrate
+
+// 课程分类：多级分类（如 网络安全 → Web安全 → SQL注入），一门课程可以属于多个分类
+// 有子分类的分类不能删除（外键 RESTRICT），删除分类时自动取消课程与它的关联
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "subjects",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS subjects (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				parent_id INT NULL,
+				title VARCHAR(50) NOT NULL,
+				sort_order INT NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE KEY unique_subject_title (parent_id, title),
+				FOREIGN KEY (parent_id) REFERENCES subjects(id) ON DELETE RESTRICT
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS course_subjects (
+				course_id INT NOT NULL,
+				subject_id INT NOT NULL,
+				PRIMARY KEY (course_id, subject_id),
+				KEY idx_course_subjects_subject (subject_id),
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE,
+				FOREIGN KEY (subject_id) REFERENCES subjects(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
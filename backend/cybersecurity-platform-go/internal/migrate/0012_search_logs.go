@@ -0,0 +1,28 @@
+// internal/migrate/0012_search_logs.go
+package migrate
+
+// 课程搜索日志（用于计算热门搜索）和管理员置顶、屏蔽的搜索词
+// searcher 为学号或IP的摘要，只用于按人去重
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "search_logs",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS search_logs (
+				id BIGINT PRIMARY KEY AUTO_INCREMENT,
+				term VARCHAR(50) NOT NULL,
+				searcher CHAR(16) NOT NULL,
+				results INT NOT NULL DEFAULT 0,
+				created_at DATETIME NOT NULL,
+				KEY idx_search_logs_created (created_at, term)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS search_terms (
+				term VARCHAR(50) PRIMARY KEY,
+				action VARCHAR(8) NOT NULL,
+				sort_order INT NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
@@ -0,0 +1,27 @@
+// internal/migrate/0013_course_search.go
+package migrate
+
+// 课程全文检索：每门课程一行，汇总课程标题、简介、教师姓名以及章节和课时标题（ngram 分词，支持中文）
+// 各列单独建索引用于按列加权排序；索引内容由程序在课程变更时更新，已有课程在服务启动时补建
+func init() {
+	register(Migration{
+		Version: 13,
+		Name:    "course_search",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS course_search (
+				course_id INT PRIMARY KEY,
+				title VARCHAR(255) NOT NULL,
+				teachers VARCHAR(500) NOT NULL DEFAULT '',
+				outline MEDIUMTEXT NOT NULL,
+				description TEXT NOT NULL,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				FULLTEXT KEY ft_course_search_all (title, teachers, outline, description) WITH PARSER ngram,
+				FULLTEXT KEY ft_course_search_title (title) WITH PARSER ngram,
+				FULLTEXT KEY ft_course_search_teachers (teachers) WITH PARSER ngram,
+				FULLTEXT KEY ft_course_search_outline (outline) WITH PARSER ngram,
+				FULLTEXT KEY ft_course_search_description (description) WITH PARSER ngram,
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
@@ -0,0 +1,15 @@
+// internal/migrate/0014_course_list_stats.go
+package migrate
+
+// 课程列表排序所需的数据：课程评分汇总（由课程评价维护，没有评价时为0），以及按课程统计最近学习时间的索引
+func init() {
+	register(Migration{
+		Version: 14,
+		Name:    "course_list_stats",
+		Statements: []string{
+			`ALTER TABLE courses ADD COLUMN rating_avg DECIMAL(3,2) NOT NULL DEFAULT 0`,
+			`ALTER TABLE courses ADD COLUMN rating_count INT NOT NULL DEFAULT 0`,
+			`ALTER TABLE lesson_progress ADD KEY idx_lesson_progress_activity (course_id, updated_at)`,
+		},
+	})
+}
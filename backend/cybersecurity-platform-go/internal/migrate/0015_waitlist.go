@@ -0,0 +1,39 @@
+// internal/migrate/0015_waitlist.go
+package migrate
+
+// 满员课程的候补名单和学生站内通知
+// 候补按 queued_at 排队；有名额时依次向候补学生发出限时名额（offered），过期未选课则作废并顺延给下一位
+func init() {
+	register(Migration{
+		Version: 15,
+		Name:    "waitlist",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS course_waitlist (
+				id BIGINT PRIMARY KEY AUTO_INCREMENT,
+				course_id INT NOT NULL,
+				stuId VARCHAR(50) NOT NULL,
+				status VARCHAR(16) NOT NULL DEFAULT 'waiting',
+				queued_at DATETIME(3) NOT NULL,
+				offered_at DATETIME NULL,
+				expires_at DATETIME NULL,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				UNIQUE KEY unique_waitlist_student (course_id, stuId),
+				KEY idx_waitlist_queue (course_id, status, queued_at),
+				KEY idx_waitlist_student (stuId, status),
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS notifications (
+				id BIGINT PRIMARY KEY AUTO_INCREMENT,
+				stuId VARCHAR(50) NOT NULL,
+				kind VARCHAR(32) NOT NULL,
+				title VARCHAR(200) NOT NULL,
+				content VARCHAR(1000) NOT NULL DEFAULT '',
+				course_id INT NULL,
+				read_at DATETIME NULL,
+				created_at DATETIME NOT NULL,
+				KEY idx_notifications_student (stuId, id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
@@ -0,0 +1,35 @@
+// internal/migrate/0016_enrollment_states.go
+package migrate
+
+// 选课状态（在修、已退课、已结课、未通过）和选课记录变更历史，课程可以单独设置退课截止时间
+// 已有的选课记录视为在修，并以加入时间补一条历史记录
+func init() {
+	register(Migration{
+		Version: 16,
+		Name:    "enrollment_states",
+		Statements: []string{
+			`ALTER TABLE student_courses ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active'`,
+			`ALTER TABLE student_courses ADD COLUMN ended_at DATETIME NULL`,
+			`ALTER TABLE student_courses ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP`,
+			`ALTER TABLE student_courses ADD KEY idx_student_courses_status (course_id, status)`,
+			`ALTER TABLE courses ADD COLUMN drop_deadline DATETIME NULL`,
+
+			`CREATE TABLE IF NOT EXISTS enrollment_events (
+				id BIGINT PRIMARY KEY AUTO_INCREMENT,
+				stuId VARCHAR(50) NOT NULL,
+				course_id INT NOT NULL,
+				from_status VARCHAR(16) NOT NULL DEFAULT '',
+				to_status VARCHAR(16) NOT NULL,
+				actor VARCHAR(32) NOT NULL,
+				reason VARCHAR(500) NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL,
+				KEY idx_enrollment_events_student (stuId, id),
+				KEY idx_enrollment_events_course (course_id, id),
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`INSERT INTO enrollment_events (stuId, course_id, from_status, to_status, actor, created_at)
+				SELECT stuId, course_id, '', 'active', 'student', joined_at FROM student_courses`,
+		},
+	})
+}
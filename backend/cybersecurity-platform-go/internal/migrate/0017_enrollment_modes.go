@@ -0,0 +1,43 @@
+// internal/migrate/0017_enrollment_modes.go
+package migrate
+
+// 课程的选课方式（自由加入、需要审核、凭邀请码加入），选课申请和邀请码
+func init() {
+	register(Migration{
+		Version: 17,
+		Name:    "enrollment_modes",
+		Statements: []string{
+			`ALTER TABLE courses ADD COLUMN enroll_mode VARCHAR(16) NOT NULL DEFAULT 'open'`,
+
+			`CREATE TABLE IF NOT EXISTS enrollment_requests (
+				id BIGINT PRIMARY KEY AUTO_INCREMENT,
+				course_id INT NOT NULL,
+				stuId VARCHAR(50) NOT NULL,
+				status VARCHAR(16) NOT NULL,
+				message VARCHAR(500) NOT NULL DEFAULT '',
+				review_note VARCHAR(500) NOT NULL DEFAULT '',
+				reviewer VARCHAR(32) NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL,
+				reviewed_at DATETIME NULL,
+				UNIQUE KEY unique_request_student_course (course_id, stuId),
+				KEY idx_enrollment_requests_status (status, created_at),
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS course_invites (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				course_id INT NOT NULL,
+				code VARCHAR(32) NOT NULL,
+				max_uses INT NOT NULL DEFAULT 0,
+				used INT NOT NULL DEFAULT 0,
+				expires_at DATETIME NULL,
+				created_by VARCHAR(32) NOT NULL,
+				created_at DATETIME NOT NULL,
+				revoked_at DATETIME NULL,
+				UNIQUE KEY unique_course_invite_code (code),
+				KEY idx_course_invites_course (course_id),
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
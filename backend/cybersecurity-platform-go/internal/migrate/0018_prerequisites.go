@@ -0,0 +1,34 @@
+// internal/migrate/0018_prerequisites.go
+package migrate
+
+// 课程和章节的先修规则
+// 同一 group_no 内的条件满足其一即可（或），不同 group_no 之间都要满足（且）
+func init() {
+	register(Migration{
+		Version: 18,
+		Name:    "prerequisites",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS course_prerequisites (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				course_id INT NOT NULL,
+				group_no INT NOT NULL,
+				required_course_id INT NOT NULL,
+				UNIQUE KEY unique_course_prerequisite (course_id, group_no, required_course_id),
+				KEY idx_course_prerequisites_required (required_course_id),
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE,
+				FOREIGN KEY (required_course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`CREATE TABLE IF NOT EXISTS chapter_prerequisites (
+				id INT PRIMARY KEY AUTO_INCREMENT,
+				chapter_id INT NOT NULL,
+				group_no INT NOT NULL,
+				required_chapter_id INT NOT NULL,
+				UNIQUE KEY unique_chapter_prerequisite (chapter_id, group_no, required_chapter_id),
+				KEY idx_chapter_prerequisites_required (required_chapter_id),
+				FOREIGN KEY (chapter_id) REFERENCES chapters(id) ON DELETE CASCADE,
+				FOREIGN KEY (required_chapter_id) REFERENCES chapters(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
@@ -0,0 +1,39 @@
+// internal/migrate/0019_course_reviews.go
+package migrate
+
+// 课程评分和评价：每名学生对每门课程一条评价，带文字的评价需要管理员审核后公开
+// courses.rating_avg、rating_count 和各星级人数（rating_1 到 rating_5）由评价维护，被拒绝的评价不计入
+func init() {
+	register(Migration{
+		Version: 19,
+		Name:    "course_reviews",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS course_reviews (
+				id BIGINT PRIMARY KEY AUTO_INCREMENT,
+				course_id INT NOT NULL,
+				stuId VARCHAR(50) NOT NULL,
+				rating TINYINT NOT NULL,
+				content VARCHAR(2000) NOT NULL DEFAULT '',
+				status VARCHAR(16) NOT NULL,
+				moderation_note VARCHAR(500) NOT NULL DEFAULT '',
+				moderated_at DATETIME NULL,
+				reply VARCHAR(1000) NOT NULL DEFAULT '',
+				replied_by VARCHAR(32) NOT NULL DEFAULT '',
+				replied_at DATETIME NULL,
+				created_at DATETIME NOT NULL,
+				updated_at DATETIME NOT NULL,
+				UNIQUE KEY unique_review_student_course (course_id, stuId),
+				KEY idx_course_reviews_course (course_id, status, created_at),
+				KEY idx_course_reviews_status (status, updated_at),
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`ALTER TABLE courses
+				ADD COLUMN rating_1 INT NOT NULL DEFAULT 0,
+				ADD COLUMN rating_2 INT NOT NULL DEFAULT 0,
+				ADD COLUMN rating_3 INT NOT NULL DEFAULT 0,
+				ADD COLUMN rating_4 INT NOT NULL DEFAULT 0,
+				ADD COLUMN rating_5 INT NOT NULL DEFAULT 0`,
+		},
+	})
+}
@@ -0,0 +1,26 @@
+// internal/migrate/0020_course_teacher_roles.go
+package migrate
+
+// 一门课程可以有多名教师：负责人（lead）、合作教师（co_teacher）和助教（ta），按 sort_order 排列显示
+// 合作教师和助教的权限可以按课程设置（course_role_permissions），没有设置时使用服务配置的默认值
+func init() {
+	register(Migration{
+		Version: 20,
+		Name:    "course_teacher_roles",
+		Statements: []string{
+			`ALTER TABLE teacher_courses
+				ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'lead',
+				ADD COLUMN sort_order INT NOT NULL DEFAULT 0,
+				ADD KEY idx_teacher_courses_course (course_id, sort_order)`,
+
+			`CREATE TABLE IF NOT EXISTS course_role_permissions (
+				course_id INT NOT NULL,
+				role VARCHAR(16) NOT NULL,
+				permissions VARCHAR(255) NOT NULL DEFAULT '',
+				updated_at DATETIME NOT NULL,
+				PRIMARY KEY (course_id, role),
+				FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
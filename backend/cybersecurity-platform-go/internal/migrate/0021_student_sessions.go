@@ -0,0 +1,20 @@
+// internal/migrate/0021_student_sessions.go
+package migrate
+
+// 学生登录会话（只保存令牌的 SHA-256 摘要），签名媒体地址时用会话确定当前学生
+func init() {
+	register(Migration{
+		Version: 21,
+		Name:    "student_sessions",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS student_sessions (
+				token_hash CHAR(64) PRIMARY KEY,
+				stuId VARCHAR(50) NOT NULL,
+				created_at DATETIME NOT NULL,
+				expires_at DATETIME NOT NULL,
+				KEY idx_student_sessions_student (stuId),
+				FOREIGN KEY (stuId) REFERENCES students(stuId) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+		},
+	})
+}
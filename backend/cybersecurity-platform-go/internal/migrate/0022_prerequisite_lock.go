@@ -0,0 +1,18 @@
+// internal/migrate/0022_prerequisite_lock.go
+package migrate
+
+// 串行修改先修规则用的哨兵行：检查循环依赖需要读取整张关系图，
+// 修改前锁定该行，避免两个并发修改各自检查通过、合起来却形成循环
+func init() {
+	register(Migration{
+		Version: 22,
+		Name:    "prerequisite_lock",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS app_locks (
+				name VARCHAR(64) PRIMARY KEY
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+
+			`INSERT IGNORE INTO app_locks (name) VALUES ('prerequisites')`,
+		},
+	})
+}
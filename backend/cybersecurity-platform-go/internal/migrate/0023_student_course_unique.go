@@ -0,0 +1,35 @@
+// internal/migrate/0023_student_course_unique.go
+package migrate
+
+// 选课记录的唯一索引 (stuId, course_id)：选课、候补和名额检查都依赖它，
+// 基线迁移只在建表时创建，早于该索引的旧库 CREATE TABLE IF NOT EXISTS 不会补上。
+// 索引不存在时先去掉重复的选课记录（优先保留在修的记录，其次保留最新的一条），再补建索引。
+// 每条语句可能在连接池的不同连接上执行，不能用会话变量拼接语句，所以借助一个临时存储过程完成判断
+func init() {
+	register(Migration{
+		Version: 23,
+		Name:    "student_course_unique",
+		Statements: []string{
+			`DROP PROCEDURE IF EXISTS migrate_student_course_unique`,
+
+			`CREATE PROCEDURE migrate_student_course_unique()
+			BEGIN
+				IF NOT EXISTS (
+					SELECT 1 FROM information_schema.statistics
+					WHERE table_schema = DATABASE() AND table_name = 'student_courses'
+						AND index_name = 'unique_student_course'
+				) THEN
+					DELETE sc FROM student_courses sc
+					JOIN student_courses keep ON keep.stuId = sc.stuId AND keep.course_id = sc.course_id
+						AND ((keep.status = 'active') > (sc.status = 'active')
+							OR ((keep.status = 'active') = (sc.status = 'active') AND keep.id > sc.id));
+					ALTER TABLE student_courses ADD UNIQUE KEY unique_student_course (stuId, course_id);
+				END IF;
+			END`,
+
+			`CALL migrate_student_course_unique()`,
+
+			`DROP PROCEDURE migrate_student_course_unique`,
+		},
+	})
+}
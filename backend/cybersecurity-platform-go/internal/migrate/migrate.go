@@ -0,0 +1,187 @@
+// internal/migrate/migrate.go
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Migration 一次数据库结构变更
+// MySQL 的 DDL 无法回滚也不能放进事务，Up 会逐条记录已执行的语句，失败后重新执行时从失败的那条继续
+// 语句本身不要求幂等，但已执行的迁移不能再修改（包括调整语句顺序）
+type Migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+// AppliedMigration 已执行的迁移记录
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+var registry []Migration
+
+// register 注册迁移（在各迁移文件的 init 中调用）
+func register(m Migration) {
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("迁移版本重复: %d (%s / %s)", m.Version, existing.Name, m.Name))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool {
+		return registry[i].Version < registry[j].Version
+	})
+}
+
+// Migrations 返回按版本排序的全部迁移
+func Migrations() []Migration {
+	return append([]Migration(nil), registry...)
+}
+
+// ensureTable 创建迁移记录表
+// schema_migration_steps 记录未完成迁移中已执行的语句，迁移完成后删除
+func ensureTable(db *sql.DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(200) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+	`); err != nil {
+		return err
+	}
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migration_steps (
+			version INT NOT NULL,
+			step INT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (version, step)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+	`)
+	return err
+}
+
+// Applied 查询已执行的迁移
+func Applied(db *sql.DB) (map[int]AppliedMigration, error) {
+	if err := ensureTable(db); err != nil {
+		return nil, fmt.Errorf("创建 schema_migrations 表失败: %v", err)
+	}
+
+	rows, err := db.Query("SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]AppliedMigration)
+	for rows.Next() {
+		var m AppliedMigration
+		if err := rows.Scan(&m.Version, &m.Name, &m.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[m.Version] = m
+	}
+	return applied, rows.Err()
+}
+
+// Pending 返回尚未执行的迁移
+func Pending(db *sql.DB) ([]Migration, error) {
+	applied, err := Applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range registry {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// stepsDone 查询某个迁移已执行的语句序号（从1开始）
+func stepsDone(db *sql.DB, version int) (map[int]bool, error) {
+	rows, err := db.Query("SELECT step FROM schema_migration_steps WHERE version = ?", version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int]bool)
+	for rows.Next() {
+		var step int
+		if err := rows.Scan(&step); err != nil {
+			return nil, err
+		}
+		done[step] = true
+	}
+	return done, rows.Err()
+}
+
+// Up 按版本顺序执行所有未执行的迁移，返回本次执行的迁移
+// 每条语句执行后立即记录，某条语句失败时停止；修复问题后再次执行会跳过已记录的语句
+// 语句已执行但记录写入失败时（如执行后连接中断），下次会重复执行该语句，此时需要手动处理
+func Up(db *sql.DB) ([]Migration, error) {
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range pending {
+		steps, err := stepsDone(db, m.Version)
+		if err != nil {
+			return done, fmt.Errorf("查询迁移 %04d_%s 的执行进度失败: %v", m.Version, m.Name, err)
+		}
+		if len(steps) > 0 {
+			log.Printf("继续执行迁移: %04d_%s（已执行 %d/%d 条语句）", m.Version, m.Name, len(steps), len(m.Statements))
+		}
+
+		for i, stmt := range m.Statements {
+			step := i + 1
+			if steps[step] {
+				continue
+			}
+			if _, err := db.Exec(stmt); err != nil {
+				return done, fmt.Errorf("迁移 %04d_%s 第 %d 条语句执行失败: %v", m.Version, m.Name, step, err)
+			}
+			if _, err := db.Exec(
+				"INSERT INTO schema_migration_steps (version, step) VALUES (?, ?)",
+				m.Version, step,
+			); err != nil {
+				return done, fmt.Errorf("记录迁移 %04d_%s 第 %d 条语句失败（语句已执行）: %v", m.Version, m.Name, step, err)
+			}
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return done, err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+			m.Version, m.Name,
+		); err != nil {
+			tx.Rollback()
+			return done, fmt.Errorf("记录迁移 %04d_%s 失败: %v", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec("DELETE FROM schema_migration_steps WHERE version = ?", m.Version); err != nil {
+			tx.Rollback()
+			return done, fmt.Errorf("记录迁移 %04d_%s 失败: %v", m.Version, m.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return done, fmt.Errorf("记录迁移 %04d_%s 失败: %v", m.Version, m.Name, err)
+		}
+
+		log.Printf("已执行迁移: %04d_%s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
@@ -0,0 +1,235 @@
+// internal/ops/approval.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 选课申请状态
+const (
+	RequestPending   = "pending"   // 等待审核
+	RequestApproved  = "approved"  // 已通过，学生已加入课程
+	RequestRejected  = "rejected"  // 已拒绝，学生可以重新申请
+	RequestCancelled = "cancelled" // 学生撤回
+)
+
+var (
+	// ErrRequestNotFound 选课申请不存在
+	ErrRequestNotFound = errors.New("选课申请不存在")
+	// ErrRequestReviewed 选课申请已经处理过
+	ErrRequestReviewed = errors.New("选课申请已处理")
+	// ErrWaitlistClosed 需要审核或邀请码的课程不能候补
+	ErrWaitlistClosed = errors.New("该课程需要审核或邀请码，不能加入候补名单")
+)
+
+// EnrollmentRequest 学生的选课申请
+type EnrollmentRequest struct {
+	ID          int64      `json:"id"`
+	CourseID    int        `json:"courseId"`
+	CourseTitle string     `json:"courseTitle"`
+	StuID       string     `json:"stuId"`
+	NickName    string     `json:"nickName"`
+	Status      string     `json:"status"`
+	Message     string     `json:"message"`    // 学生的申请说明
+	ReviewNote  string     `json:"reviewNote"` // 教师的审核意见
+	Reviewer    string     `json:"reviewer"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ReviewedAt  *time.Time `json:"reviewedAt"`
+}
+
+// requestEnrollment 提交或重新提交选课申请（被拒绝、撤回后可以再次申请）
+func requestEnrollment(tx *sql.Tx, stuID string, courseID int, message string, now time.Time) error {
+	message = strings.TrimSpace(message)
+	if utf8.RuneCountInString(message) > 500 {
+		return InputError("申请说明不能超过500个字符")
+	}
+	_, err := tx.Exec(`
+		INSERT INTO enrollment_requests (course_id, stuId, status, message, created_at) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE status = VALUES(status), message = VALUES(message), created_at = VALUES(created_at),
+			review_note = '', reviewer = '', reviewed_at = NULL
+	`, courseID, stuID, RequestPending, message, now)
+	return err
+}
+
+// StudentEnrollmentRequest 学生在课程中的选课申请，没有申请时返回 nil
+func StudentEnrollmentRequest(db *sql.DB, stuID string, courseID int) (*EnrollmentRequest, error) {
+	list, err := enrollmentRequests(db, "r.stuId = ? AND r.course_id = ?", stuID, courseID)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// CancelEnrollmentRequest 学生撤回待审核的申请
+func CancelEnrollmentRequest(db *sql.DB, stuID string, courseID int) error {
+	res, err := db.Exec(
+		"UPDATE enrollment_requests SET status = ? WHERE course_id = ? AND stuId = ? AND status = ?",
+		RequestCancelled, courseID, stuID, RequestPending,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRequestNotFound
+	}
+	return nil
+}
+
+// EnrollmentRequests 审核队列：教师讲授的课程（teacherID 为0表示全部课程）的选课申请，按提交时间排序
+// courseID 为0表示不限课程，status 为空表示全部状态
+func EnrollmentRequests(db *sql.DB, teacherID, courseID int, status string) ([]EnrollmentRequest, error) {
+	var where []string
+	var args []interface{}
+	if teacherID != 0 {
+		// 只包括教师有管理学生权限的课程
+		ids, err := teacherCourseIDs(db, teacherID, PermStudents)
+		if err != nil || len(ids) == 0 {
+			return []EnrollmentRequest{}, err
+		}
+		placeholders, idArgs := inArgs(ids)
+		where = append(where, "r.course_id IN ("+placeholders+")")
+		args = append(args, idArgs...)
+	}
+	if courseID != 0 {
+		if err := checkCourseAccess(db, teacherID, courseID, PermStudents); err != nil {
+			return nil, err
+		}
+		where = append(where, "r.course_id = ?")
+		args = append(args, courseID)
+	}
+	if status != "" {
+		where = append(where, "r.status = ?")
+		args = append(args, status)
+	}
+	if len(where) == 0 {
+		where = append(where, "1 = 1")
+	}
+	return enrollmentRequests(db, strings.Join(where, " AND "), args...)
+}
+
+func enrollmentRequests(db *sql.DB, where string, args ...interface{}) ([]EnrollmentRequest, error) {
+	rows, err := db.Query(`
+		SELECT r.id, r.course_id, c.title, r.stuId, ud.nickName, r.status, r.message, r.review_note, r.reviewer,
+			r.created_at, r.reviewed_at
+		FROM enrollment_requests r
+		JOIN courses c ON c.id = r.course_id
+		LEFT JOIN userdetail ud ON ud.stuId = r.stuId
+		WHERE `+where+`
+		ORDER BY r.created_at, r.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []EnrollmentRequest{}
+	for rows.Next() {
+		var r EnrollmentRequest
+		var nickName sql.NullString
+		var reviewed sql.NullTime
+		if err := rows.Scan(&r.ID, &r.CourseID, &r.CourseTitle, &r.StuID, &nickName, &r.Status, &r.Message,
+			&r.ReviewNote, &r.Reviewer, &r.CreatedAt, &reviewed); err != nil {
+			return nil, err
+		}
+		r.NickName = nickName.String
+		r.ReviewedAt = nullTime(reviewed)
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
+
+// ReviewEnrollmentRequest 教师或管理员（teacherID 为0）审核选课申请，并通知学生
+// 通过时检查人数限制（课程已满返回 ErrCourseFull，可以先提高人数限制）
+func ReviewEnrollmentRequest(db *sql.DB, teacherID int, requestID int64, approve bool, note string) error {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > 500 {
+		return InputError("审核意见不能超过500个字符")
+	}
+
+	var courseID int
+	err := db.QueryRow("SELECT course_id FROM enrollment_requests WHERE id = ?", requestID).Scan(&courseID)
+	if err == sql.ErrNoRows {
+		return ErrRequestNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 与学生选课一样先锁定课程行，再锁定申请
+	var courseStatus string
+	var limit int
+	if err := tx.QueryRow("SELECT status, limit_count FROM courses WHERE id = ? FOR UPDATE", courseID).
+		Scan(&courseStatus, &limit); err != nil {
+		return err
+	}
+	if teacherID != 0 {
+		if err := checkCourseTeacher(tx, teacherID, courseID, PermStudents, false); err != nil {
+			return err
+		}
+	}
+	var stuID, status string
+	if err := tx.QueryRow("SELECT stuId, status FROM enrollment_requests WHERE id = ? FOR UPDATE", requestID).
+		Scan(&stuID, &status); err != nil {
+		return err
+	}
+	if status != RequestPending {
+		return ErrRequestReviewed
+	}
+
+	now := time.Now()
+	actor := teacherActor(teacherID)
+	to, title, content := RequestRejected, "选课申请未通过", "你的选课申请未通过审核。"
+	if approve {
+		to, title, content = RequestApproved, "选课申请已通过", "你的选课申请已通过审核，已加入课程。"
+		if courseStatus != CoursePublished {
+			return ErrCourseNotFound
+		}
+		from, err := enrollmentStatus(tx, stuID, courseID)
+		if err != nil {
+			return err
+		}
+		if from != EnrollActive && from != EnrollCompleted {
+			if _, err := refreshWaitlist(tx, courseID, limit, now); err != nil {
+				return err
+			}
+			taken, err := seatsTaken(tx, courseID, stuID, now)
+			if err != nil {
+				return err
+			}
+			if taken >= limit {
+				return ErrCourseFull
+			}
+			if err := activateEnrollment(tx, stuID, courseID, from, actor, "选课申请通过", now); err != nil {
+				return err
+			}
+		}
+	}
+	if note != "" {
+		content += "审核意见：" + note
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE enrollment_requests SET status = ?, review_note = ?, reviewer = ?, reviewed_at = ? WHERE id = ?",
+		to, note, actor, now, requestID,
+	); err != nil {
+		return err
+	}
+	kind := NoticeEnrollRejected
+	if approve {
+		kind = NoticeEnrollApproved
+	}
+	if err := notify(tx, stuID, kind, title, content, courseID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,773 @@
+// internal/ops/authoring.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNotCourseTeacher 教师没有讲授该课程
+	ErrNotCourseTeacher = errors.New("没有管理该课程的权限")
+	// ErrChapterNotFound 章节不存在
+	ErrChapterNotFound = errors.New("章节不存在")
+	// ErrLessonNotFound 课时不存在
+	ErrLessonNotFound = errors.New("课时不存在")
+	// ErrCourseHasStudents 课程已有学生选修，不能删除
+	ErrCourseHasStudents = errors.New("课程已有学生选修，不能删除")
+	// ErrVideoNotAttachable 视频不存在，或既不是该教师上传的、也没有用在该教师的课程中
+	ErrVideoNotAttachable = errors.New("视频不存在或无权使用")
+	// ErrBadOrder 排序列表与现有的章节或课时不一致
+	ErrBadOrder = errors.New("排序列表必须恰好包含全部章节或课时")
+)
+
+// InputError 参数校验失败
+type InputError string
+
+func (e InputError) Error() string { return string(e) }
+
+// CourseInput 创建或修改课程的字段，为 nil 的字段在修改时保持不变
+// DropDeadline 为退课截止时间（格式同章节开放时间），空字符串表示按加入时间计算
+// EnrollMode 为选课方式：open 自由加入、approval 需要教师审核、invite 凭邀请码加入
+type CourseInput struct {
+	Title        *string  `json:"title"`
+	Description  *string  `json:"description"`
+	Cover        *string  `json:"cover"`
+	Credit       *float64 `json:"credit"`
+	LimitCount   *int     `json:"limitCount"`
+	DropDeadline *string  `json:"dropDeadline"`
+	EnrollMode   *string  `json:"enrollMode"`
+}
+
+// LessonInput 创建或修改课时的字段，为 nil 的字段在修改时保持不变
+// VideoID 为0、PdfURL 为空字符串表示取消关联；ChapterID 用于把课时移动到同一课程的其他章节
+type LessonInput struct {
+	Title     *string `json:"title"`
+	VideoID   *int    `json:"videoId"`
+	PdfURL    *string `json:"pdfUrl"`
+	ChapterID *int    `json:"chapterId"`
+}
+
+// CourseOutline 教师编辑课程时看到的课程结构
+type CourseOutline struct {
+	ID           int              `json:"id"`
+	Title        string           `json:"title"`
+	Description  string           `json:"description"`
+	Cover        string           `json:"cover"`
+	Credit       float64          `json:"credit"`
+	LimitCount   int              `json:"limitCount"`
+	LessonNum    int              `json:"lessonNum"`
+	Status       string           `json:"status"`
+	StatusNote   string           `json:"statusNote"`   // 管理员退回时的审核意见
+	DropDeadline *time.Time       `json:"dropDeadline"` // 退课截止时间，为空表示按加入时间计算
+	EnrollMode   string           `json:"enrollMode"`
+	SubjectIDs   []int            `json:"subjectIds,omitempty"`
+	Chapters     []OutlineChapter `json:"chapters,omitempty"`
+	// Prerequisites 先修课程规则，只在查询单门课程时返回
+	Prerequisites []PrerequisiteGroup `json:"prerequisites,omitempty"`
+}
+
+// OutlineChapter 章节
+type OutlineChapter struct {
+	ID        int             `json:"id"`
+	Title     string          `json:"title"`
+	ReleaseAt *time.Time      `json:"releaseAt"` // 为空表示立即开放
+	Lessons   []OutlineLesson `json:"lessons"`
+	// Prerequisites 先修章节规则，学完后才能学习本章节
+	Prerequisites []PrerequisiteGroup `json:"prerequisites,omitempty"`
+}
+
+// OutlineLesson 课时
+type OutlineLesson struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	VideoID  int    `json:"videoId"`
+	VideoURL string `json:"videoUrl"`
+	PdfURL   string `json:"pdfUrl"`
+}
+
+// queryExecer *sql.DB 和 *sql.Tx 的公共方法
+type queryExecer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// CheckCourseTeacher 检查教师是否讲授该课程（teacher_courses），并且在课程中的角色有 perm 权限
+func CheckCourseTeacher(db *sql.DB, teacherID, courseID int, perm string) error {
+	return checkCourseTeacher(db, teacherID, courseID, perm, false)
+}
+
+// TeacherCourses 教师讲授的课程
+func TeacherCourses(db *sql.DB, teacherID int) ([]CourseOutline, error) {
+	rows, err := db.Query(`
+		SELECT c.id, c.title, c.description, c.cover, c.credit, c.limit_count, c.lesson_num, c.status, c.status_note, c.drop_deadline, c.enroll_mode
+		FROM teacher_courses tc
+		JOIN courses c ON tc.course_id = c.id
+		WHERE tc.teacher_id = ?
+		ORDER BY c.id DESC
+	`, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := []CourseOutline{}
+	for rows.Next() {
+		c, err := scanCourse(rows)
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, *c)
+	}
+	return courses, rows.Err()
+}
+
+// scanCourse 读取 id, title, description, cover, credit, limit_count, lesson_num, status, status_note, drop_deadline, enroll_mode
+func scanCourse(row interface{ Scan(...interface{}) error }) (*CourseOutline, error) {
+	var c CourseOutline
+	var description, cover sql.NullString
+	var dropDeadline sql.NullTime
+	if err := row.Scan(&c.ID, &c.Title, &description, &cover, &c.Credit, &c.LimitCount, &c.LessonNum,
+		&c.Status, &c.StatusNote, &dropDeadline, &c.EnrollMode); err != nil {
+		return nil, err
+	}
+	c.Description = description.String
+	c.Cover = cover.String
+	c.DropDeadline = nullTime(dropDeadline)
+	return &c, nil
+}
+
+// CourseOutlineFor 查询课程及其章节、课时（按显示顺序）
+func CourseOutlineFor(db *sql.DB, teacherID, courseID int) (*CourseOutline, error) {
+	if err := CheckCourseTeacher(db, teacherID, courseID, ""); err != nil {
+		return nil, err
+	}
+
+	c, err := scanCourse(db.QueryRow(
+		"SELECT id, title, description, cover, credit, limit_count, lesson_num, status, status_note, drop_deadline, enroll_mode FROM courses WHERE id = ?",
+		courseID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrCourseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.SubjectIDs, err = courseSubjectIDs(db, courseID); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT ch.id, ch.title, ch.release_at, cc.id, cc.title, cc.video_id, v.url, cc.pdf_url
+		FROM chapters ch
+		LEFT JOIN chapter_children cc ON ch.id = cc.chapter_id
+		LEFT JOIN videos v ON cc.video_id = v.id
+		WHERE ch.course_id = ?
+		ORDER BY ch.sort_order, ch.id, cc.sort_order, cc.id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	c.Chapters = []OutlineChapter{}
+	for rows.Next() {
+		var chapter OutlineChapter
+		var releaseAt sql.NullTime
+		var lessonID, videoID sql.NullInt64
+		var lessonTitle, videoURL, pdfURL sql.NullString
+		if err := rows.Scan(&chapter.ID, &chapter.Title, &releaseAt,
+			&lessonID, &lessonTitle, &videoID, &videoURL, &pdfURL); err != nil {
+			return nil, err
+		}
+		if releaseAt.Valid {
+			chapter.ReleaseAt = &releaseAt.Time
+		}
+		if n := len(c.Chapters); n == 0 || c.Chapters[n-1].ID != chapter.ID {
+			chapter.Lessons = []OutlineLesson{}
+			c.Chapters = append(c.Chapters, chapter)
+		}
+		if lessonID.Valid {
+			ch := &c.Chapters[len(c.Chapters)-1]
+			ch.Lessons = append(ch.Lessons, OutlineLesson{
+				ID:       int(lessonID.Int64),
+				Title:    lessonTitle.String,
+				VideoID:  int(videoID.Int64),
+				VideoURL: videoURL.String,
+				PdfURL:   pdfURL.String,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.Prerequisites, err = CoursePrerequisites(db, courseID); err != nil {
+		return nil, err
+	}
+	rules, err := ChapterPrerequisites(db, courseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range c.Chapters {
+		c.Chapters[i].Prerequisites = rules[c.Chapters[i].ID]
+	}
+	return c, nil
+}
+
+// CreateCourse 创建课程（草稿），创建者成为课程教师
+func CreateCourse(db *sql.DB, teacherID int, in CourseInput) (int64, error) {
+	if in.Title == nil {
+		return 0, InputError("课程标题不能为空")
+	}
+	if err := in.validate(); err != nil {
+		return 0, err
+	}
+	description, cover, credit, limit := "", "", 0.0, 100
+	if in.Description != nil {
+		description = *in.Description
+	}
+	if in.Cover != nil {
+		cover = *in.Cover
+	}
+	if in.Credit != nil {
+		credit = *in.Credit
+	}
+	if in.LimitCount != nil {
+		limit = *in.LimitCount
+	}
+	var dropDeadline *time.Time
+	if in.DropDeadline != nil {
+		dropDeadline, _ = ParseReleaseAt(*in.DropDeadline)
+	}
+	mode := EnrollModeOpen
+	if in.EnrollMode != nil {
+		mode = *in.EnrollMode
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO courses (title, description, cover, lesson_num, credit, limit_count, status, drop_deadline, enroll_mode) VALUES (?, ?, ?, 0, ?, ?, ?, ?, ?)",
+		strings.TrimSpace(*in.Title), description, cover, credit, limit, CourseDraft, dropDeadline, mode,
+	)
+	if err != nil {
+		return 0, err
+	}
+	courseID, _ := result.LastInsertId()
+	if _, err := tx.Exec("INSERT INTO teacher_courses (teacher_id, course_id, role) VALUES (?, ?, ?)", teacherID, courseID, RoleLead); err != nil {
+		return 0, err
+	}
+	if err := reindexCourse(tx, int(courseID)); err != nil {
+		return 0, err
+	}
+	return courseID, tx.Commit()
+}
+
+// UpdateCourse 修改课程信息
+func UpdateCourse(db *sql.DB, teacherID, courseID int, in CourseInput) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	if err := CheckCourseTeacher(db, teacherID, courseID, PermContent); err != nil {
+		return err
+	}
+
+	var sets []string
+	var args []interface{}
+	if in.Title != nil {
+		sets, args = append(sets, "title = ?"), append(args, strings.TrimSpace(*in.Title))
+	}
+	if in.Description != nil {
+		sets, args = append(sets, "description = ?"), append(args, *in.Description)
+	}
+	if in.Cover != nil {
+		sets, args = append(sets, "cover = ?"), append(args, *in.Cover)
+	}
+	if in.Credit != nil {
+		sets, args = append(sets, "credit = ?"), append(args, *in.Credit)
+	}
+	if in.LimitCount != nil {
+		sets, args = append(sets, "limit_count = ?"), append(args, *in.LimitCount)
+	}
+	if in.DropDeadline != nil {
+		deadline, _ := ParseReleaseAt(*in.DropDeadline)
+		sets, args = append(sets, "drop_deadline = ?"), append(args, deadline)
+	}
+	if in.EnrollMode != nil {
+		sets, args = append(sets, "enroll_mode = ?"), append(args, *in.EnrollMode)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+	if _, err := db.Exec("UPDATE courses SET "+strings.Join(sets, ", ")+" WHERE id = ?", append(args, courseID)...); err != nil {
+		return err
+	}
+	if in.Title != nil || in.Description != nil {
+		if err := reindexCourse(db, courseID); err != nil {
+			return err
+		}
+	}
+	// 提高人数限制后，新增的名额依次发给候补学生
+	if in.LimitCount != nil {
+		if _, err := OfferSeats(db, courseID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 校验课程字段
+func (in CourseInput) validate() error {
+	if in.Title != nil {
+		title := strings.TrimSpace(*in.Title)
+		if title == "" {
+			return InputError("课程标题不能为空")
+		}
+		if utf8.RuneCountInString(title) > 255 {
+			return InputError("课程标题不能超过255个字符")
+		}
+	}
+	if in.Cover != nil && len(*in.Cover) > 500 {
+		return InputError("封面地址不能超过500个字符")
+	}
+	// credit 列为 DECIMAL(3,1)
+	if in.Credit != nil && (*in.Credit < 0 || *in.Credit > 99.9) {
+		return InputError("学分必须在0到99.9之间")
+	}
+	if in.LimitCount != nil && *in.LimitCount <= 0 {
+		return InputError("限制人数必须大于0")
+	}
+	if in.DropDeadline != nil {
+		if _, err := ParseReleaseAt(*in.DropDeadline); err != nil {
+			return InputError("无效的退课截止时间，例如 2024-09-16 23:59")
+		}
+	}
+	if in.EnrollMode != nil {
+		switch *in.EnrollMode {
+		case EnrollModeOpen, EnrollModeApproval, EnrollModeInvite:
+		default:
+			return InputError("选课方式只能是 open、approval 或 invite")
+		}
+	}
+	return nil
+}
+
+// DeleteCourse 删除课程及其章节和课时；已有学生选修的课程不能删除
+// 课时关联的视频不会被删除
+func DeleteCourse(db *sql.DB, teacherID, courseID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkCourseTeacher(tx, teacherID, courseID, PermManage, true); err != nil {
+		return err
+	}
+	var enrolled bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM student_courses WHERE course_id = ?)", courseID).Scan(&enrolled); err != nil {
+		return err
+	}
+	if enrolled {
+		return ErrCourseHasStudents
+	}
+
+	for _, stmt := range []string{
+		"DELETE cc FROM chapter_children cc JOIN chapters ch ON cc.chapter_id = ch.id WHERE ch.course_id = ?",
+		"DELETE FROM chapters WHERE course_id = ?",
+		"DELETE FROM teacher_courses WHERE course_id = ?",
+		"DELETE FROM courses WHERE id = ?",
+	} {
+		if _, err := tx.Exec(stmt, courseID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// CreateChapter 在课程末尾添加章节
+func CreateChapter(db *sql.DB, teacherID, courseID int, title string) (int64, error) {
+	title, err := chapterTitle(title)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkCourseTeacher(tx, teacherID, courseID, PermContent, true); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`
+		INSERT INTO chapters (course_id, title, state, sort_order)
+		SELECT ?, ?, 0, COALESCE(MAX(sort_order), 0) + 1 FROM chapters WHERE course_id = ?
+	`, courseID, title, courseID)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	if err := reindexCourse(tx, courseID); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// DeleteChapter 删除章节及其课时
+func DeleteChapter(db *sql.DB, teacherID, chapterID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	courseID, err := chapterCourse(tx, teacherID, chapterID, true)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM chapter_children WHERE chapter_id = ?", chapterID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM chapters WHERE id = ?", chapterID); err != nil {
+		return err
+	}
+	if err := syncLessonNum(tx, courseID); err != nil {
+		return err
+	}
+	if err := reindexCourse(tx, courseID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReorderChapters 按 ids 的顺序排列课程的章节，ids 必须恰好包含课程的全部章节
+func ReorderChapters(db *sql.DB, teacherID, courseID int, ids []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkCourseTeacher(tx, teacherID, courseID, PermContent, true); err != nil {
+		return err
+	}
+	if err := reorder(tx, "chapters", "course_id", courseID, ids); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateLesson 在章节末尾添加课时
+func CreateLesson(db *sql.DB, teacherID, chapterID int, in LessonInput) (int64, error) {
+	if in.Title == nil {
+		return 0, InputError("课时标题不能为空")
+	}
+	title, err := lessonTitle(*in.Title)
+	if err != nil {
+		return 0, err
+	}
+	var pdfURL interface{}
+	if in.PdfURL != nil && *in.PdfURL != "" {
+		normalized, err := NormalizePdfURL(*in.PdfURL)
+		if err != nil {
+			return 0, err
+		}
+		pdfURL = normalized
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	courseID, err := chapterCourse(tx, teacherID, chapterID, true)
+	if err != nil {
+		return 0, err
+	}
+	var videoID interface{}
+	if in.VideoID != nil && *in.VideoID != 0 {
+		if err := checkAttachableVideo(tx, teacherID, *in.VideoID); err != nil {
+			return 0, err
+		}
+		videoID = *in.VideoID
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO chapter_children (chapter_id, title, video_id, pdf_url, sort_order)
+		SELECT ?, ?, ?, ?, COALESCE(MAX(sort_order), 0) + 1 FROM chapter_children WHERE chapter_id = ?
+	`, chapterID, title, videoID, pdfURL, chapterID)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	if err := syncLessonNum(tx, courseID); err != nil {
+		return 0, err
+	}
+	if err := reindexCourse(tx, courseID); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// UpdateLesson 修改课时：标题、关联的视频和PDF，或移动到同一课程的其他章节（放在末尾）
+func UpdateLesson(db *sql.DB, teacherID, lessonID int, in LessonInput) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	courseID, chapterID, err := lessonCourse(tx, teacherID, lessonID, true)
+	if err != nil {
+		return err
+	}
+
+	var sets []string
+	var args []interface{}
+	if in.Title != nil {
+		title, err := lessonTitle(*in.Title)
+		if err != nil {
+			return err
+		}
+		sets, args = append(sets, "title = ?"), append(args, title)
+	}
+	if in.VideoID != nil {
+		var videoID interface{}
+		if *in.VideoID != 0 {
+			if err := checkAttachableVideo(tx, teacherID, *in.VideoID); err != nil {
+				return err
+			}
+			videoID = *in.VideoID
+		}
+		sets, args = append(sets, "video_id = ?"), append(args, videoID)
+	}
+	if in.PdfURL != nil {
+		var pdfURL interface{}
+		if *in.PdfURL != "" {
+			normalized, err := NormalizePdfURL(*in.PdfURL)
+			if err != nil {
+				return err
+			}
+			pdfURL = normalized
+		}
+		sets, args = append(sets, "pdf_url = ?"), append(args, pdfURL)
+	}
+	if in.ChapterID != nil && *in.ChapterID != chapterID {
+		var targetCourse int
+		err := tx.QueryRow("SELECT course_id FROM chapters WHERE id = ?", *in.ChapterID).Scan(&targetCourse)
+		if err == sql.ErrNoRows || (err == nil && targetCourse != courseID) {
+			return InputError("只能移动到同一课程的其他章节")
+		}
+		if err != nil {
+			return err
+		}
+		var order int
+		if err := tx.QueryRow(
+			"SELECT COALESCE(MAX(sort_order), 0) + 1 FROM chapter_children WHERE chapter_id = ?", *in.ChapterID,
+		).Scan(&order); err != nil {
+			return err
+		}
+		sets, args = append(sets, "chapter_id = ?", "sort_order = ?"), append(args, *in.ChapterID, order)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec("UPDATE chapter_children SET "+strings.Join(sets, ", ")+" WHERE id = ?", append(args, lessonID)...); err != nil {
+		return err
+	}
+	if in.Title != nil {
+		if err := reindexCourse(tx, courseID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteLesson 删除课时（关联的视频不会被删除）
+func DeleteLesson(db *sql.DB, teacherID, lessonID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	courseID, _, err := lessonCourse(tx, teacherID, lessonID, true)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM chapter_children WHERE id = ?", lessonID); err != nil {
+		return err
+	}
+	if err := syncLessonNum(tx, courseID); err != nil {
+		return err
+	}
+	if err := reindexCourse(tx, courseID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReorderLessons 按 ids 的顺序排列章节中的课时，ids 必须恰好包含章节的全部课时
+func ReorderLessons(db *sql.DB, teacherID, chapterID int, ids []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := chapterCourse(tx, teacherID, chapterID, true); err != nil {
+		return err
+	}
+	if err := reorder(tx, "chapter_children", "chapter_id", chapterID, ids); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// LessonCourse 查询课时所属的课程并检查教师权限
+func LessonCourse(db *sql.DB, teacherID, lessonID int) (int, error) {
+	courseID, _, err := lessonCourse(db, teacherID, lessonID, false)
+	return courseID, err
+}
+
+// chapterCourse 查询章节所属课程并检查教师权限
+func chapterCourse(q queryExecer, teacherID, chapterID int, lock bool) (int, error) {
+	var courseID int
+	err := q.QueryRow("SELECT course_id FROM chapters WHERE id = ?", chapterID).Scan(&courseID)
+	if err == sql.ErrNoRows {
+		return 0, ErrChapterNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return courseID, checkCourseTeacher(q, teacherID, courseID, PermContent, lock)
+}
+
+// lessonCourse 查询课时所属课程和章节并检查教师权限
+func lessonCourse(q queryExecer, teacherID, lessonID int, lock bool) (courseID, chapterID int, err error) {
+	err = q.QueryRow(`
+		SELECT ch.course_id, ch.id FROM chapter_children cc
+		JOIN chapters ch ON cc.chapter_id = ch.id
+		WHERE cc.id = ?
+	`, lessonID).Scan(&courseID, &chapterID)
+	if err == sql.ErrNoRows {
+		return 0, 0, ErrLessonNotFound
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return courseID, chapterID, checkCourseTeacher(q, teacherID, courseID, PermContent, lock)
+}
+
+// checkAttachableVideo 教师只能使用自己上传的视频，或已经用在自己课程中的视频
+func checkAttachableVideo(q queryExecer, teacherID, videoID int) error {
+	var ok bool
+	err := q.QueryRow(`
+		SELECT v.teacher_id <=> ? OR EXISTS(
+			SELECT 1 FROM chapter_children cc
+			JOIN chapters ch ON cc.chapter_id = ch.id
+			JOIN teacher_courses tc ON tc.course_id = ch.course_id
+			WHERE cc.video_id = v.id AND tc.teacher_id = ?
+		)
+		FROM videos v WHERE v.id = ?
+	`, teacherID, teacherID, videoID).Scan(&ok)
+	if err == sql.ErrNoRows || (err == nil && !ok) {
+		return ErrVideoNotAttachable
+	}
+	return err
+}
+
+// reorder 按 ids 的顺序重写 sort_order
+func reorder(tx *sql.Tx, table, parentColumn string, parentID int, ids []int) error {
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE "+parentColumn+" = ?", parentID).Scan(&count); err != nil {
+		return err
+	}
+	if len(ids) != count {
+		return ErrBadOrder
+	}
+	seen := make(map[int]bool, len(ids))
+	for i, id := range ids {
+		if seen[id] {
+			return ErrBadOrder
+		}
+		seen[id] = true
+		result, err := tx.Exec("UPDATE "+table+" SET sort_order = ? WHERE id = ? AND "+parentColumn+" = ?", i+1, id, parentID)
+		if err != nil {
+			return err
+		}
+		// 顺序未变化时影响行数为0，需要再确认记录是否属于该章节/课程
+		if n, _ := result.RowsAffected(); n == 0 {
+			var exists bool
+			if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM "+table+" WHERE id = ? AND "+parentColumn+" = ?)", id, parentID).Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				return ErrBadOrder
+			}
+		}
+	}
+	return nil
+}
+
+// syncLessonNum 根据课时数量更新课程的 lesson_num
+func syncLessonNum(q queryExecer, courseID int) error {
+	_, err := q.Exec(`
+		UPDATE courses SET lesson_num = (
+			SELECT COUNT(*) FROM chapter_children cc
+			JOIN chapters ch ON cc.chapter_id = ch.id
+			WHERE ch.course_id = ?
+		) WHERE id = ?
+	`, courseID, courseID)
+	return err
+}
+
+// NormalizePdfURL 规范化课时的PDF地址：文件名或 /api/pdfs/ 下的路径统一为 /api/pdfs/...，也可以是外部 http(s) 地址
+func NormalizePdfURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.EqualFold(path.Ext(raw), ".pdf") || len(raw) > 500 {
+		return "", InputError("PDF地址必须以 .pdf 结尾")
+	}
+	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+		return raw, nil
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(raw, "/api/pdfs/"), "/")
+	if name == "" || strings.Contains(name, "\\") || path.Clean(name) != name || strings.HasPrefix(name, "../") {
+		return "", InputError("无效的PDF地址")
+	}
+	return "/api/pdfs/" + name, nil
+}
+
+func chapterTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", InputError("章节标题不能为空")
+	}
+	if utf8.RuneCountInString(title) > 100 {
+		return "", InputError("章节标题不能超过100个字符")
+	}
+	return title, nil
+}
+
+func lessonTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", InputError("课时标题不能为空")
+	}
+	if utf8.RuneCountInString(title) > 100 {
+		return "", InputError("课时标题不能超过100个字符")
+	}
+	return title, nil
+}
@@ -0,0 +1,124 @@
+// internal/ops/catalog.go
+package ops
+
+import (
+	"strings"
+	"time"
+)
+
+// 课程列表排序方式（GetCourseList 的 order 参数）
+const (
+	SortDefault = 0 // 默认：搜索时按相关度，否则按最新
+	SortNewest  = 1 // 最新
+	SortPopular = 2 // 最热：选课人数
+	SortRating  = 3 // 评分
+	SortActive  = 4 // 最近有学生学习或选课
+	SortCredit  = 5 // 学分
+)
+
+// enrollmentJoin 各课程的选课人数（不含已退课）和最近一次选课时间，别名 e
+const enrollmentJoin = ` LEFT JOIN (
+	SELECT course_id, COUNT(*) AS enrolled, MAX(joined_at) AS last_joined
+	FROM student_courses WHERE status <> 'dropped' GROUP BY course_id
+) e ON e.course_id = c.id`
+
+// activityJoin 各课程最近一次学习时间，别名 a
+const activityJoin = ` LEFT JOIN (
+	SELECT course_id, MAX(updated_at) AS last_active
+	FROM lesson_progress GROUP BY course_id
+) a ON a.course_id = c.id`
+
+// CourseListFilter 课程列表的筛选和排序条件，课程表别名为 c
+type CourseListFilter struct {
+	SubjectIDs []int // 分类及其子分类，为空表示不限
+	TeacherID  int
+	CreditMin  *float64
+	CreditMax  *float64
+	Available  bool      // 只看还有剩余名额的课程
+	Now        time.Time // 判断候补名额是否过期
+	StuID      string    // 当前登录的学生，用于返回和筛选选课状态
+	Enrolled   *bool     // true 只看已选的课程，false 只看未选的，需要 StuID
+	Sort       int
+}
+
+// CourseListSQL 由筛选条件生成的查询片段
+// Columns 依次为 选课人数、已占用名额（含未过期的候补名额）、评分、评分人数、1星到5星的评分人数，StuID 不为空时还有 是否已选
+type CourseListSQL struct {
+	Columns    string
+	ColumnArgs []interface{}
+	Joins      string
+	Where      []string
+	WhereArgs  []interface{}
+	OrderBy    string
+}
+
+// SQL 生成查询片段，调用方负责拼装 SELECT 和 FROM courses c
+func (f CourseListFilter) SQL() CourseListSQL {
+	taken := "(" + seatsTakenSQL("c.id") + ")"
+	takenArgs := []interface{}{WaitlistOffered, f.Now, ""}
+	q := CourseListSQL{
+		Columns:    ", COALESCE(e.enrolled, 0), " + taken + ", c.rating_avg, c.rating_count, c.rating_1, c.rating_2, c.rating_3, c.rating_4, c.rating_5",
+		ColumnArgs: append([]interface{}(nil), takenArgs...),
+		Joins:      enrollmentJoin,
+	}
+	if f.StuID != "" {
+		q.Columns += ", EXISTS(SELECT 1 FROM student_courses sc WHERE sc.course_id = c.id AND sc.stuId = ? AND sc.status <> 'dropped')"
+		q.ColumnArgs = append(q.ColumnArgs, f.StuID)
+	}
+
+	if len(f.SubjectIDs) > 0 {
+		q.Where = append(q.Where, "c.id IN (SELECT course_id FROM course_subjects WHERE subject_id IN (?"+strings.Repeat(", ?", len(f.SubjectIDs)-1)+"))")
+		for _, id := range f.SubjectIDs {
+			q.WhereArgs = append(q.WhereArgs, id)
+		}
+	}
+	if f.TeacherID > 0 {
+		q.Where = append(q.Where, "c.id IN (SELECT course_id FROM teacher_courses WHERE teacher_id = ?)")
+		q.WhereArgs = append(q.WhereArgs, f.TeacherID)
+	}
+	if f.CreditMin != nil {
+		q.Where = append(q.Where, "c.credit >= ?")
+		q.WhereArgs = append(q.WhereArgs, *f.CreditMin)
+	}
+	if f.CreditMax != nil {
+		q.Where = append(q.Where, "c.credit <= ?")
+		q.WhereArgs = append(q.WhereArgs, *f.CreditMax)
+	}
+	if f.Available {
+		q.Where = append(q.Where, "c.limit_count > "+taken)
+		q.WhereArgs = append(q.WhereArgs, takenArgs...)
+	}
+	if f.StuID != "" && f.Enrolled != nil {
+		cond := "EXISTS(SELECT 1 FROM student_courses sc WHERE sc.course_id = c.id AND sc.stuId = ? AND sc.status <> 'dropped')"
+		if !*f.Enrolled {
+			cond = "NOT " + cond
+		}
+		q.Where = append(q.Where, cond)
+		q.WhereArgs = append(q.WhereArgs, f.StuID)
+	}
+
+	switch f.Sort {
+	case SortPopular:
+		q.OrderBy = "COALESCE(e.enrolled, 0) DESC, c.id DESC"
+	case SortRating:
+		q.OrderBy = "c.rating_avg DESC, c.rating_count DESC, c.id DESC"
+	case SortActive:
+		// 最近学习和最近选课中较晚的一个，都没有的课程排在最后
+		q.Joins += activityJoin
+		q.OrderBy = "COALESCE(GREATEST(a.last_active, e.last_joined), a.last_active, e.last_joined) IS NULL, " +
+			"COALESCE(GREATEST(a.last_active, e.last_joined), a.last_active, e.last_joined) DESC, c.id DESC"
+	case SortCredit:
+		q.OrderBy = "c.credit DESC, c.id DESC"
+	default:
+		q.OrderBy = "c.id DESC"
+	}
+	return q
+}
+
+// RemainingSeats 剩余名额，不小于0，taken 为已占用的名额（见 seatsTakenSQL）
+func RemainingSeats(limit, taken int) int {
+	if taken >= limit {
+		return 0
+	}
+	return limit - taken
+}
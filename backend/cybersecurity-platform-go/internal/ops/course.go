@@ -0,0 +1,222 @@
+// internal/ops/course.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCourseNotFound 课程不存在
+var ErrCourseNotFound = errors.New("课程不存在")
+
+// CourseBundle 课程导入导出格式（JSON）
+// 导出时不包含数据库ID，导入时总是创建新课程，便于在不同环境之间迁移
+type CourseBundle struct {
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Cover       string          `json:"cover"`
+	Credit      float64         `json:"credit"`
+	LimitCount  int             `json:"limitCount"`
+	Teachers    []string        `json:"teachers"` // 教师姓名，导入时按姓名匹配已有教师
+	Chapters    []ChapterBundle `json:"chapters"`
+}
+
+// ChapterBundle 章节
+type ChapterBundle struct {
+	Title     string         `json:"title"`
+	State     int            `json:"state"`
+	ReleaseAt *time.Time     `json:"releaseAt,omitempty"` // 章节开放时间
+	Lessons   []LessonBundle `json:"lessons"`
+}
+
+// LessonBundle 课时
+type LessonBundle struct {
+	Title            string `json:"title"`
+	VideoURL         string `json:"videoUrl,omitempty"`
+	VideoDescription string `json:"videoDescription,omitempty"`
+	VideoDuration    int    `json:"videoDuration,omitempty"`
+	PdfURL           string `json:"pdfUrl,omitempty"`
+}
+
+// ExportCourse 导出课程及其章节、课时
+func ExportCourse(db *sql.DB, courseID int) (*CourseBundle, error) {
+	var b CourseBundle
+	var description, cover sql.NullString
+	err := db.QueryRow(
+		"SELECT title, description, cover, credit, limit_count FROM courses WHERE id = ?", courseID,
+	).Scan(&b.Title, &description, &cover, &b.Credit, &b.LimitCount)
+	if err == sql.ErrNoRows {
+		return nil, ErrCourseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.Description = description.String
+	b.Cover = cover.String
+
+	rows, err := db.Query(`
+		SELECT t.name FROM teacher_courses tc
+		JOIN teachers t ON tc.teacher_id = t.id
+		WHERE tc.course_id = ?
+		ORDER BY FIELD(tc.role, 'lead', 'co_teacher', 'ta'), tc.sort_order, tc.id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	b.Teachers = []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.Teachers = append(b.Teachers, name)
+	}
+	rows.Close()
+
+	rows, err = db.Query(`
+		SELECT ch.id, ch.title, ch.state, ch.release_at, cc.title, v.url, v.description, v.duration, cc.pdf_url
+		FROM chapters ch
+		LEFT JOIN chapter_children cc ON ch.id = cc.chapter_id
+		LEFT JOIN videos v ON cc.video_id = v.id
+		WHERE ch.course_id = ?
+		ORDER BY ch.sort_order, ch.id, cc.sort_order, cc.id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	b.Chapters = []ChapterBundle{}
+	lastChapterID := -1
+	for rows.Next() {
+		var chapterID, state int
+		var chapterTitle string
+		var lessonTitle, videoURL, videoDesc, pdfURL sql.NullString
+		var duration sql.NullInt64
+		var releaseAt sql.NullTime
+		if err := rows.Scan(&chapterID, &chapterTitle, &state, &releaseAt, &lessonTitle, &videoURL, &videoDesc, &duration, &pdfURL); err != nil {
+			return nil, err
+		}
+
+		if chapterID != lastChapterID {
+			ch := ChapterBundle{Title: chapterTitle, State: state, Lessons: []LessonBundle{}}
+			if releaseAt.Valid {
+				ch.ReleaseAt = &releaseAt.Time
+			}
+			b.Chapters = append(b.Chapters, ch)
+			lastChapterID = chapterID
+		}
+		if lessonTitle.Valid {
+			ch := &b.Chapters[len(b.Chapters)-1]
+			ch.Lessons = append(ch.Lessons, LessonBundle{
+				Title:            lessonTitle.String,
+				VideoURL:         videoURL.String,
+				VideoDescription: videoDesc.String,
+				VideoDuration:    int(duration.Int64),
+				PdfURL:           pdfURL.String,
+			})
+		}
+	}
+	return &b, rows.Err()
+}
+
+// ImportCourse 在一个事务中导入课程，返回新课程ID
+// 教师按姓名匹配，不存在的教师会被创建；lesson_num 根据课时数量计算
+func ImportCourse(db *sql.DB, b *CourseBundle) (int64, error) {
+	if b.Title == "" {
+		return 0, errors.New("课程标题不能为空")
+	}
+	if b.LimitCount <= 0 {
+		b.LimitCount = 100
+	}
+
+	lessonNum := 0
+	for _, ch := range b.Chapters {
+		lessonNum += len(ch.Lessons)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO courses (title, description, cover, lesson_num, credit, limit_count) VALUES (?, ?, ?, ?, ?, ?)",
+		b.Title, b.Description, b.Cover, lessonNum, b.Credit, b.LimitCount,
+	)
+	if err != nil {
+		return 0, err
+	}
+	courseID, _ := result.LastInsertId()
+
+	// 第一名教师为负责人，其余为合作教师，保持导出时的顺序
+	for i, name := range b.Teachers {
+		var teacherID int64
+		err := tx.QueryRow("SELECT id FROM teachers WHERE name = ?", name).Scan(&teacherID)
+		if err == sql.ErrNoRows {
+			res, insertErr := tx.Exec("INSERT INTO teachers (name, career, intro) VALUES (?, '', '')", name)
+			if insertErr != nil {
+				return 0, fmt.Errorf("创建教师 %s 失败: %v", name, insertErr)
+			}
+			teacherID, _ = res.LastInsertId()
+		} else if err != nil {
+			return 0, err
+		}
+
+		role := RoleCoTeacher
+		if i == 0 {
+			role = RoleLead
+		}
+		if _, err := tx.Exec("INSERT INTO teacher_courses (teacher_id, course_id, role, sort_order) VALUES (?, ?, ?, ?)", teacherID, courseID, role, i); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, ch := range b.Chapters {
+		res, err := tx.Exec(
+			"INSERT INTO chapters (course_id, title, state, sort_order, release_at) VALUES (?, ?, ?, ?, ?)",
+			courseID, ch.Title, ch.State, i+1, ch.ReleaseAt,
+		)
+		if err != nil {
+			return 0, err
+		}
+		chapterID, _ := res.LastInsertId()
+
+		for j, l := range ch.Lessons {
+			var videoID, pdfURL interface{}
+			if l.PdfURL != "" {
+				pdfURL = l.PdfURL
+			}
+			if l.VideoURL != "" {
+				res, err := tx.Exec(
+					"INSERT INTO videos (url, description, duration) VALUES (?, ?, ?)",
+					l.VideoURL, l.VideoDescription, l.VideoDuration,
+				)
+				if err != nil {
+					return 0, err
+				}
+				id, _ := res.LastInsertId()
+				videoID = id
+			}
+
+			if _, err := tx.Exec(
+				"INSERT INTO chapter_children (chapter_id, title, video_id, pdf_url, sort_order) VALUES (?, ?, ?, ?, ?)",
+				chapterID, l.Title, videoID, pdfURL, j+1,
+			); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := reindexCourse(tx, int(courseID)); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return courseID, nil
+}
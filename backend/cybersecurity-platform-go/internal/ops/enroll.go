@@ -0,0 +1,130 @@
+// internal/ops/enroll.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// 课程的选课方式
+const (
+	EnrollModeOpen     = "open"     // 学生自由加入
+	EnrollModeApproval = "approval" // 学生提交申请，教师审核通过后加入
+	EnrollModeInvite   = "invite"   // 学生凭教师发放的邀请码加入
+)
+
+var (
+	// ErrCourseFull 课程人数已满
+	ErrCourseFull = errors.New("课程人数已满")
+	// ErrAlreadyEnrolled 已加入该课程
+	ErrAlreadyEnrolled = errors.New("已加入该课程")
+)
+
+// EnrollOptions 学生选课时提交的信息
+type EnrollOptions struct {
+	InviteCode string // 邀请码，凭邀请码加入的课程需要
+	Message    string // 申请说明，需要审核的课程使用
+}
+
+// mysqlDuplicateEntry 违反唯一键（unique_student_course）
+const mysqlDuplicateEntry = 1062
+
+// Enroll 学生加入课程，需要审核的课程提交选课申请并返回 pending 为 true
+// 在事务中锁定课程行，同一课程的选课串行执行，人数检查和插入之间不会有其他学生加入；
+// 重复提交（客户端重试）返回 ErrAlreadyEnrolled，不会产生重复记录。
+// 发给候补学生的名额在有效期内为其保留，其他学生不能占用
+func Enroll(db *sql.DB, stuID string, courseID int, opts EnrollOptions) (pending bool, err error) {
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)", stuID).Scan(&exists); err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, ErrUserNotFound
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// 只有已发布的课程可以加入，草稿、审核中和已归档的课程对学生视为不存在
+	limit, mode, err := lockEnrollCourse(tx, courseID)
+	if err != nil {
+		return false, err
+	}
+
+	// 在修或已结课时不能重复加入；已退课、未通过的学生可以重新选课
+	from, err := enrollmentStatus(tx, stuID, courseID)
+	if err != nil {
+		return false, err
+	}
+	if from == EnrollActive || from == EnrollCompleted {
+		return false, ErrAlreadyEnrolled
+	}
+	// 提交申请和直接加入都需要先完成先修课程；教师加入学生、导入名单不检查
+	if err := checkEnrollPrerequisites(tx, stuID, courseID); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	// 需要审核的课程只记录申请，人数在教师审核时检查
+	if mode == EnrollModeApproval {
+		if err := requestEnrollment(tx, stuID, courseID, opts.Message, now); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+	var invite *courseInvite
+	if mode == EnrollModeInvite {
+		if invite, err = lockInvite(tx, courseID, opts.InviteCode, now); err != nil {
+			return false, err
+		}
+	}
+
+	// 空出的名额先发给候补学生；发给该学生本人的名额不算占用
+	if _, err := refreshWaitlist(tx, courseID, limit, now); err != nil {
+		return false, err
+	}
+	taken, err := seatsTaken(tx, courseID, stuID, now)
+	if err != nil {
+		return false, err
+	}
+	if taken >= limit {
+		return false, ErrCourseFull
+	}
+
+	reason := ""
+	if invite != nil {
+		// 人数已满时不消耗邀请码的使用次数
+		if _, err := tx.Exec("UPDATE course_invites SET used = used + 1 WHERE id = ?", invite.id); err != nil {
+			return false, err
+		}
+		reason = "邀请码 " + invite.code
+	}
+	if err := activateEnrollment(tx, stuID, courseID, from, ActorStudent, reason, now); err != nil {
+		return false, err
+	}
+	return false, tx.Commit()
+}
+
+// lockEnrollCourse 锁定已发布的课程行，返回人数限制和选课方式
+func lockEnrollCourse(tx *sql.Tx, courseID int) (int, string, error) {
+	var limit int
+	var mode string
+	err := tx.QueryRow("SELECT limit_count, enroll_mode FROM courses WHERE id = ? AND status = 'published' FOR UPDATE", courseID).
+		Scan(&limit, &mode)
+	if err == sql.ErrNoRows {
+		return 0, "", ErrCourseNotFound
+	}
+	return limit, mode, err
+}
+
+// isDuplicateEntry 是否为唯一键冲突
+func isDuplicateEntry(err error) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == mysqlDuplicateEntry
+}
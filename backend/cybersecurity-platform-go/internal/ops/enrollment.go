@@ -0,0 +1,382 @@
+// internal/ops/enrollment.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 选课状态
+const (
+	EnrollActive    = "active"    // 在修
+	EnrollDropped   = "dropped"   // 已退课（学生退课或被教师移出）
+	EnrollCompleted = "completed" // 已结课
+	EnrollFailed    = "failed"    // 未通过
+)
+
+// 选课记录变更的操作者
+const (
+	ActorStudent = "student"
+	ActorAdmin   = "admin"
+)
+
+// EnrollDropWindow 课程没有设置退课截止时间时，学生在加入后多长时间内可以退课，0 表示不限制
+// 服务启动时根据配置设置
+var EnrollDropWindow = 14 * 24 * time.Hour
+
+var (
+	// ErrNotEnrolled 未选修该课程
+	ErrNotEnrolled = errors.New("未选修该课程")
+	// ErrDropDeadlinePassed 已超过退课截止时间
+	ErrDropDeadlinePassed = errors.New("已超过退课截止时间")
+)
+
+// Enrollment 学生的一条选课记录
+type Enrollment struct {
+	StuID        string     `json:"stuId"`
+	CourseID     int        `json:"courseId"`
+	Status       string     `json:"status"`
+	JoinedAt     time.Time  `json:"joinedAt"`
+	EndedAt      *time.Time `json:"endedAt"`
+	DropDeadline *time.Time `json:"dropDeadline"` // 学生可以退课的截止时间，为空表示不限制
+}
+
+// CanDrop 学生现在是否可以退课
+func (e *Enrollment) CanDrop(now time.Time) bool {
+	return e.Status == EnrollActive && (e.DropDeadline == nil || !now.After(*e.DropDeadline))
+}
+
+// EnrollmentEvent 选课记录的一次状态变更
+type EnrollmentEvent struct {
+	ID          int64     `json:"id"`
+	StuID       string    `json:"stuId"`
+	CourseID    int       `json:"courseId"`
+	CourseTitle string    `json:"courseTitle"`
+	From        string    `json:"from"` // 为空表示首次加入
+	To          string    `json:"to"`
+	Actor       string    `json:"actor"` // student、admin 或 teacher:<教师ID>
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// RosterEntry 课程名单中的学生
+type RosterEntry struct {
+	StuID    string     `json:"stuId"`
+	NickName string     `json:"nickName"`
+	Email    string     `json:"email"`
+	Status   string     `json:"status"`
+	JoinedAt time.Time  `json:"joinedAt"`
+	EndedAt  *time.Time `json:"endedAt"`
+}
+
+// DropDeadline 学生退课的截止时间：课程设置的截止时间优先，否则为加入后 EnrollDropWindow，都没有时返回 nil
+func DropDeadline(courseDeadline *time.Time, joinedAt time.Time) *time.Time {
+	if courseDeadline != nil {
+		return courseDeadline
+	}
+	if EnrollDropWindow <= 0 {
+		return nil
+	}
+	t := joinedAt.Add(EnrollDropWindow)
+	return &t
+}
+
+// teacherActor 操作者标识，teacherID 为0表示管理员
+func teacherActor(teacherID int) string {
+	if teacherID == 0 {
+		return ActorAdmin
+	}
+	return fmt.Sprintf("teacher:%d", teacherID)
+}
+
+// recordEnrollment 记录选课状态变更
+func recordEnrollment(q queryExecer, stuID string, courseID int, from, to, actor, reason string, at time.Time) error {
+	_, err := q.Exec(
+		"INSERT INTO enrollment_events (stuId, course_id, from_status, to_status, actor, reason, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		stuID, courseID, from, to, actor, reason, at,
+	)
+	return err
+}
+
+// enrollmentStatus 锁定并读取选课记录的状态，没有记录时返回空字符串
+func enrollmentStatus(tx *sql.Tx, stuID string, courseID int) (string, error) {
+	var status string
+	err := tx.QueryRow(
+		"SELECT status FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE", stuID, courseID,
+	).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return status, err
+}
+
+// activateEnrollment 新建或恢复在修的选课记录（已退课、未通过的学生重新选课），并结束该学生的候补和选课申请
+func activateEnrollment(tx *sql.Tx, stuID string, courseID int, from, actor, reason string, now time.Time) error {
+	if from == "" {
+		if _, err := tx.Exec(
+			"INSERT INTO student_courses (stuId, course_id, status, joined_at) VALUES (?, ?, ?, ?)",
+			stuID, courseID, EnrollActive, now,
+		); err != nil {
+			if isDuplicateEntry(err) {
+				return ErrAlreadyEnrolled
+			}
+			return err
+		}
+	} else if _, err := tx.Exec(
+		"UPDATE student_courses SET status = ?, joined_at = ?, ended_at = NULL WHERE stuId = ? AND course_id = ?",
+		EnrollActive, now, stuID, courseID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		"UPDATE course_waitlist SET status = ? WHERE course_id = ? AND stuId = ? AND status IN (?, ?)",
+		WaitlistEnrolled, courseID, stuID, WaitlistWaiting, WaitlistOffered,
+	); err != nil {
+		return err
+	}
+	// 教师直接加入或导入名单时，学生待审核的申请一并视为通过
+	if _, err := tx.Exec(
+		"UPDATE enrollment_requests SET status = ?, reviewer = ?, reviewed_at = ? WHERE course_id = ? AND stuId = ? AND status = ?",
+		RequestApproved, actor, now, courseID, stuID, RequestPending,
+	); err != nil {
+		return err
+	}
+	return recordEnrollment(tx, stuID, courseID, from, EnrollActive, actor, reason, now)
+}
+
+// StudentEnrollment 学生在课程中的选课记录，没有记录时返回 nil
+func StudentEnrollment(db *sql.DB, stuID string, courseID int) (*Enrollment, error) {
+	e := Enrollment{StuID: stuID, CourseID: courseID}
+	var ended, deadline sql.NullTime
+	err := db.QueryRow(`
+		SELECT sc.status, sc.joined_at, sc.ended_at, c.drop_deadline
+		FROM student_courses sc JOIN courses c ON sc.course_id = c.id
+		WHERE sc.stuId = ? AND sc.course_id = ?
+	`, stuID, courseID).Scan(&e.Status, &e.JoinedAt, &ended, &deadline)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ended.Valid {
+		e.EndedAt = &ended.Time
+	}
+	if e.Status == EnrollActive {
+		e.DropDeadline = DropDeadline(nullTime(deadline), e.JoinedAt)
+	}
+	return &e, nil
+}
+
+// DropCourse 学生退课，需在退课截止时间之前；空出的名额发给候补学生
+func DropCourse(db *sql.DB, stuID string, courseID int, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > 500 {
+		return InputError("退课原因不能超过500个字符")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var limit int
+	var deadline sql.NullTime
+	err = tx.QueryRow("SELECT status, limit_count, drop_deadline FROM courses WHERE id = ? FOR UPDATE", courseID).
+		Scan(&status, &limit, &deadline)
+	if err == sql.ErrNoRows {
+		return ErrCourseNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var from string
+	var joinedAt time.Time
+	err = tx.QueryRow(
+		"SELECT status, joined_at FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE", stuID, courseID,
+	).Scan(&from, &joinedAt)
+	if err == sql.ErrNoRows || (err == nil && from != EnrollActive) {
+		return ErrNotEnrolled
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if d := DropDeadline(nullTime(deadline), joinedAt); d != nil && now.After(*d) {
+		return ErrDropDeadlinePassed
+	}
+
+	if err := endEnrollment(tx, stuID, courseID, from, EnrollDropped, ActorStudent, reason, now); err != nil {
+		return err
+	}
+	if status == CoursePublished {
+		if _, err := refreshWaitlist(tx, courseID, limit, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// endEnrollment 将选课记录改为已退课、已结课或未通过
+func endEnrollment(tx *sql.Tx, stuID string, courseID int, from, to, actor, reason string, now time.Time) error {
+	if _, err := tx.Exec(
+		"UPDATE student_courses SET status = ?, ended_at = ? WHERE stuId = ? AND course_id = ?",
+		to, now, stuID, courseID,
+	); err != nil {
+		return err
+	}
+	return recordEnrollment(tx, stuID, courseID, from, to, actor, reason, now)
+}
+
+// SetEnrollment 教师或管理员（teacherID 为0）修改学生的选课状态：
+// active 将学生加入课程（不受人数限制和课程状态限制），dropped 将学生移出课程，completed、failed 记录结课结果
+func SetEnrollment(db *sql.DB, teacherID, courseID int, stuID, to, reason string) error {
+	switch to {
+	case EnrollActive, EnrollDropped, EnrollCompleted, EnrollFailed:
+	default:
+		return InputError("status 只能是 active、dropped、completed 或 failed")
+	}
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > 500 {
+		return InputError("原因不能超过500个字符")
+	}
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)", stuID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var limit int
+	err = tx.QueryRow("SELECT status, limit_count FROM courses WHERE id = ? FOR UPDATE", courseID).Scan(&status, &limit)
+	if err == sql.ErrNoRows {
+		return ErrCourseNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if teacherID != 0 {
+		if err := checkCourseTeacher(tx, teacherID, courseID, PermStudents, false); err != nil {
+			return err
+		}
+	}
+
+	from, err := enrollmentStatus(tx, stuID, courseID)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return tx.Commit()
+	}
+	now := time.Now()
+	actor := teacherActor(teacherID)
+	switch {
+	case to == EnrollActive:
+		if err := activateEnrollment(tx, stuID, courseID, from, actor, reason, now); err != nil {
+			return err
+		}
+	case from == "" || from == EnrollDropped:
+		return ErrNotEnrolled
+	default:
+		if err := endEnrollment(tx, stuID, courseID, from, to, actor, reason, now); err != nil {
+			return err
+		}
+		// 退课、结课和未通过都会空出名额（见 seatsTakenSQL），依次发给候补学生
+		if status == CoursePublished {
+			if _, err := refreshWaitlist(tx, courseID, limit, now); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// EnrollmentHistory 学生全部选课记录的变更历史（最新的在前）
+func EnrollmentHistory(db *sql.DB, stuID string) ([]EnrollmentEvent, error) {
+	rows, err := db.Query(`
+		SELECT e.id, e.stuId, e.course_id, c.title, e.from_status, e.to_status, e.actor, e.reason, e.created_at
+		FROM enrollment_events e
+		JOIN courses c ON e.course_id = c.id
+		WHERE e.stuId = ?
+		ORDER BY e.id DESC
+	`, stuID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []EnrollmentEvent{}
+	for rows.Next() {
+		var e EnrollmentEvent
+		if err := rows.Scan(&e.ID, &e.StuID, &e.CourseID, &e.CourseTitle, &e.From, &e.To, &e.Actor, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// CourseRoster 课程的学生名单，status 为空时返回全部状态
+func CourseRoster(db *sql.DB, teacherID, courseID int, status string) ([]RosterEntry, error) {
+	if err := checkCourseAccess(db, teacherID, courseID, PermStudents); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT sc.stuId, ud.nickName, s.email, sc.status, sc.joined_at, sc.ended_at
+		FROM student_courses sc
+		LEFT JOIN students s ON s.stuId = sc.stuId
+		LEFT JOIN userdetail ud ON ud.stuId = sc.stuId
+		WHERE sc.course_id = ?`
+	args := []interface{}{courseID}
+	if status != "" {
+		query += " AND sc.status = ?"
+		args = append(args, status)
+	}
+	rows, err := db.Query(query+" ORDER BY sc.joined_at, sc.id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []RosterEntry{}
+	for rows.Next() {
+		var e RosterEntry
+		var nickName, email sql.NullString
+		var ended sql.NullTime
+		if err := rows.Scan(&e.StuID, &nickName, &email, &e.Status, &e.JoinedAt, &ended); err != nil {
+			return nil, err
+		}
+		e.NickName = nickName.String
+		e.Email = email.String
+		if ended.Valid {
+			e.EndedAt = &ended.Time
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// nullTime 将可空时间转换为指针
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
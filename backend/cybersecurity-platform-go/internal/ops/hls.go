@@ -0,0 +1,166 @@
+// internal/ops/hls.go
+package ops
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cybersecurity-platform-go/internal/hls"
+	"cybersecurity-platform-go/internal/storage"
+)
+
+// 视频的 HLS 打包状态（videos.hls_status）
+const (
+	HLSProcessing  = "processing"
+	HLSReady       = "ready"
+	HLSFailed      = "failed"
+	HLSUnsupported = "unsupported" // 源文件格式不支持直接封装，且未配置转码程序
+)
+
+// ErrVideoNotFound 视频不存在
+var ErrVideoNotFound = errors.New("视频不存在")
+
+// HLSKeyPrefix 视频的 HLS 文件在存储中的位置
+func HLSKeyPrefix(videoID int64) string {
+	return fmt.Sprintf("%shls/%d/", storage.PrefixVideos, videoID)
+}
+
+// PackageVideoHLS 将视频打包为 HLS，写入存储并更新 videos 表的 hls_status 和 hls_url
+// 源文件先在 dirs（VideoDirs）中查找，找不到时从存储读取；workDir 用于存放打包过程中的临时文件
+func PackageVideoHLS(ctx context.Context, db *sql.DB, store storage.Storage, dirs []string, videoID int64, opts hls.Options, workDir string) error {
+	var videoURL string
+	err := db.QueryRowContext(ctx, "SELECT url FROM videos WHERE id = ?", videoID).Scan(&videoURL)
+	if err == sql.ErrNoRows {
+		return ErrVideoNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE videos SET hls_status = ? WHERE id = ?", HLSProcessing, videoID); err != nil {
+		return err
+	}
+
+	hlsURL, err := packageVideo(ctx, store, dirs, videoID, videoURL, opts, workDir)
+	status := HLSReady
+	switch {
+	case errors.Is(err, hls.ErrUnsupported):
+		status = HLSUnsupported
+	case err != nil:
+		status = HLSFailed
+	}
+	if _, uerr := db.ExecContext(ctx,
+		"UPDATE videos SET hls_status = ?, hls_url = ? WHERE id = ?", status, hlsURL, videoID,
+	); uerr != nil && err == nil {
+		err = uerr
+	}
+	return err
+}
+
+// packageVideo 打包并上传，返回主播放列表地址
+func packageVideo(ctx context.Context, store storage.Storage, dirs []string, videoID int64, videoURL string, opts hls.Options, workDir string) (string, error) {
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(workDir, "hls-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	src, err := sourceVideo(ctx, store, dirs, videoURL, tmp)
+	if err != nil {
+		return "", err
+	}
+
+	outDir := filepath.Join(tmp, "out")
+	if _, err := hls.Package(ctx, src, outDir, opts); err != nil {
+		return "", err
+	}
+
+	// 删除上次打包的文件（清晰度可能不同），再上传本次的结果
+	prefix := HLSKeyPrefix(videoID)
+	var stale []string
+	if err := store.List(ctx, prefix, func(o storage.ObjectInfo) error {
+		stale = append(stale, o.Key)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	for _, key := range stale {
+		if err := store.Delete(ctx, key); err != nil {
+			return "", err
+		}
+	}
+
+	err = filepath.WalkDir(outDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(outDir, p)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		st, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return store.Put(ctx, prefix+filepath.ToSlash(rel), f, st.Size(), mime.TypeByExtension(filepath.Ext(p)))
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return videoURLPrefix + strings.TrimPrefix(prefix, storage.PrefixVideos) + hls.MasterPlaylist, nil
+}
+
+// sourceVideo 获取源视频的本地路径，远程存储中的视频先下载到 tmp
+func sourceVideo(ctx context.Context, store storage.Storage, dirs []string, videoURL, tmp string) (string, error) {
+	file, ok := localVideoFile(videoURL, dirs)
+	if !ok {
+		// 外部地址（CDN等）无法打包
+		return "", hls.ErrUnsupported
+	}
+	if file != "" {
+		return file, nil
+	}
+
+	u, _ := url.Parse(videoURL)
+	key := storage.PrefixVideos + path.Clean("/" + strings.TrimPrefix(u.Path, videoURLPrefix))[1:]
+	rc, _, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	if f, ok := rc.(*os.File); ok {
+		return f.Name(), nil
+	}
+
+	dst := filepath.Join(tmp, "source"+path.Ext(key))
+	f, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, rc)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	return dst, nil
+}
@@ -0,0 +1,139 @@
+// internal/ops/hotsearch.go
+package ops
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cybersecurity-platform-go/internal/hotsearch"
+)
+
+// 搜索词规则
+const (
+	SearchTermPin   = "pin"   // 置顶
+	SearchTermBlock = "block" // 屏蔽
+)
+
+// searchLogRetention 搜索日志至少保留的时间，超过统计窗口和该时间的日志在计算热门搜索时删除
+const searchLogRetention = 30 * 24 * time.Hour
+
+// SearchTermRule 管理员设置的搜索词规则
+type SearchTermRule struct {
+	Term   string `json:"term"`
+	Action string `json:"action"`
+	Sort   int    `json:"sort"`
+}
+
+// LogSearch 记录一次课程搜索，query 为原始搜索词，searcher 为学号或IP
+func LogSearch(ctx context.Context, db *sql.DB, query, searcher string, results int) error {
+	term := hotsearch.Normalize(query)
+	if term == "" {
+		return nil
+	}
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO search_logs (term, searcher, results, created_at) VALUES (?, ?, ?, ?)",
+		term, hotsearch.SearcherKey(searcher), results, time.Now(),
+	)
+	return err
+}
+
+// RefreshHotSearch 根据搜索日志和管理员规则重新计算全局热门搜索列表，并清理过期日志
+// 只统计有搜索结果的搜索；同一人多次搜索同一个词只计一次
+func RefreshHotSearch(ctx context.Context, db *sql.DB) ([]hotsearch.Term, error) {
+	board := hotsearch.Default()
+	opts := board.Options()
+	now := time.Now()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT term,
+			COUNT(DISTINCT CASE WHEN created_at >= ? THEN searcher END),
+			COUNT(DISTINCT searcher)
+		FROM search_logs
+		WHERE created_at >= ? AND results > 0
+		GROUP BY term
+	`, now.Add(-opts.Recent), now.Add(-opts.Window))
+	if err != nil {
+		return nil, err
+	}
+	var stats []hotsearch.Stats
+	for rows.Next() {
+		var s hotsearch.Stats
+		if err := rows.Scan(&s.Term, &s.Recent, &s.Window); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rules, err := SearchTermRules(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var pinned []string
+	blocked := make(map[string]bool)
+	for _, r := range rules {
+		switch r.Action {
+		case SearchTermPin:
+			pinned = append(pinned, r.Term)
+		case SearchTermBlock:
+			blocked[r.Term] = true
+		}
+	}
+
+	terms := hotsearch.Rank(stats, pinned, blocked, opts)
+	board.Set(terms, now)
+
+	retention := searchLogRetention
+	if opts.Window > retention {
+		retention = opts.Window
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM search_logs WHERE created_at < ?", now.Add(-retention)); err != nil {
+		return terms, err
+	}
+	return terms, nil
+}
+
+// SearchTermRules 管理员设置的置顶和屏蔽词，置顶词按显示顺序排列
+func SearchTermRules(ctx context.Context, db *sql.DB) ([]SearchTermRule, error) {
+	rows, err := db.QueryContext(ctx, "SELECT term, action, sort_order FROM search_terms ORDER BY action, sort_order, created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []SearchTermRule{}
+	for rows.Next() {
+		var r SearchTermRule
+		if err := rows.Scan(&r.Term, &r.Action, &r.Sort); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// SetSearchTerm 置顶或屏蔽搜索词，action 为空时取消规则
+func SetSearchTerm(ctx context.Context, db *sql.DB, rule SearchTermRule) error {
+	term := hotsearch.Normalize(rule.Term)
+	if term == "" {
+		return InputError("搜索词不能为空")
+	}
+	switch rule.Action {
+	case "":
+		_, err := db.ExecContext(ctx, "DELETE FROM search_terms WHERE term = ?", term)
+		return err
+	case SearchTermPin, SearchTermBlock:
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO search_terms (term, action, sort_order) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE action = VALUES(action), sort_order = VALUES(sort_order)
+		`, term, rule.Action, rule.Sort)
+		return err
+	default:
+		return InputError("action 只能是 pin、block 或空字符串")
+	}
+}
@@ -0,0 +1,210 @@
+// internal/ops/invite.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInviteRequired 凭邀请码加入的课程没有提供邀请码
+	ErrInviteRequired = errors.New("该课程需要邀请码才能加入")
+	// ErrInviteInvalid 邀请码不存在、不属于该课程或已作废
+	ErrInviteInvalid = errors.New("邀请码无效")
+	// ErrInviteExpired 邀请码已过期
+	ErrInviteExpired = errors.New("邀请码已过期")
+	// ErrInviteUsedUp 邀请码的使用次数已用完
+	ErrInviteUsedUp = errors.New("邀请码使用次数已满")
+	// ErrInviteNotFound 作废邀请码时找不到
+	ErrInviteNotFound = errors.New("邀请码不存在")
+)
+
+// inviteCodePattern 自定义邀请码的格式
+var inviteCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{4,32}$`)
+
+// inviteChars 生成邀请码使用的字符，去掉了容易混淆的 0/O、1/I
+const inviteChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// InviteInput 创建邀请码的参数
+// Code 为空时随机生成；MaxUses 为0表示不限次数；ExpiresAt 为空表示不过期（格式同章节开放时间）
+type InviteInput struct {
+	Code      string `json:"code"`
+	MaxUses   int    `json:"maxUses"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// CourseInvite 课程邀请码
+type CourseInvite struct {
+	ID        int        `json:"id"`
+	CourseID  int        `json:"courseId"`
+	Code      string     `json:"code"`
+	MaxUses   int        `json:"maxUses"`
+	Used      int        `json:"used"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	CreatedBy string     `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	Usable    bool       `json:"usable"` // 未作废、未过期且还有使用次数
+}
+
+// courseInvite 选课时锁定的邀请码
+type courseInvite struct {
+	id   int
+	code string
+}
+
+// usable 邀请码现在是否可用
+func (c *CourseInvite) usable(now time.Time) bool {
+	return c.RevokedAt == nil && (c.ExpiresAt == nil || now.Before(*c.ExpiresAt)) && (c.MaxUses == 0 || c.Used < c.MaxUses)
+}
+
+// lockInvite 锁定并检查学生提交的邀请码
+func lockInvite(tx *sql.Tx, courseID int, code string, now time.Time) (*courseInvite, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, ErrInviteRequired
+	}
+	var c CourseInvite
+	var expires, revoked sql.NullTime
+	err := tx.QueryRow(
+		"SELECT id, max_uses, used, expires_at, revoked_at FROM course_invites WHERE course_id = ? AND code = ? FOR UPDATE",
+		courseID, code,
+	).Scan(&c.ID, &c.MaxUses, &c.Used, &expires, &revoked)
+	if err == sql.ErrNoRows || (err == nil && revoked.Valid) {
+		return nil, ErrInviteInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if expires.Valid && !now.Before(expires.Time) {
+		return nil, ErrInviteExpired
+	}
+	if c.MaxUses > 0 && c.Used >= c.MaxUses {
+		return nil, ErrInviteUsedUp
+	}
+	return &courseInvite{id: c.ID, code: code}, nil
+}
+
+// CheckCourseAccess 教师只能管理自己讲授的课程，并且在课程中的角色要有 perm 权限；
+// teacherID 为0（管理员）时只检查课程是否存在
+func CheckCourseAccess(db *sql.DB, teacherID, courseID int, perm string) error {
+	return checkCourseAccess(db, teacherID, courseID, perm)
+}
+
+func checkCourseAccess(q queryExecer, teacherID, courseID int, perm string) error {
+	if teacherID != 0 {
+		return checkCourseTeacher(q, teacherID, courseID, perm, false)
+	}
+	var exists bool
+	if err := q.QueryRow("SELECT EXISTS(SELECT 1 FROM courses WHERE id = ?)", courseID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrCourseNotFound
+	}
+	return nil
+}
+
+// CreateInvite 为课程创建邀请码，teacherID 为0表示管理员
+func CreateInvite(db *sql.DB, teacherID, courseID int, in InviteInput) (*CourseInvite, error) {
+	if in.MaxUses < 0 {
+		return nil, InputError("使用次数不能小于0")
+	}
+	code := strings.ToUpper(strings.TrimSpace(in.Code))
+	if code != "" && !inviteCodePattern.MatchString(code) {
+		return nil, InputError("邀请码只能包含字母、数字、下划线和连字符，长度4到32")
+	}
+	expires, err := ParseReleaseAt(in.ExpiresAt)
+	if err != nil {
+		return nil, InputError("无效的过期时间，例如 2024-09-16 23:59")
+	}
+	now := time.Now()
+	if expires != nil && !expires.After(now) {
+		return nil, InputError("过期时间必须晚于当前时间")
+	}
+	if err := checkCourseAccess(db, teacherID, courseID, PermStudents); err != nil {
+		return nil, err
+	}
+
+	// 随机生成的邀请码重复时重新生成
+	for attempt := 0; ; attempt++ {
+		c := code
+		if c == "" {
+			if c, err = randomString(inviteChars, 8); err != nil {
+				return nil, err
+			}
+		}
+		res, err := db.Exec(
+			"INSERT INTO course_invites (course_id, code, max_uses, expires_at, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+			courseID, c, in.MaxUses, expires, teacherActor(teacherID), now,
+		)
+		if isDuplicateEntry(err) {
+			if code != "" {
+				return nil, InputError("邀请码已存在")
+			}
+			if attempt < 5 {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		id, _ := res.LastInsertId()
+		invite := &CourseInvite{
+			ID: int(id), CourseID: courseID, Code: c, MaxUses: in.MaxUses, ExpiresAt: expires,
+			CreatedBy: teacherActor(teacherID), CreatedAt: now,
+		}
+		invite.Usable = invite.usable(now)
+		return invite, nil
+	}
+}
+
+// CourseInvites 课程的全部邀请码（最新的在前）
+func CourseInvites(db *sql.DB, teacherID, courseID int) ([]CourseInvite, error) {
+	if err := checkCourseAccess(db, teacherID, courseID, PermStudents); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`
+		SELECT id, course_id, code, max_uses, used, expires_at, created_by, created_at, revoked_at
+		FROM course_invites WHERE course_id = ? ORDER BY id DESC
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	list := []CourseInvite{}
+	for rows.Next() {
+		var c CourseInvite
+		var expires, revoked sql.NullTime
+		if err := rows.Scan(&c.ID, &c.CourseID, &c.Code, &c.MaxUses, &c.Used, &expires, &c.CreatedBy, &c.CreatedAt, &revoked); err != nil {
+			return nil, err
+		}
+		c.ExpiresAt = nullTime(expires)
+		c.RevokedAt = nullTime(revoked)
+		c.Usable = c.usable(now)
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// RevokeInvite 作废邀请码，已经用邀请码加入的学生不受影响
+func RevokeInvite(db *sql.DB, teacherID, inviteID int) error {
+	var courseID int
+	err := db.QueryRow("SELECT course_id FROM course_invites WHERE id = ?", inviteID).Scan(&courseID)
+	if err == sql.ErrNoRows {
+		return ErrInviteNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkCourseAccess(db, teacherID, courseID, PermStudents); err != nil {
+		return err
+	}
+	_, err = db.Exec("UPDATE course_invites SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?", time.Now(), inviteID)
+	return err
+}
@@ -0,0 +1,232 @@
+// internal/ops/lifecycle.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 课程状态：教师创建的课程为草稿，提交审核后由管理员发布；学生只能看到已发布的课程，
+// 已归档的课程不再出现在课程列表中，已选修的学生仍可以继续学习
+const (
+	CourseDraft     = "draft"
+	CourseReview    = "review"
+	CoursePublished = "published"
+	CourseArchived  = "archived"
+)
+
+var (
+	// ErrInvalidTransition 当前状态不能变更为目标状态
+	ErrInvalidTransition = errors.New("课程当前状态不允许该操作")
+	// ErrCourseEmpty 没有课时的课程不能提交审核
+	ErrCourseEmpty = errors.New("课程至少需要一个课时才能提交审核")
+)
+
+// 教师和管理员允许的状态变更
+var (
+	teacherTransitions = map[[2]string]bool{
+		{CourseDraft, CourseReview}:       true, // 提交审核
+		{CourseReview, CourseDraft}:       true, // 撤回
+		{CoursePublished, CourseArchived}: true, // 归档
+	}
+	adminTransitions = map[[2]string]bool{
+		{CourseReview, CoursePublished}:   true, // 审核通过
+		{CourseReview, CourseDraft}:       true, // 退回修改
+		{CoursePublished, CourseArchived}: true,
+		{CourseArchived, CoursePublished}: true, // 恢复
+	}
+)
+
+// StudentVisibleStatuses 已选修的学生可以访问的课程状态
+var StudentVisibleStatuses = []string{CoursePublished, CourseArchived}
+
+// ChapterInput 修改章节的字段，为 nil 的字段保持不变
+// ReleaseAt 为开放时间（RFC 3339 或 "2006-01-02 15:04"，按服务器时区），空字符串表示立即开放
+type ChapterInput struct {
+	Title     *string `json:"title"`
+	ReleaseAt *string `json:"releaseAt"`
+}
+
+// SetCourseStatus 变更课程状态，teacherID 为0表示管理员操作
+// note 为审核意见（退回时告知教师原因），提交审核时清空
+func SetCourseStatus(db *sql.DB, teacherID, courseID int, to, note string) error {
+	if utf8.RuneCountInString(note) > 500 {
+		return InputError("审核意见不能超过500个字符")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var from string
+	err = tx.QueryRow("SELECT status FROM courses WHERE id = ? FOR UPDATE", courseID).Scan(&from)
+	if err == sql.ErrNoRows {
+		return ErrCourseNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	allowed := adminTransitions
+	if teacherID != 0 {
+		if err := checkCourseTeacher(tx, teacherID, courseID, PermPublish, false); err != nil {
+			return err
+		}
+		allowed = teacherTransitions
+	}
+	if !allowed[[2]string{from, to}] {
+		return fmt.Errorf("%w：%s → %s", ErrInvalidTransition, from, to)
+	}
+
+	if to == CourseReview {
+		var lessons int
+		if err := tx.QueryRow(`
+			SELECT COUNT(*) FROM chapter_children cc
+			JOIN chapters ch ON cc.chapter_id = ch.id
+			WHERE ch.course_id = ?
+		`, courseID).Scan(&lessons); err != nil {
+			return err
+		}
+		if lessons == 0 {
+			return ErrCourseEmpty
+		}
+		note = ""
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE courses
+		SET status = ?, status_note = ?,
+			published_at = CASE WHEN ? = 'published' THEN COALESCE(published_at, ?) ELSE published_at END
+		WHERE id = ?
+	`, to, strings.TrimSpace(note), to, time.Now(), courseID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CoursesByStatus 按状态查询课程（管理员审核列表）
+func CoursesByStatus(db *sql.DB, status string) ([]CourseOutline, error) {
+	rows, err := db.Query(`
+		SELECT id, title, description, cover, credit, limit_count, lesson_num, status, status_note, drop_deadline, enroll_mode
+		FROM courses WHERE status = ?
+		ORDER BY updated_at
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := []CourseOutline{}
+	for rows.Next() {
+		c, err := scanCourse(rows)
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, *c)
+	}
+	return courses, rows.Err()
+}
+
+// UpdateChapter 修改章节标题和开放时间
+func UpdateChapter(db *sql.DB, teacherID, chapterID int, in ChapterInput) error {
+	var sets []string
+	var args []interface{}
+	if in.Title != nil {
+		title, err := chapterTitle(*in.Title)
+		if err != nil {
+			return err
+		}
+		sets, args = append(sets, "title = ?"), append(args, title)
+	}
+	if in.ReleaseAt != nil {
+		releaseAt, err := ParseReleaseAt(*in.ReleaseAt)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if releaseAt != nil {
+			value = *releaseAt
+		}
+		sets, args = append(sets, "release_at = ?"), append(args, value)
+	}
+
+	courseID, err := chapterCourse(db, teacherID, chapterID, false)
+	if err != nil {
+		return err
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+	if _, err := db.Exec("UPDATE chapters SET "+strings.Join(sets, ", ")+" WHERE id = ?", append(args, chapterID)...); err != nil {
+		return err
+	}
+	if in.Title != nil {
+		return reindexCourse(db, courseID)
+	}
+	return nil
+}
+
+// ScheduleChapters 按显示顺序为课程的章节设置开放时间：第一章在 start 开放，之后每隔 every 开放一章
+func ScheduleChapters(db *sql.DB, teacherID, courseID int, start time.Time, every time.Duration) error {
+	if every <= 0 {
+		return InputError("开放间隔必须大于0")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkCourseTeacher(tx, teacherID, courseID, PermContent, true); err != nil {
+		return err
+	}
+	rows, err := tx.Query("SELECT id FROM chapters WHERE course_id = ? ORDER BY sort_order, id", courseID)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		if _, err := tx.Exec("UPDATE chapters SET release_at = ? WHERE id = ?", start.Add(time.Duration(i)*every), id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ParseReleaseAt 解析开放时间，空字符串返回 nil（立即开放）
+func ParseReleaseAt(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		t = t.In(time.Local)
+		return &t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, InputError("无效的开放时间，例如 2024-09-02 08:00 或 2024-09-02T08:00:00+08:00")
+}
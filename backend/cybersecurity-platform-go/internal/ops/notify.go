@@ -0,0 +1,89 @@
+// internal/ops/notify.go
+package ops
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// 通知类型
+const (
+	NoticeWaitlistOffer   = "waitlist_offer"   // 候补获得名额
+	NoticeWaitlistExpired = "waitlist_expired" // 候补名额过期
+	NoticeEnrollApproved  = "enroll_approved"  // 选课申请通过
+	NoticeEnrollRejected  = "enroll_rejected"  // 选课申请未通过
+	NoticeReviewRejected  = "review_rejected"  // 课程评价未通过审核
+	NoticeReviewReply     = "review_reply"     // 教师回复了课程评价
+)
+
+// Notification 学生站内通知
+type Notification struct {
+	ID        int64      `json:"id"`
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	CourseID  int        `json:"courseId"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// notify 给学生发送站内通知，courseID 为0表示与课程无关
+func notify(q queryExecer, stuID, kind, title, content string, courseID int) error {
+	_, err := q.Exec(
+		"INSERT INTO notifications (stuId, kind, title, content, course_id, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		stuID, kind, title, content, nullableID(courseID), time.Now(),
+	)
+	return err
+}
+
+// Notifications 学生的通知（最新的在前）和未读数量
+func Notifications(db *sql.DB, stuID string, unreadOnly bool, limit int) ([]Notification, int, error) {
+	query := "SELECT id, kind, title, content, course_id, read_at, created_at FROM notifications WHERE stuId = ?"
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	rows, err := db.Query(query+" ORDER BY id DESC LIMIT ?", stuID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []Notification{}
+	for rows.Next() {
+		var n Notification
+		var courseID sql.NullInt64
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.Kind, &n.Title, &n.Content, &courseID, &readAt, &n.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		n.CourseID = int(courseID.Int64)
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		list = append(list, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var unread int
+	if err := db.QueryRow("SELECT COUNT(*) FROM notifications WHERE stuId = ? AND read_at IS NULL", stuID).Scan(&unread); err != nil {
+		return nil, 0, err
+	}
+	return list, unread, nil
+}
+
+// MarkNotificationsRead 将学生的通知标记为已读，ids 为空时全部标记
+func MarkNotificationsRead(db *sql.DB, stuID string, ids []int64) error {
+	query := "UPDATE notifications SET read_at = ? WHERE stuId = ? AND read_at IS NULL"
+	args := []interface{}{time.Now(), stuID}
+	if len(ids) > 0 {
+		query += " AND id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	_, err := db.Exec(query, args...)
+	return err
+}
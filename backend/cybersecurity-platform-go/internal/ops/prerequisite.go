@@ -0,0 +1,443 @@
+// internal/ops/prerequisite.go
+package ops
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// 先修规则的规模限制
+const (
+	maxPrerequisiteGroups = 10 // 最多10组（且）
+	maxPrerequisiteItems  = 10 // 每组最多10项（或）
+)
+
+// prerequisiteQueryer *sql.DB 和 *sql.Tx 的公共方法，选课时在事务中检查先修课程
+type prerequisiteQueryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// PrerequisiteItem 先修规则中的一门课程或一个章节
+// 课程的 Status 为学生在该课程的选课状态（未选课为空），完成（completed）才算满足；
+// 章节的 Status 为 finished（视频课时全部学完）或空
+type PrerequisiteItem struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	Status    string `json:"status,omitempty"`
+	Satisfied bool   `json:"satisfied"`
+}
+
+// PrerequisiteGroup 一组可以互相替代的先修条件，满足其中之一即可
+type PrerequisiteGroup struct {
+	Items     []PrerequisiteItem `json:"items"`
+	Satisfied bool               `json:"satisfied"`
+}
+
+// PrerequisiteCheck 先修规则的检查结果：全部组都满足时 Satisfied 为 true，Missing 为未满足的组
+type PrerequisiteCheck struct {
+	Satisfied bool                `json:"satisfied"`
+	Groups    []PrerequisiteGroup `json:"groups"`
+	Missing   []PrerequisiteGroup `json:"missing"`
+}
+
+// PrerequisiteError 学生未满足课程的先修规则
+type PrerequisiteError struct {
+	Missing []PrerequisiteGroup
+}
+
+func (e *PrerequisiteError) Error() string {
+	return "需要先完成先修课程：" + DescribePrerequisites(e.Missing)
+}
+
+// DescribePrerequisites 把先修条件写成一句话，例如 《网络基础》；《Linux入门》或《Windows基础》
+func DescribePrerequisites(groups []PrerequisiteGroup) string {
+	parts := make([]string, len(groups))
+	for i, g := range groups {
+		titles := make([]string, len(g.Items))
+		for j, item := range g.Items {
+			titles[j] = "《" + item.Title + "》"
+		}
+		parts[i] = strings.Join(titles, "或")
+	}
+	return strings.Join(parts, "；")
+}
+
+// evaluate 计算各组是否满足，组内任一项满足即可
+func (c *PrerequisiteCheck) evaluate() {
+	c.Satisfied = true
+	c.Missing = []PrerequisiteGroup{}
+	for i := range c.Groups {
+		g := &c.Groups[i]
+		g.Satisfied = false
+		for _, item := range g.Items {
+			if item.Satisfied {
+				g.Satisfied = true
+				break
+			}
+		}
+		if !g.Satisfied {
+			c.Satisfied = false
+			c.Missing = append(c.Missing, *g)
+		}
+	}
+}
+
+// NormalizePrerequisites 整理提交的先修规则：去掉空组和组内重复项，不能引用自己
+func NormalizePrerequisites(selfID int, groups [][]int) ([][]int, error) {
+	out := [][]int{}
+	for _, group := range groups {
+		seen := make(map[int]bool, len(group))
+		ids := []int{}
+		for _, id := range group {
+			if id <= 0 {
+				return nil, InputError("无效的先修ID")
+			}
+			if id == selfID {
+				return nil, InputError("不能把自己设为先修条件")
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > maxPrerequisiteItems {
+			return nil, InputError(fmt.Sprintf("每组最多%d个先修条件", maxPrerequisiteItems))
+		}
+		if len(ids) > 0 {
+			out = append(out, ids)
+		}
+	}
+	if len(out) > maxPrerequisiteGroups {
+		return nil, InputError(fmt.Sprintf("最多%d组先修条件", maxPrerequisiteGroups))
+	}
+	return out, nil
+}
+
+// FindPrerequisiteCycle 在先修关系图（ID → 它要求的ID）中查找经过 start 的环，返回环上的ID（首尾都是 start），没有环返回 nil
+// 修改前的图没有环，所以只需要检查从被修改的节点出发能否回到它自己
+func FindPrerequisiteCycle(edges map[int][]int, start int) []int {
+	visited := make(map[int]bool)
+	var path []int
+	var visit func(id int) bool
+	visit = func(id int) bool {
+		path = append(path, id)
+		for _, next := range edges[id] {
+			if next == start {
+				path = append(path, next)
+				return true
+			}
+			if !visited[next] {
+				visited[next] = true
+				if visit(next) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(start) {
+		return path
+	}
+	return nil
+}
+
+// flattenGroups 规则中引用的全部ID（不重复）
+func flattenGroups(groups [][]int) []int {
+	seen := make(map[int]bool)
+	ids := []int{}
+	for _, g := range groups {
+		for _, id := range g {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// inArgs IN 子句的占位符和参数
+func inArgs(ids []int) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return "?" + strings.Repeat(", ?", len(ids)-1), args
+}
+
+// lockPrerequisites 锁定先修规则的哨兵行，课程和章节先修规则的修改都在此串行执行
+// 只锁定被修改的课程或章节不够：两个并发修改可能各自检查通过，合起来却形成循环
+func lockPrerequisites(tx *sql.Tx) error {
+	var name string
+	return tx.QueryRow("SELECT name FROM app_locks WHERE name = 'prerequisites' FOR UPDATE").Scan(&name)
+}
+
+// loadPrerequisiteEdges 读取先修关系图，query 返回 (ID, 要求的ID) 两列
+// 使用加锁读取最新提交的数据，事务之前的普通查询建立的快照可能早于上一个修改的提交
+func loadPrerequisiteEdges(tx *sql.Tx, query string, args ...interface{}) (map[int][]int, error) {
+	rows, err := tx.Query(query+" LOCK IN SHARE MODE", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := make(map[int][]int)
+	for rows.Next() {
+		var id, required int
+		if err := rows.Scan(&id, &required); err != nil {
+			return nil, err
+		}
+		edges[id] = append(edges[id], required)
+	}
+	return edges, rows.Err()
+}
+
+// cycleError 把环上的ID换成标题，提示哪些规则形成了循环
+func cycleError(tx *sql.Tx, table string, cycle []int) error {
+	titles := make([]string, len(cycle))
+	for i, id := range cycle {
+		titles[i] = fmt.Sprint(id)
+		var title string
+		if err := tx.QueryRow("SELECT title FROM "+table+" WHERE id = ?", id).Scan(&title); err == nil {
+			titles[i] = "《" + title + "》"
+		}
+	}
+	return InputError("先修规则形成循环：" + strings.Join(titles, " → "))
+}
+
+// CoursePrerequisites 课程的先修规则（不含学生状态），没有规则时返回空列表
+func CoursePrerequisites(db *sql.DB, courseID int) ([]PrerequisiteGroup, error) {
+	check, err := coursePrerequisiteCheck(db, "", courseID)
+	if err != nil {
+		return nil, err
+	}
+	return check.Groups, nil
+}
+
+// CheckCoursePrerequisites 学生是否满足课程的先修规则，未满足时 Missing 说明还需要完成哪些课程
+func CheckCoursePrerequisites(db *sql.DB, stuID string, courseID int) (*PrerequisiteCheck, error) {
+	return coursePrerequisiteCheck(db, stuID, courseID)
+}
+
+func coursePrerequisiteCheck(q prerequisiteQueryer, stuID string, courseID int) (*PrerequisiteCheck, error) {
+	rows, err := q.Query(`
+		SELECT p.group_no, c.id, c.title, COALESCE(sc.status, '')
+		FROM course_prerequisites p
+		JOIN courses c ON c.id = p.required_course_id
+		LEFT JOIN student_courses sc ON sc.course_id = p.required_course_id AND sc.stuId = ?
+		WHERE p.course_id = ?
+		ORDER BY p.group_no, p.id
+	`, stuID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	check := &PrerequisiteCheck{Groups: []PrerequisiteGroup{}}
+	last := -1
+	for rows.Next() {
+		var groupNo int
+		var item PrerequisiteItem
+		if err := rows.Scan(&groupNo, &item.ID, &item.Title, &item.Status); err != nil {
+			return nil, err
+		}
+		item.Satisfied = item.Status == EnrollCompleted
+		if groupNo != last {
+			check.Groups = append(check.Groups, PrerequisiteGroup{})
+			last = groupNo
+		}
+		g := &check.Groups[len(check.Groups)-1]
+		g.Items = append(g.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	check.evaluate()
+	return check, nil
+}
+
+// checkEnrollPrerequisites 选课时检查先修课程，未满足时返回 *PrerequisiteError
+func checkEnrollPrerequisites(tx *sql.Tx, stuID string, courseID int) error {
+	check, err := coursePrerequisiteCheck(tx, stuID, courseID)
+	if err != nil {
+		return err
+	}
+	if !check.Satisfied {
+		return &PrerequisiteError{Missing: check.Missing}
+	}
+	return nil
+}
+
+// SetCoursePrerequisites 设置课程的先修规则（整体替换），teacherID 为0表示管理员
+// groups 之间为“且”，同一组内为“或”，例如 [[1], [2, 5]] 表示需要完成课程1，并且完成课程2或课程5；
+// 规则形成循环（A 要求 B，B 又直接或间接要求 A）时拒绝
+func SetCoursePrerequisites(db *sql.DB, teacherID, courseID int, groups [][]int) error {
+	groups, err := NormalizePrerequisites(courseID, groups)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkCourseAccess(tx, teacherID, courseID, PermContent); err != nil {
+		return err
+	}
+	if ids := flattenGroups(groups); len(ids) > 0 {
+		in, args := inArgs(ids)
+		var found int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM courses WHERE id IN ("+in+")", args...).Scan(&found); err != nil {
+			return err
+		}
+		if found != len(ids) {
+			return ErrCourseNotFound
+		}
+	}
+
+	if err := lockPrerequisites(tx); err != nil {
+		return err
+	}
+	edges, err := loadPrerequisiteEdges(tx,
+		"SELECT course_id, required_course_id FROM course_prerequisites WHERE course_id <> ?", courseID)
+	if err != nil {
+		return err
+	}
+	edges[courseID] = flattenGroups(groups)
+	if cycle := FindPrerequisiteCycle(edges, courseID); cycle != nil {
+		return cycleError(tx, "courses", cycle)
+	}
+
+	if _, err := tx.Exec("DELETE FROM course_prerequisites WHERE course_id = ?", courseID); err != nil {
+		return err
+	}
+	for i, group := range groups {
+		for _, id := range group {
+			if _, err := tx.Exec(
+				"INSERT INTO course_prerequisites (course_id, group_no, required_course_id) VALUES (?, ?, ?)",
+				courseID, i, id,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// ChapterPrerequisites 课程中各章节的先修规则（章节ID → 规则），Satisfied 需要调用方按学习进度计算
+func ChapterPrerequisites(db *sql.DB, courseID int) (map[int][]PrerequisiteGroup, error) {
+	rows, err := db.Query(`
+		SELECT p.chapter_id, p.group_no, ch.id, ch.title
+		FROM chapter_prerequisites p
+		JOIN chapters ch ON ch.id = p.required_chapter_id
+		JOIN chapters owner ON owner.id = p.chapter_id
+		WHERE owner.course_id = ?
+		ORDER BY p.chapter_id, p.group_no, p.id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make(map[int][]PrerequisiteGroup)
+	lastChapter, lastGroup := -1, -1
+	for rows.Next() {
+		var chapterID, groupNo int
+		var item PrerequisiteItem
+		if err := rows.Scan(&chapterID, &groupNo, &item.ID, &item.Title); err != nil {
+			return nil, err
+		}
+		if chapterID != lastChapter || groupNo != lastGroup {
+			rules[chapterID] = append(rules[chapterID], PrerequisiteGroup{})
+			lastChapter, lastGroup = chapterID, groupNo
+		}
+		groups := rules[chapterID]
+		groups[len(groups)-1].Items = append(groups[len(groups)-1].Items, item)
+	}
+	return rules, rows.Err()
+}
+
+// CheckChapterPrerequisites 按已学完的章节计算章节先修规则是否满足
+func CheckChapterPrerequisites(groups []PrerequisiteGroup, finished map[int]bool) *PrerequisiteCheck {
+	check := &PrerequisiteCheck{Groups: make([]PrerequisiteGroup, len(groups))}
+	for i, g := range groups {
+		items := make([]PrerequisiteItem, len(g.Items))
+		for j, item := range g.Items {
+			item.Satisfied = finished[item.ID]
+			if item.Satisfied {
+				item.Status = "finished"
+			}
+			items[j] = item
+		}
+		check.Groups[i] = PrerequisiteGroup{Items: items}
+	}
+	check.evaluate()
+	return check
+}
+
+// SetChapterPrerequisites 设置章节的先修规则（整体替换），先修章节必须属于同一门课程
+// 规则含义同 SetCoursePrerequisites，学生学完先修章节的全部视频课时后才能学习该章节
+func SetChapterPrerequisites(db *sql.DB, teacherID, chapterID int, groups [][]int) error {
+	groups, err := NormalizePrerequisites(chapterID, groups)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 锁定课程行，同一课程的章节规则串行修改
+	courseID, err := chapterCourse(tx, teacherID, chapterID, true)
+	if err != nil {
+		return err
+	}
+	if ids := flattenGroups(groups); len(ids) > 0 {
+		in, args := inArgs(ids)
+		var found int
+		if err := tx.QueryRow(
+			"SELECT COUNT(*) FROM chapters WHERE course_id = ? AND id IN ("+in+")", append([]interface{}{courseID}, args...)...,
+		).Scan(&found); err != nil {
+			return err
+		}
+		if found != len(ids) {
+			return InputError("先修章节必须属于同一门课程")
+		}
+	}
+
+	if err := lockPrerequisites(tx); err != nil {
+		return err
+	}
+	edges, err := loadPrerequisiteEdges(tx, `
+		SELECT p.chapter_id, p.required_chapter_id FROM chapter_prerequisites p
+		JOIN chapters ch ON ch.id = p.chapter_id
+		WHERE ch.course_id = ? AND p.chapter_id <> ?`, courseID, chapterID)
+	if err != nil {
+		return err
+	}
+	edges[chapterID] = flattenGroups(groups)
+	if cycle := FindPrerequisiteCycle(edges, chapterID); cycle != nil {
+		return cycleError(tx, "chapters", cycle)
+	}
+
+	if _, err := tx.Exec("DELETE FROM chapter_prerequisites WHERE chapter_id = ?", chapterID); err != nil {
+		return err
+	}
+	for i, group := range groups {
+		for _, id := range group {
+			if _, err := tx.Exec(
+				"INSERT INTO chapter_prerequisites (chapter_id, group_no, required_chapter_id) VALUES (?, ?, ?)",
+				chapterID, i, id,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
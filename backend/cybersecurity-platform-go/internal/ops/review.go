@@ -0,0 +1,430 @@
+// internal/ops/review.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cybersecurity-platform-go/internal/progress"
+)
+
+// 评价状态
+const (
+	ReviewPending  = "pending"  // 带文字的评价等待管理员审核，评分已计入
+	ReviewApproved = "approved" // 已公开（只有评分的评价直接公开）
+	ReviewRejected = "rejected" // 审核未通过，文字不公开，评分不计入
+)
+
+// ReviewMinProgress 评价课程需要达到的学习进度（百分比），已结课的学生不受限制；服务启动时根据配置设置
+var ReviewMinProgress = 30
+
+var (
+	// ErrReviewNotFound 评价不存在
+	ErrReviewNotFound = errors.New("评价不存在")
+	// ErrReviewProgress 学习进度不足，还不能评价
+	ErrReviewProgress = errors.New("学习进度不足，暂不能评价")
+)
+
+// Review 课程评价
+type Review struct {
+	ID             int64      `json:"id"`
+	CourseID       int        `json:"courseId"`
+	CourseTitle    string     `json:"courseTitle,omitempty"`
+	StuID          string     `json:"stuId,omitempty"` // 公开的评价列表不返回学号
+	NickName       string     `json:"nickName"`
+	Rating         int        `json:"rating"`
+	Content        string     `json:"content"`
+	Status         string     `json:"status"`
+	ModerationNote string     `json:"moderationNote,omitempty"`
+	Reply          string     `json:"reply"`
+	RepliedAt      *time.Time `json:"repliedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// ReviewInput 学生提交的评分和评价（文字可以为空）
+type ReviewInput struct {
+	Rating  int    `json:"rating"`
+	Content string `json:"content"`
+}
+
+// RatingSummary 课程评分汇总，Distribution 依次为1星到5星的人数
+type RatingSummary struct {
+	Average      float64 `json:"average"`
+	Count        int     `json:"count"`
+	Distribution [5]int  `json:"distribution"`
+}
+
+// ReviewEligibility 学生能否评价课程；Progress 为当前学习进度，Review 为已提交的评价
+type ReviewEligibility struct {
+	CanReview   bool    `json:"canReview"`
+	Reason      string  `json:"reason,omitempty"`
+	Progress    int     `json:"progress"`
+	MinProgress int     `json:"minProgress"`
+	Review      *Review `json:"review"`
+}
+
+// validate 校验评分和文字
+func (in *ReviewInput) validate() error {
+	if in.Rating < 1 || in.Rating > 5 {
+		return InputError("评分必须是1到5星")
+	}
+	in.Content = strings.TrimSpace(in.Content)
+	if utf8.RuneCountInString(in.Content) > 2000 {
+		return InputError("评价不能超过2000个字符")
+	}
+	return nil
+}
+
+// reviewQueryer *sql.DB 和 *sql.Tx 的公共方法
+type reviewQueryer interface {
+	queryExecer
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// studentCourseProgress 学生在课程中的学习进度，按有视频的课时汇总
+func studentCourseProgress(q reviewQueryer, stuID string, courseID int) (progress.Rollup, error) {
+	var r progress.Rollup
+	rows, err := q.Query(`
+		SELECT COALESCE(lp.completed, 0), COALESCE(lp.watched_seconds, 0), COALESCE(NULLIF(lp.duration, 0), v.duration, 0)
+		FROM chapters ch
+		JOIN chapter_children cc ON cc.chapter_id = ch.id
+		LEFT JOIN videos v ON cc.video_id = v.id
+		LEFT JOIN lesson_progress lp ON lp.lesson_id = cc.id AND lp.stuId = ?
+		WHERE ch.course_id = ? AND cc.video_id IS NOT NULL
+	`, stuID, courseID)
+	if err != nil {
+		return r, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var completed bool
+		var watched, duration float64
+		if err := rows.Scan(&completed, &watched, &duration); err != nil {
+			return r, err
+		}
+		r.Add(completed, progress.Ratio(watched, duration))
+	}
+	return r, rows.Err()
+}
+
+// checkReviewEligibility 在修或已结课的学生才能评价；在修的学生学习进度需要达到 ReviewMinProgress
+// 课程没有视频课时时不检查进度
+func checkReviewEligibility(q reviewQueryer, stuID string, courseID int) (int, error) {
+	var status string
+	err := q.QueryRow("SELECT status FROM student_courses WHERE stuId = ? AND course_id = ?", stuID, courseID).Scan(&status)
+	if err == sql.ErrNoRows || (err == nil && status != EnrollActive && status != EnrollCompleted) {
+		return 0, ErrNotEnrolled
+	}
+	if err != nil {
+		return 0, err
+	}
+	r, err := studentCourseProgress(q, stuID, courseID)
+	if err != nil {
+		return 0, err
+	}
+	if status == EnrollActive && r.Total > 0 && r.Percent < ReviewMinProgress {
+		return r.Percent, ErrReviewProgress
+	}
+	return r.Percent, nil
+}
+
+// StudentReviewEligibility 学生能否评价课程，以及已提交的评价
+func StudentReviewEligibility(db *sql.DB, stuID string, courseID int) (*ReviewEligibility, error) {
+	e := &ReviewEligibility{MinProgress: ReviewMinProgress}
+	p, err := checkReviewEligibility(db, stuID, courseID)
+	switch err {
+	case nil:
+		e.CanReview = true
+	case ErrNotEnrolled:
+		e.Reason = "选修该课程后才能评价"
+	case ErrReviewProgress:
+		e.Reason = fmt.Sprintf("学习进度达到%d%%后才能评价", ReviewMinProgress)
+	default:
+		return nil, err
+	}
+	e.Progress = p
+
+	list, err := queryReviews(db, "r.course_id = ? AND r.stuId = ?", []interface{}{courseID, stuID}, "", 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		e.Review = &list[0]
+	}
+	return e, nil
+}
+
+// lockReviewCourse 锁定学生可见的课程行，同一课程的评价串行修改，评分汇总准确
+func lockReviewCourse(tx *sql.Tx, courseID int) error {
+	var id int
+	err := tx.QueryRow("SELECT id FROM courses WHERE id = ? AND status IN ('published', 'archived') FOR UPDATE", courseID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrCourseNotFound
+	}
+	return err
+}
+
+// SubmitReview 学生评分和评价课程，已评价时修改原评价
+// 只有评分的评价直接公开；文字有变化时重新进入审核，审核期间评分照常计入
+func SubmitReview(db *sql.DB, stuID string, courseID int, in ReviewInput) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockReviewCourse(tx, courseID); err != nil {
+		return err
+	}
+	if _, err := checkReviewEligibility(tx, stuID, courseID); err != nil {
+		return err
+	}
+
+	status := ReviewPending
+	if in.Content == "" {
+		status = ReviewApproved
+	} else {
+		var content, current string
+		err := tx.QueryRow("SELECT content, status FROM course_reviews WHERE course_id = ? AND stuId = ? FOR UPDATE", courseID, stuID).
+			Scan(&content, &current)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		// 只修改评分时保留原来的审核结果
+		if err == nil && content == in.Content && current != ReviewRejected {
+			status = current
+		}
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		INSERT INTO course_reviews (course_id, stuId, rating, content, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE moderation_note = IF(VALUES(status) = status, moderation_note, ''),
+			rating = VALUES(rating), content = VALUES(content), status = VALUES(status), updated_at = VALUES(updated_at)
+	`, courseID, stuID, in.Rating, in.Content, status, now, now); err != nil {
+		return err
+	}
+	if err := refreshCourseRating(tx, courseID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteReview 学生删除自己的评价
+func DeleteReview(db *sql.DB, stuID string, courseID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockReviewCourse(tx, courseID); err != nil {
+		return err
+	}
+	res, err := tx.Exec("DELETE FROM course_reviews WHERE course_id = ? AND stuId = ?", courseID, stuID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrReviewNotFound
+	}
+	if err := refreshCourseRating(tx, courseID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// refreshCourseRating 重新计算课程的评分汇总（被拒绝的评价不计入）
+func refreshCourseRating(q queryExecer, courseID int) error {
+	_, err := q.Exec(`
+		UPDATE courses c LEFT JOIN (
+			SELECT course_id, COUNT(*) AS n, AVG(rating) AS average,
+				SUM(rating = 1) AS r1, SUM(rating = 2) AS r2, SUM(rating = 3) AS r3, SUM(rating = 4) AS r4, SUM(rating = 5) AS r5
+			FROM course_reviews WHERE course_id = ? AND status <> ? GROUP BY course_id
+		) s ON s.course_id = c.id
+		SET c.rating_count = COALESCE(s.n, 0), c.rating_avg = COALESCE(s.average, 0),
+			c.rating_1 = COALESCE(s.r1, 0), c.rating_2 = COALESCE(s.r2, 0), c.rating_3 = COALESCE(s.r3, 0),
+			c.rating_4 = COALESCE(s.r4, 0), c.rating_5 = COALESCE(s.r5, 0)
+		WHERE c.id = ?
+	`, courseID, ReviewRejected, courseID)
+	return err
+}
+
+// CourseReviews 课程公开的评价（已通过审核且有文字，最新的在前）和总数
+func CourseReviews(db *sql.DB, courseID, page, size int) ([]Review, int, error) {
+	where := "r.course_id = ? AND r.status = ? AND r.content <> ''"
+	args := []interface{}{courseID, ReviewApproved}
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM course_reviews r WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	list, err := queryReviews(db, where, args, "r.created_at DESC, r.id DESC", size, (page-1)*size)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range list {
+		list[i].StuID = ""
+		list[i].ModerationNote = ""
+	}
+	return list, total, nil
+}
+
+// TeacherCourseReviews 课程的全部评价（含审核中和未通过的），teacherID 为0表示管理员
+func TeacherCourseReviews(db *sql.DB, teacherID, courseID int) ([]Review, error) {
+	if err := checkCourseAccess(db, teacherID, courseID, PermReviews); err != nil {
+		return nil, err
+	}
+	return queryReviews(db, "r.course_id = ?", []interface{}{courseID}, "r.created_at DESC, r.id DESC", 0, 0)
+}
+
+// ReviewsByStatus 管理员审核队列：按状态查询有文字的评价，最早提交的在前
+func ReviewsByStatus(db *sql.DB, status string) ([]Review, error) {
+	return queryReviews(db, "r.status = ? AND r.content <> ''", []interface{}{status}, "r.updated_at, r.id", 0, 0)
+}
+
+// queryReviews 查询评价，limit 为0表示不限
+func queryReviews(db *sql.DB, where string, args []interface{}, orderBy string, limit, offset int) ([]Review, error) {
+	query := `
+		SELECT r.id, r.course_id, c.title, r.stuId, ud.nickName, r.rating, r.content, r.status, r.moderation_note,
+			r.reply, r.replied_at, r.created_at, r.updated_at
+		FROM course_reviews r
+		JOIN courses c ON c.id = r.course_id
+		LEFT JOIN userdetail ud ON ud.stuId = r.stuId
+		WHERE ` + where
+	if orderBy != "" {
+		query += " ORDER BY " + orderBy
+	}
+	if limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Review{}
+	for rows.Next() {
+		var r Review
+		var nickName sql.NullString
+		var replied sql.NullTime
+		if err := rows.Scan(&r.ID, &r.CourseID, &r.CourseTitle, &r.StuID, &nickName, &r.Rating, &r.Content, &r.Status,
+			&r.ModerationNote, &r.Reply, &replied, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		r.NickName = nickName.String
+		r.RepliedAt = nullTime(replied)
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
+
+// reviewCourse 评价所属的课程和学生
+func reviewCourse(q queryExecer, reviewID int64) (courseID int, stuID string, err error) {
+	err = q.QueryRow("SELECT course_id, stuId FROM course_reviews WHERE id = ?", reviewID).Scan(&courseID, &stuID)
+	if err == sql.ErrNoRows {
+		err = ErrReviewNotFound
+	}
+	return courseID, stuID, err
+}
+
+// ModerateReview 管理员审核评价：通过后公开，拒绝后文字不公开、评分不计入，并通知学生
+func ModerateReview(db *sql.DB, reviewID int64, approve bool, note string) error {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > 500 {
+		return InputError("审核意见不能超过500个字符")
+	}
+	courseID, stuID, err := reviewCourse(db, reviewID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 与学生修改评价一样先锁定课程行
+	var locked int
+	if err := tx.QueryRow("SELECT id FROM courses WHERE id = ? FOR UPDATE", courseID).Scan(&locked); err != nil {
+		return err
+	}
+	status := ReviewRejected
+	if approve {
+		status = ReviewApproved
+	}
+	res, err := tx.Exec(
+		"UPDATE course_reviews SET status = ?, moderation_note = ?, moderated_at = ? WHERE id = ?",
+		status, note, time.Now(), reviewID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrReviewNotFound
+	}
+	if err := refreshCourseRating(tx, courseID); err != nil {
+		return err
+	}
+	if !approve {
+		content := "你的课程评价未通过审核，修改后可以重新提交。"
+		if note != "" {
+			content += "审核意见：" + note
+		}
+		if err := notify(tx, stuID, NoticeReviewRejected, "课程评价未通过审核", content, courseID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ReplyReview 教师回复课程评价并通知学生，reply 为空表示删除回复；teacherID 为0表示管理员
+func ReplyReview(db *sql.DB, teacherID int, reviewID int64, reply string) error {
+	reply = strings.TrimSpace(reply)
+	if utf8.RuneCountInString(reply) > 1000 {
+		return InputError("回复不能超过1000个字符")
+	}
+	courseID, stuID, err := reviewCourse(db, reviewID)
+	if err != nil {
+		return err
+	}
+	if err := checkCourseAccess(db, teacherID, courseID, PermReviews); err != nil {
+		return err
+	}
+
+	var repliedAt *time.Time
+	repliedBy := ""
+	if reply != "" {
+		now := time.Now()
+		repliedAt, repliedBy = &now, teacherActor(teacherID)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"UPDATE course_reviews SET reply = ?, replied_by = ?, replied_at = ? WHERE id = ?",
+		reply, repliedBy, repliedAt, reviewID,
+	); err != nil {
+		return err
+	}
+	if reply != "" {
+		if err := notify(tx, stuID, NoticeReviewReply, "教师回复了你的课程评价", reply, courseID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,473 @@
+// internal/ops/roster.go
+package ops
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cybersecurity-platform-go/internal/progress"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 名单导入每一行的结果
+const (
+	RosterEnrolled = "enrolled" // 加入课程
+	RosterCreated  = "created"  // 新建账号并加入课程
+	RosterExisting = "existing" // 已在课程中（在修或已结课），不做修改
+	RosterInvalid  = "error"    // 该行有错误，未导入
+)
+
+// rosterReason 导入名单时选课记录的变更原因
+const rosterReason = "名单导入"
+
+// RosterMaxRows 一次最多导入的学生人数
+// 新建账号需要逐个计算 bcrypt 摘要，限制人数避免单次导入耗时过长
+const RosterMaxRows = 1000
+
+// 名单文件的表头，比较时忽略大小写、空格、下划线和连字符
+var rosterHeaders = map[string][]string{
+	"stuId": {"stuid", "学号", "studentid", "学生编号"},
+	"name":  {"name", "姓名", "nickname", "名字"},
+	"email": {"email", "邮箱", "电子邮箱", "mail"},
+}
+
+// RosterImportOptions 名单导入选项
+type RosterImportOptions struct {
+	DryRun         bool // 只校验并返回每一行的结果，不写入
+	CreateAccounts bool // 为不存在的学号创建账号（该行需要邮箱），初始密码随结果返回
+}
+
+// RosterRow 名单中的一行及其导入结果
+type RosterRow struct {
+	Line     int    `json:"line"` // 文件中的行号（从1开始，含表头）
+	StuID    string `json:"stuId"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Result   string `json:"result"`
+	Error    string `json:"error,omitempty"`
+	Password string `json:"password,omitempty"` // 新建账号的初始密码，只在导入时返回一次
+}
+
+// RosterImportResult 名单导入结果
+type RosterImportResult struct {
+	DryRun   bool        `json:"dryRun"`
+	Rows     []RosterRow `json:"rows"`
+	Enrolled int         `json:"enrolled"`
+	Created  int         `json:"created"`
+	Existing int         `json:"existing"`
+	Failed   int         `json:"failed"`
+}
+
+// RosterReportRow 导出名单中的一名学生：选课记录和学习进度
+type RosterReportRow struct {
+	RosterEntry
+	Progress   progress.Rollup `json:"progress"`
+	LastActive *time.Time      `json:"lastActive"`
+}
+
+// ParseRoster 解析名单表格：第一行为表头，必须有学号列，姓名和邮箱列可选；空行忽略
+func ParseRoster(table [][]string) ([]RosterRow, error) {
+	if len(table) == 0 {
+		return nil, InputError("名单为空")
+	}
+	cols := map[string]int{}
+	for i, cell := range table[0] {
+		key := strings.NewReplacer(" ", "", "_", "", "-", "", "\ufeff", "").Replace(strings.ToLower(strings.TrimSpace(cell)))
+		for field, aliases := range rosterHeaders {
+			for _, alias := range aliases {
+				if _, seen := cols[field]; key == alias && !seen {
+					cols[field] = i
+				}
+			}
+		}
+	}
+	if _, ok := cols["stuId"]; !ok {
+		return nil, InputError("名单第一行需要表头，并包含学号列（stuId 或 学号）")
+	}
+
+	cell := func(record []string, field string) string {
+		i, ok := cols[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	var rows []RosterRow
+	for i, record := range table[1:] {
+		row := RosterRow{Line: i + 2, StuID: cell(record, "stuId"), Name: cell(record, "name"), Email: cell(record, "email")}
+		if row.StuID == "" && row.Name == "" && row.Email == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, InputError("名单中没有学生")
+	}
+	return rows, nil
+}
+
+// validateRosterRows 检查每一行的格式和文件内的重复，有错误的行标记为 RosterInvalid
+func validateRosterRows(rows []RosterRow) {
+	stuLines := map[string]int{}
+	emailLines := map[string]int{}
+	for i := range rows {
+		row := &rows[i]
+		switch {
+		case row.StuID == "":
+			row.Error = "缺少学号"
+		case utf8.RuneCountInString(row.StuID) > 50:
+			row.Error = "学号不能超过50个字符"
+		case utf8.RuneCountInString(row.Name) > 50:
+			row.Error = "姓名不能超过50个字符"
+		case row.Email != "" && (len(row.Email) > 100 || !strings.Contains(row.Email, "@")):
+			row.Error = "邮箱格式不正确"
+		case stuLines[row.StuID] > 0:
+			row.Error = fmt.Sprintf("学号与第%d行重复", stuLines[row.StuID])
+		case row.Email != "" && emailLines[strings.ToLower(row.Email)] > 0:
+			row.Error = fmt.Sprintf("邮箱与第%d行重复", emailLines[strings.ToLower(row.Email)])
+		default:
+			stuLines[row.StuID] = row.Line
+			if row.Email != "" {
+				emailLines[strings.ToLower(row.Email)] = row.Line
+			}
+			continue
+		}
+		row.Result = RosterInvalid
+	}
+}
+
+// ImportRoster 将名单中的学生加入课程，teacherID 为0表示管理员
+// 与教师手动加入学生一样不受人数限制；已退课、未通过的学生恢复为在修。
+// 有错误的行跳过并在结果中说明，其余行在同一个事务中导入；DryRun 时只返回结果不写入
+func ImportRoster(db *sql.DB, teacherID, courseID int, rows []RosterRow, opts RosterImportOptions) (*RosterImportResult, error) {
+	if len(rows) > RosterMaxRows {
+		return nil, InputError(fmt.Sprintf("一次最多导入%d名学生", RosterMaxRows))
+	}
+	validateRosterRows(rows)
+
+	var accounts map[string]rosterAccount
+	if opts.CreateAccounts && !opts.DryRun {
+		var err error
+		if accounts, err = prepareRosterAccounts(db, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if teacherID != 0 {
+		if err := checkCourseTeacher(tx, teacherID, courseID, PermStudents, true); err != nil {
+			return nil, err
+		}
+	} else {
+		var id int
+		err := tx.QueryRow("SELECT id FROM courses WHERE id = ? FOR UPDATE", courseID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return nil, ErrCourseNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	actor := teacherActor(teacherID)
+	result := &RosterImportResult{DryRun: opts.DryRun, Rows: rows}
+	for i := range rows {
+		row := &rows[i]
+		if row.Result == RosterInvalid {
+			result.Failed++
+			continue
+		}
+		if err := importRosterRow(tx, courseID, row, opts, accounts, actor, now); err != nil {
+			return nil, err
+		}
+		switch row.Result {
+		case RosterEnrolled:
+			result.Enrolled++
+		case RosterCreated:
+			result.Created++
+		case RosterExisting:
+			result.Existing++
+		default:
+			result.Failed++
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	return result, tx.Commit()
+}
+
+// rosterAccount 为名单中的新账号预先生成的初始密码和 bcrypt 摘要
+type rosterAccount struct {
+	password string
+	hashed   string
+}
+
+// prepareRosterAccounts 为尚未注册、带邮箱的行预先生成初始密码和摘要
+// 在事务和课程行锁之外执行，计算摘要期间不阻塞该课程的选课；事务中仍会重新检查账号是否存在
+func prepareRosterAccounts(db *sql.DB, rows []RosterRow) (map[string]rosterAccount, error) {
+	var stuIDs []interface{}
+	for _, row := range rows {
+		if row.Result != RosterInvalid && row.Email != "" {
+			stuIDs = append(stuIDs, row.StuID)
+		}
+	}
+	accounts := map[string]rosterAccount{}
+	if len(stuIDs) == 0 {
+		return accounts, nil
+	}
+
+	registered := map[string]bool{}
+	list, err := db.Query("SELECT stuId FROM students WHERE stuId IN (?"+strings.Repeat(", ?", len(stuIDs)-1)+")", stuIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer list.Close()
+	for list.Next() {
+		var stuID string
+		if err := list.Scan(&stuID); err != nil {
+			return nil, err
+		}
+		registered[stuID] = true
+	}
+	if err := list.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range stuIDs {
+		stuID := id.(string)
+		if registered[stuID] {
+			continue
+		}
+		password, err := randomString(passwordChars, 12)
+		if err != nil {
+			return nil, err
+		}
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("密码加密失败: %v", err)
+		}
+		accounts[stuID] = rosterAccount{password: password, hashed: string(hashed)}
+	}
+	return accounts, nil
+}
+
+// importRosterRow 导入一行，行本身的问题记录在 row.Error 中，只有数据库错误才返回
+// accounts 为事务前生成的新账号密码，DryRun 时为空
+func importRosterRow(tx *sql.Tx, courseID int, row *RosterRow, opts RosterImportOptions, accounts map[string]rosterAccount, actor string, now time.Time) error {
+	var registered bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)", row.StuID).Scan(&registered); err != nil {
+		return err
+	}
+
+	if !registered {
+		switch {
+		case !opts.CreateAccounts:
+			row.Result, row.Error = RosterInvalid, "学生账号不存在"
+			return nil
+		case row.Email == "":
+			row.Result, row.Error = RosterInvalid, "学生账号不存在，创建账号需要邮箱"
+			return nil
+		}
+		var taken bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM students WHERE email = ?)", row.Email).Scan(&taken); err != nil {
+			return err
+		}
+		if taken {
+			row.Result, row.Error = RosterInvalid, "邮箱已被其他账号使用"
+			return nil
+		}
+		if opts.DryRun {
+			row.Result = RosterCreated
+			return nil
+		}
+		account, ok := accounts[row.StuID]
+		if !ok {
+			// 生成密码时账号已存在，之后又被删除
+			row.Result, row.Error = RosterInvalid, "学生账号在导入过程中发生变化，请重新导入"
+			return nil
+		}
+		row.Result = RosterCreated
+
+		nickName := row.Name
+		if nickName == "" {
+			nickName = row.StuID
+		}
+		if err := insertUser(tx, NewUser{StuID: row.StuID, Email: row.Email, NickName: nickName, UserName: row.Name}, account.hashed); err != nil {
+			return err
+		}
+		row.Password = account.password
+		return activateEnrollment(tx, row.StuID, courseID, "", actor, rosterReason, now)
+	}
+
+	from, err := enrollmentStatus(tx, row.StuID, courseID)
+	if err != nil {
+		return err
+	}
+	if from == EnrollActive || from == EnrollCompleted {
+		row.Result = RosterExisting
+		return nil
+	}
+	row.Result = RosterEnrolled
+	if opts.DryRun {
+		return nil
+	}
+	return activateEnrollment(tx, row.StuID, courseID, from, actor, rosterReason, now)
+}
+
+// passwordChars 初始密码使用的字符，去掉了容易混淆的 0/O、1/l/I
+const passwordChars = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// randomString 用 chars 中的字符生成长度为 n 的随机字符串（初始密码、邀请码）
+func randomString(chars string, n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(chars)))
+	for i := range b {
+		k, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = chars[k.Int64()]
+	}
+	return string(b), nil
+}
+
+// CourseRosterReport 课程名单及每名学生的学习进度（有视频的课时），teacherID 为0表示管理员
+func CourseRosterReport(db *sql.DB, teacherID, courseID int) ([]RosterReportRow, error) {
+	roster, err := CourseRoster(db, teacherID, courseID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// 课程中有视频的课时及视频时长
+	lessonRows, err := db.Query(`
+		SELECT cc.id, COALESCE(v.duration, 0)
+		FROM chapters ch
+		JOIN chapter_children cc ON cc.chapter_id = ch.id
+		LEFT JOIN videos v ON cc.video_id = v.id
+		WHERE ch.course_id = ? AND cc.video_id IS NOT NULL
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	durations := map[int]float64{}
+	for lessonRows.Next() {
+		var id int
+		var duration float64
+		if err := lessonRows.Scan(&id, &duration); err != nil {
+			lessonRows.Close()
+			return nil, err
+		}
+		durations[id] = duration
+	}
+	lessonRows.Close()
+	if err := lessonRows.Err(); err != nil {
+		return nil, err
+	}
+
+	type lessonState struct {
+		completed bool
+		ratio     float64
+	}
+	states := map[string]map[int]lessonState{}
+	lastActive := map[string]time.Time{}
+	rows, err := db.Query(
+		"SELECT stuId, lesson_id, completed, watched_seconds, duration, updated_at FROM lesson_progress WHERE course_id = ?", courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var stuID string
+		var lessonID int
+		var completed bool
+		var watched, duration float64
+		var updated sql.NullTime
+		if err := rows.Scan(&stuID, &lessonID, &completed, &watched, &duration, &updated); err != nil {
+			return nil, err
+		}
+		if updated.Valid && updated.Time.After(lastActive[stuID]) {
+			lastActive[stuID] = updated.Time
+		}
+		videoDuration, ok := durations[lessonID]
+		if !ok {
+			continue
+		}
+		if duration <= 0 {
+			duration = videoDuration
+		}
+		if states[stuID] == nil {
+			states[stuID] = map[int]lessonState{}
+		}
+		states[stuID][lessonID] = lessonState{completed, progress.Ratio(watched, duration)}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := make([]RosterReportRow, 0, len(roster))
+	for _, entry := range roster {
+		r := RosterReportRow{RosterEntry: entry}
+		for lessonID := range durations {
+			s := states[entry.StuID][lessonID]
+			r.Progress.Add(s.completed, s.ratio)
+		}
+		if t, ok := lastActive[entry.StuID]; ok {
+			r.LastActive = &t
+		}
+		report = append(report, r)
+	}
+	return report, nil
+}
+
+// rosterStatusLabels 导出时显示的选课状态
+var rosterStatusLabels = map[string]string{
+	EnrollActive:    "在修",
+	EnrollDropped:   "已退课",
+	EnrollCompleted: "已结课",
+	EnrollFailed:    "未通过",
+}
+
+// RosterTable 将名单报表转换为表格（第一行为表头），成绩按结课结果填写
+func RosterTable(report []RosterReportRow) [][]string {
+	table := [][]string{{"学号", "姓名", "邮箱", "状态", "加入时间", "结束时间", "完成课时", "总课时", "学习进度(%)", "最近学习", "成绩"}}
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04")
+	}
+	for _, r := range report {
+		grade := ""
+		switch r.Status {
+		case EnrollCompleted:
+			grade = "通过"
+		case EnrollFailed:
+			grade = "未通过"
+		}
+		status := rosterStatusLabels[r.Status]
+		if status == "" {
+			status = r.Status
+		}
+		table = append(table, []string{
+			r.StuID, r.NickName, r.Email, status, formatTime(&r.JoinedAt), formatTime(r.EndedAt),
+			strconv.Itoa(r.Progress.Completed), strconv.Itoa(r.Progress.Total), strconv.Itoa(r.Progress.Percent),
+			formatTime(r.LastActive), grade,
+		})
+	}
+	return table
+}
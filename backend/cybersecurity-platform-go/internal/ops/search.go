@@ -0,0 +1,126 @@
+// internal/ops/search.go
+package ops
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode/utf8"
+)
+
+// searchIndexer *sql.DB 和 *sql.Tx 的公共方法，课程变更时在同一事务中更新检索索引
+type searchIndexer interface {
+	queryExecer
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// ReindexCourse 重建一门课程的检索索引（course_search），课程不存在时删除索引
+func ReindexCourse(db *sql.DB, courseID int) error {
+	return reindexCourse(db, courseID)
+}
+
+func reindexCourse(q searchIndexer, courseID int) error {
+	var title string
+	var description sql.NullString
+	err := q.QueryRow("SELECT title, description FROM courses WHERE id = ?", courseID).Scan(&title, &description)
+	if err == sql.ErrNoRows {
+		_, err = q.Exec("DELETE FROM course_search WHERE course_id = ?", courseID)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	teachers, err := collectStrings(q, `
+		SELECT t.name FROM teacher_courses tc
+		JOIN teachers t ON tc.teacher_id = t.id
+		WHERE tc.course_id = ?
+		ORDER BY FIELD(tc.role, 'lead', 'co_teacher', 'ta'), tc.sort_order, tc.id
+	`, courseID)
+	if err != nil {
+		return err
+	}
+	// 章节标题和课时标题按显示顺序排列，每行一个
+	outline, err := collectStrings(q, `
+		SELECT title FROM (
+			SELECT ch.title, ch.sort_order AS chapter_order, ch.id AS chapter_id, -1 AS lesson_order, 0 AS lesson_id
+			FROM chapters ch WHERE ch.course_id = ?
+			UNION ALL
+			SELECT cc.title, ch.sort_order, ch.id, cc.sort_order, cc.id
+			FROM chapter_children cc JOIN chapters ch ON cc.chapter_id = ch.id
+			WHERE ch.course_id = ?
+		) t
+		ORDER BY chapter_order, chapter_id, lesson_order, lesson_id
+	`, courseID, courseID)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(`
+		REPLACE INTO course_search (course_id, title, teachers, outline, description)
+		VALUES (?, ?, ?, ?, ?)
+	`, courseID, title, truncateRunes(strings.Join(teachers, " "), 500), strings.Join(outline, "\n"), description.String)
+	return err
+}
+
+// ReindexCourses 重建检索索引，all 为 false 时只补建还没有索引的课程，返回处理的课程数
+func ReindexCourses(ctx context.Context, db *sql.DB, all bool) (int, error) {
+	query := "SELECT id FROM courses"
+	if !all {
+		query = "SELECT c.id FROM courses c LEFT JOIN course_search cs ON cs.course_id = c.id WHERE cs.course_id IS NULL"
+	}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if err := reindexCourse(db, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// collectStrings 查询单列字符串
+func collectStrings(q searchIndexer, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// truncateRunes 截断到最多 n 个字符
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,58 @@
+// internal/ops/session.go
+package ops
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrSessionInvalid 会话令牌不存在、已过期或账号已被禁用
+var ErrSessionInvalid = errors.New("登录已失效，请重新登录")
+
+// StudentSessionTTL 学生登录会话的有效期；服务启动时根据配置设置
+var StudentSessionTTL = 7 * 24 * time.Hour
+
+// IssueStudentSession 学生登录成功后签发会话令牌，同时清理该学生已过期的会话
+// 数据库只保存令牌的摘要（与教师令牌相同），令牌本身只在登录时返回一次
+func IssueStudentSession(db *sql.DB, stuID string, now time.Time) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	if _, err := db.Exec("DELETE FROM student_sessions WHERE stuId = ? AND expires_at <= ?", stuID, now); err != nil {
+		return "", err
+	}
+	if _, err := db.Exec(
+		"INSERT INTO student_sessions (token_hash, stuId, created_at, expires_at) VALUES (?, ?, ?, ?)",
+		TeacherTokenHash(token), stuID, now, now.Add(StudentSessionTTL),
+	); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// StudentSession 返回会话令牌对应的学号，已禁用的账号视为会话无效
+func StudentSession(db *sql.DB, token string, now time.Time) (string, error) {
+	var stuID string
+	err := db.QueryRow(`
+		SELECT s.stuId FROM student_sessions s
+		JOIN students st ON st.stuId = s.stuId
+		WHERE s.token_hash = ? AND s.expires_at > ? AND st.disabled = 0`,
+		TeacherTokenHash(token), now,
+	).Scan(&stuID)
+	if err == sql.ErrNoRows {
+		return "", ErrSessionInvalid
+	}
+	return stuID, err
+}
+
+// RevokeStudentSession 退出登录，删除会话令牌
+func RevokeStudentSession(db *sql.DB, token string) error {
+	_, err := db.Exec("DELETE FROM student_sessions WHERE token_hash = ?", TeacherTokenHash(token))
+	return err
+}
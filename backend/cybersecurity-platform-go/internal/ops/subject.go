@@ -0,0 +1,383 @@
+// internal/ops/subject.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrSubjectNotFound 分类不存在
+	ErrSubjectNotFound = errors.New("分类不存在")
+	// ErrSubjectHasChildren 分类下还有子分类，不能删除
+	ErrSubjectHasChildren = errors.New("请先删除或移走子分类")
+)
+
+// Subject 课程分类树的节点
+// CateID 与 ID 相同，前端按 cateId 筛选课程；Count 为该分类及其子分类下的课程数（同一课程只计一次）
+type Subject struct {
+	ID       int        `json:"id"`
+	CateID   int        `json:"cateId"`
+	ParentID int        `json:"parentId"` // 0 表示顶级分类
+	Title    string     `json:"title"`
+	Sort     int        `json:"sort"`
+	Count    int        `json:"count"`
+	Children []*Subject `json:"children"`
+}
+
+// SubjectInput 创建或修改分类的字段，为 nil 的字段在修改时保持不变；ParentID 为0表示顶级分类
+type SubjectInput struct {
+	Title    *string `json:"title"`
+	ParentID *int    `json:"parentId"`
+	Sort     *int    `json:"sort"`
+}
+
+// SubtreeIDs 分类及其全部子分类的ID
+func (s *Subject) SubtreeIDs() []int {
+	ids := []int{s.ID}
+	for _, child := range s.Children {
+		ids = append(ids, child.SubtreeIDs()...)
+	}
+	return ids
+}
+
+// BuildSubjectTree 由分类列表（已按显示顺序排列）和各分类直接关联的课程构造分类树并统计课程数
+// 父分类不存在的分类作为顶级分类
+func BuildSubjectTree(flat []Subject, courses map[int][]int) []*Subject {
+	nodes := make(map[int]*Subject, len(flat))
+	for i := range flat {
+		s := flat[i]
+		s.CateID = s.ID
+		s.Children = []*Subject{}
+		nodes[s.ID] = &s
+	}
+
+	roots := []*Subject{}
+	for i := range flat {
+		s := nodes[flat[i].ID]
+		if parent := nodes[s.ParentID]; parent != nil && s.ParentID != s.ID {
+			parent.Children = append(parent.Children, s)
+		} else {
+			s.ParentID = 0
+			roots = append(roots, s)
+		}
+	}
+
+	var count func(s *Subject) map[int]bool
+	count = func(s *Subject) map[int]bool {
+		set := make(map[int]bool)
+		for _, id := range courses[s.ID] {
+			set[id] = true
+		}
+		for _, child := range s.Children {
+			for id := range count(child) {
+				set[id] = true
+			}
+		}
+		s.Count = len(set)
+		return set
+	}
+	for _, root := range roots {
+		count(root)
+	}
+	return roots
+}
+
+// SubjectTree 查询分类树；publishedOnly 为 true 时只统计已发布的课程（学生端）
+func SubjectTree(db *sql.DB, publishedOnly bool) ([]*Subject, error) {
+	flat, err := loadSubjects(db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT cs.subject_id, cs.course_id FROM course_subjects cs"
+	if publishedOnly {
+		query += " JOIN courses c ON cs.course_id = c.id WHERE c.status = '" + CoursePublished + "'"
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := make(map[int][]int)
+	for rows.Next() {
+		var subjectID, courseID int
+		if err := rows.Scan(&subjectID, &courseID); err != nil {
+			return nil, err
+		}
+		courses[subjectID] = append(courses[subjectID], courseID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return BuildSubjectTree(flat, courses), nil
+}
+
+// SubjectSubtree 分类及其全部子分类的ID，用于按分类筛选课程
+func SubjectSubtree(db *sql.DB, subjectID int) ([]int, error) {
+	flat, err := loadSubjects(db)
+	if err != nil {
+		return nil, err
+	}
+	var find func(nodes []*Subject) *Subject
+	find = func(nodes []*Subject) *Subject {
+		for _, s := range nodes {
+			if s.ID == subjectID {
+				return s
+			}
+			if found := find(s.Children); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	s := find(BuildSubjectTree(flat, nil))
+	if s == nil {
+		return nil, ErrSubjectNotFound
+	}
+	return s.SubtreeIDs(), nil
+}
+
+func loadSubjects(db *sql.DB) ([]Subject, error) {
+	rows, err := db.Query("SELECT id, COALESCE(parent_id, 0), title, sort_order FROM subjects ORDER BY sort_order, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flat := []Subject{}
+	for rows.Next() {
+		var s Subject
+		if err := rows.Scan(&s.ID, &s.ParentID, &s.Title, &s.Sort); err != nil {
+			return nil, err
+		}
+		flat = append(flat, s)
+	}
+	return flat, rows.Err()
+}
+
+// CreateSubject 创建分类
+func CreateSubject(db *sql.DB, in SubjectInput) (int64, error) {
+	if in.Title == nil {
+		return 0, InputError("分类名称不能为空")
+	}
+	title, err := subjectTitle(*in.Title)
+	if err != nil {
+		return 0, err
+	}
+	parentID, sort := 0, 0
+	if in.ParentID != nil {
+		parentID = *in.ParentID
+	}
+	if in.Sort != nil {
+		sort = *in.Sort
+	}
+	if err := checkSubjectParent(db, 0, parentID); err != nil {
+		return 0, err
+	}
+	if err := checkSubjectTitle(db, 0, parentID, title); err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(
+		"INSERT INTO subjects (parent_id, title, sort_order) VALUES (?, ?, ?)",
+		nullableID(parentID), title, sort,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateSubject 修改分类名称、上级分类和显示顺序；不能移动到自己或自己的子分类下
+func UpdateSubject(db *sql.DB, subjectID int, in SubjectInput) error {
+	var parentID int
+	var title string
+	err := db.QueryRow("SELECT COALESCE(parent_id, 0), title FROM subjects WHERE id = ?", subjectID).Scan(&parentID, &title)
+	if err == sql.ErrNoRows {
+		return ErrSubjectNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if in.Title != nil {
+		if title, err = subjectTitle(*in.Title); err != nil {
+			return err
+		}
+	}
+	if in.ParentID != nil {
+		parentID = *in.ParentID
+		if err := checkSubjectParent(db, subjectID, parentID); err != nil {
+			return err
+		}
+	}
+	if in.Title != nil || in.ParentID != nil {
+		if err := checkSubjectTitle(db, subjectID, parentID, title); err != nil {
+			return err
+		}
+	}
+
+	sets := []string{"title = ?", "parent_id = ?"}
+	args := []interface{}{title, nullableID(parentID)}
+	if in.Sort != nil {
+		sets, args = append(sets, "sort_order = ?"), append(args, *in.Sort)
+	}
+	_, err = db.Exec("UPDATE subjects SET "+strings.Join(sets, ", ")+" WHERE id = ?", append(args, subjectID)...)
+	return err
+}
+
+// DeleteSubject 删除没有子分类的分类，课程与它的关联一并删除
+func DeleteSubject(db *sql.DB, subjectID int) error {
+	var hasChildren bool
+	err := db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM subjects WHERE parent_id = ?) FROM subjects WHERE id = ?", subjectID, subjectID,
+	).Scan(&hasChildren)
+	if err == sql.ErrNoRows {
+		return ErrSubjectNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return ErrSubjectHasChildren
+	}
+	_, err = db.Exec("DELETE FROM subjects WHERE id = ?", subjectID)
+	return err
+}
+
+// SetCourseSubjects 设置课程所属的分类（整体替换），teacherID 为0表示管理员操作
+func SetCourseSubjects(db *sql.DB, teacherID, courseID int, subjectIDs []int) error {
+	seen := make(map[int]bool, len(subjectIDs))
+	ids := []int{}
+	for _, id := range subjectIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 10 {
+		return InputError("一门课程最多属于10个分类")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if teacherID != 0 {
+		if err := checkCourseTeacher(tx, teacherID, courseID, PermContent, true); err != nil {
+			return err
+		}
+	} else {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM courses WHERE id = ?)", courseID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCourseNotFound
+		}
+	}
+
+	if len(ids) > 0 {
+		args := make([]interface{}, len(ids))
+		for i, id := range ids {
+			args[i] = id
+		}
+		var found int
+		if err := tx.QueryRow(
+			"SELECT COUNT(*) FROM subjects WHERE id IN (?"+strings.Repeat(", ?", len(ids)-1)+")", args...,
+		).Scan(&found); err != nil {
+			return err
+		}
+		if found != len(ids) {
+			return ErrSubjectNotFound
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM course_subjects WHERE course_id = ?", courseID); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := tx.Exec("INSERT INTO course_subjects (course_id, subject_id) VALUES (?, ?)", courseID, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// courseSubjectIDs 课程所属的分类ID
+func courseSubjectIDs(db *sql.DB, courseID int) ([]int, error) {
+	rows, err := db.Query("SELECT subject_id FROM course_subjects WHERE course_id = ? ORDER BY subject_id", courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// checkSubjectParent 检查上级分类存在，并且不是 subjectID 自己或它的子分类
+func checkSubjectParent(db *sql.DB, subjectID, parentID int) error {
+	for id, depth := parentID, 0; id != 0; depth++ {
+		if id == subjectID || depth > 100 {
+			return InputError("不能把分类移动到它自己或它的子分类下")
+		}
+		var next sql.NullInt64
+		err := db.QueryRow("SELECT parent_id FROM subjects WHERE id = ?", id).Scan(&next)
+		if err == sql.ErrNoRows {
+			return ErrSubjectNotFound
+		}
+		if err != nil {
+			return err
+		}
+		id = int(next.Int64)
+	}
+	return nil
+}
+
+// checkSubjectTitle 同一上级分类下名称不能重复
+func checkSubjectTitle(db *sql.DB, subjectID, parentID int, title string) error {
+	var exists bool
+	if err := db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM subjects WHERE parent_id <=> ? AND title = ? AND id <> ?)",
+		nullableID(parentID), title, subjectID,
+	).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return InputError("同一上级分类下已有同名分类")
+	}
+	return nil
+}
+
+func subjectTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", InputError("分类名称不能为空")
+	}
+	if utf8.RuneCountInString(title) > 50 {
+		return "", InputError("分类名称不能超过50个字符")
+	}
+	return title, nil
+}
+
+// nullableID 0 写入为 NULL
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
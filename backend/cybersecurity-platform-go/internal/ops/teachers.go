@@ -0,0 +1,42 @@
+// internal/ops/teachers.go
+package ops
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrTeacherNotFound 教师不存在
+var ErrTeacherNotFound = errors.New("教师不存在")
+
+// IssueTeacherToken 为教师签发新的接口令牌（旧令牌立即失效）
+// 数据库只保存令牌的摘要，令牌本身只在签发时返回一次
+func IssueTeacherToken(db *sql.DB, teacherID int) (string, error) {
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM teachers WHERE id = ?)", teacherID).Scan(&exists); err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", ErrTeacherNotFound
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	if _, err := db.Exec("UPDATE teachers SET token_hash = ? WHERE id = ?", TeacherTokenHash(token), teacherID); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// TeacherTokenHash 计算令牌摘要
+func TeacherTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
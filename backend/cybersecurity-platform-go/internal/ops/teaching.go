@@ -0,0 +1,366 @@
+// internal/ops/teaching.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 课程中的教师角色，显示时按此顺序排列
+const (
+	RoleLead      = "lead"       // 负责人，拥有全部权限
+	RoleCoTeacher = "co_teacher" // 合作教师
+	RoleTA        = "ta"         // 助教
+)
+
+// 教师在课程中的权限，合作教师和助教的权限可以配置
+const (
+	PermContent  = "content"  // 修改课程信息、分类、先修规则、章节和课时
+	PermStudents = "students" // 管理学生名单、选课申请和邀请码
+	PermReviews  = "reviews"  // 查看和回复课程评价
+	PermPublish  = "publish"  // 提交审核、撤回和归档课程
+	// PermManage 删除课程、设置课程教师和权限，只有负责人和管理员可以，不能配置给其他角色
+	PermManage = "manage"
+)
+
+// teacherOrder 课程教师的显示顺序：负责人、合作教师、助教，同一角色按 sort_order
+const teacherOrder = "FIELD(tc.role, 'lead', 'co_teacher', 'ta'), tc.sort_order, tc.id"
+
+// configurablePermissions 可以配置给合作教师和助教的权限
+var configurablePermissions = []string{PermContent, PermStudents, PermReviews, PermPublish}
+
+// DefaultRolePermissions 课程没有单独设置时合作教师和助教的权限；服务启动时根据配置设置
+var DefaultRolePermissions = map[string][]string{
+	RoleCoTeacher: {PermContent, PermStudents, PermReviews},
+	RoleTA:        {PermStudents},
+}
+
+// ErrNoCoursePermission 教师在课程中的角色没有该项权限
+var ErrNoCoursePermission = errors.New("你在该课程中的角色没有此项权限")
+
+// CourseTeacher 课程的一名教师
+type CourseTeacher struct {
+	TeacherID int    `json:"teacherId"`
+	Name      string `json:"teacherName"`
+	Career    string `json:"career"`
+	Intro     string `json:"intro"`
+	Role      string `json:"role"`
+}
+
+// CourseTeam 课程的教师（按显示顺序）和合作教师、助教当前的权限
+type CourseTeam struct {
+	Teachers    []CourseTeacher     `json:"teachers"`
+	Permissions map[string][]string `json:"permissions"`
+}
+
+// CourseTeacherInput 设置课程教师时的一项，数组顺序即显示顺序
+type CourseTeacherInput struct {
+	TeacherID int    `json:"teacherId"`
+	Role      string `json:"role"`
+}
+
+// validRole 是否为有效的课程角色
+func validRole(role string) bool {
+	return role == RoleLead || role == RoleCoTeacher || role == RoleTA
+}
+
+// ParsePermissions 解析逗号分隔的权限列表（去重并校验），用于配置和接口
+func ParsePermissions(s string) ([]string, error) {
+	var list []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			list = append(list, p)
+		}
+	}
+	return normalizePermissions(list)
+}
+
+// normalizePermissions 按 configurablePermissions 的顺序去重，出现不能配置的权限时返回错误
+func normalizePermissions(list []string) ([]string, error) {
+	set := map[string]bool{}
+	for _, p := range list {
+		ok := false
+		for _, c := range configurablePermissions {
+			ok = ok || p == c
+		}
+		if !ok {
+			return nil, InputError(fmt.Sprintf("无效的权限：%s（可选 %s）", p, strings.Join(configurablePermissions, "、")))
+		}
+		set[p] = true
+	}
+	out := []string{}
+	for _, c := range configurablePermissions {
+		if set[c] {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// rolePermits 角色是否有 perm 权限；custom 为课程单独设置的权限（逗号分隔），无效时使用默认值
+// perm 为空表示只要求是课程的教师
+func rolePermits(role string, custom sql.NullString, perm string) bool {
+	if perm == "" || role == RoleLead {
+		return true
+	}
+	if perm == PermManage {
+		return false
+	}
+	perms := DefaultRolePermissions[role]
+	if custom.Valid {
+		perms = strings.Split(custom.String, ",")
+	}
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCourseTeacher 检查教师是否讲授该课程（teacher_courses），并且在课程中的角色有 perm 权限
+// lock 为 true 时锁定课程行，同一课程的结构修改串行执行，保证 lesson_num 准确
+func checkCourseTeacher(q queryExecer, teacherID, courseID int, perm string, lock bool) error {
+	query := `
+		SELECT tc.role, rp.permissions FROM courses c
+		LEFT JOIN teacher_courses tc ON tc.teacher_id = ? AND tc.course_id = c.id
+		LEFT JOIN course_role_permissions rp ON rp.course_id = c.id AND rp.role = tc.role
+		WHERE c.id = ?`
+	if lock {
+		query += " FOR UPDATE"
+	}
+	var role, custom sql.NullString
+	err := q.QueryRow(query, teacherID, courseID).Scan(&role, &custom)
+	if err == sql.ErrNoRows {
+		return ErrCourseNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !role.Valid {
+		return ErrNotCourseTeacher
+	}
+	if !rolePermits(role.String, custom, perm) {
+		return ErrNoCoursePermission
+	}
+	return nil
+}
+
+// teacherCourseIDs 教师在其中有 perm 权限的课程
+func teacherCourseIDs(db *sql.DB, teacherID int, perm string) ([]int, error) {
+	rows, err := db.Query(`
+		SELECT tc.course_id, tc.role, rp.permissions FROM teacher_courses tc
+		LEFT JOIN course_role_permissions rp ON rp.course_id = tc.course_id AND rp.role = tc.role
+		WHERE tc.teacher_id = ?
+	`, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		var role string
+		var custom sql.NullString
+		if err := rows.Scan(&id, &role, &custom); err != nil {
+			return nil, err
+		}
+		if rolePermits(role, custom, perm) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// CanEditVideo 教师是否可以管理视频（字幕等）：自己上传的视频，或用在自己有修改内容权限的课程中的视频
+// 视频不存在时返回 sql.ErrNoRows
+func CanEditVideo(db *sql.DB, teacherID, videoID int) (bool, error) {
+	var owner sql.NullInt64
+	if err := db.QueryRow("SELECT teacher_id FROM videos WHERE id = ?", videoID).Scan(&owner); err != nil {
+		return false, err
+	}
+	if owner.Valid && int(owner.Int64) == teacherID {
+		return true, nil
+	}
+	rows, err := db.Query(`
+		SELECT DISTINCT tc.role, rp.permissions FROM chapter_children cc
+		JOIN chapters ch ON cc.chapter_id = ch.id
+		JOIN teacher_courses tc ON tc.course_id = ch.course_id AND tc.teacher_id = ?
+		LEFT JOIN course_role_permissions rp ON rp.course_id = tc.course_id AND rp.role = tc.role
+		WHERE cc.video_id = ?
+	`, teacherID, videoID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var role string
+		var custom sql.NullString
+		if err := rows.Scan(&role, &custom); err != nil {
+			return false, err
+		}
+		if rolePermits(role, custom, PermContent) {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// CourseTeachers 课程的教师，按显示顺序排列
+func CourseTeachers(db *sql.DB, courseID int) ([]CourseTeacher, error) {
+	rows, err := db.Query(`
+		SELECT t.id, t.name, t.career, t.intro, tc.role
+		FROM teacher_courses tc
+		JOIN teachers t ON tc.teacher_id = t.id
+		WHERE tc.course_id = ?
+		ORDER BY `+teacherOrder, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []CourseTeacher{}
+	for rows.Next() {
+		var t CourseTeacher
+		var career, intro sql.NullString
+		if err := rows.Scan(&t.TeacherID, &t.Name, &career, &intro, &t.Role); err != nil {
+			return nil, err
+		}
+		t.Career, t.Intro = career.String, intro.String
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// CourseTeamFor 课程的教师和角色权限，课程的任何教师都可以查看，teacherID 为0表示管理员
+func CourseTeamFor(db *sql.DB, teacherID, courseID int) (*CourseTeam, error) {
+	if err := checkCourseAccess(db, teacherID, courseID, ""); err != nil {
+		return nil, err
+	}
+	teachers, err := CourseTeachers(db, courseID)
+	if err != nil {
+		return nil, err
+	}
+	team := &CourseTeam{Teachers: teachers, Permissions: map[string][]string{}}
+	for _, role := range []string{RoleCoTeacher, RoleTA} {
+		team.Permissions[role] = append([]string{}, DefaultRolePermissions[role]...)
+	}
+
+	rows, err := db.Query("SELECT role, permissions FROM course_role_permissions WHERE course_id = ?", courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var role, perms string
+		if err := rows.Scan(&role, &perms); err != nil {
+			return nil, err
+		}
+		team.Permissions[role], _ = ParsePermissions(perms)
+	}
+	return team, rows.Err()
+}
+
+// SetCourseTeachers 设置课程的教师和角色（整体替换），数组顺序即显示顺序；至少要有一名负责人
+// 只有负责人和管理员（teacherID 为0）可以设置
+func SetCourseTeachers(db *sql.DB, teacherID, courseID int, list []CourseTeacherInput) error {
+	seen := map[int]bool{}
+	hasLead := false
+	for _, t := range list {
+		if t.TeacherID <= 0 {
+			return InputError("无效的教师ID")
+		}
+		if !validRole(t.Role) {
+			return InputError("无效的角色：" + t.Role)
+		}
+		if seen[t.TeacherID] {
+			return InputError(fmt.Sprintf("教师 %d 重复", t.TeacherID))
+		}
+		seen[t.TeacherID] = true
+		hasLead = hasLead || t.Role == RoleLead
+	}
+	if !hasLead {
+		return InputError("课程至少需要一名负责人")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if teacherID != 0 {
+		if err := checkCourseTeacher(tx, teacherID, courseID, PermManage, true); err != nil {
+			return err
+		}
+	} else {
+		var id int
+		err := tx.QueryRow("SELECT id FROM courses WHERE id = ? FOR UPDATE", courseID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return ErrCourseNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, t := range list {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM teachers WHERE id = ?)", t.TeacherID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return InputError(fmt.Sprintf("教师 %d 不存在", t.TeacherID))
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM teacher_courses WHERE course_id = ?", courseID); err != nil {
+		return err
+	}
+	for i, t := range list {
+		if _, err := tx.Exec(
+			"INSERT INTO teacher_courses (teacher_id, course_id, role, sort_order) VALUES (?, ?, ?, ?)",
+			t.TeacherID, courseID, t.Role, i,
+		); err != nil {
+			return err
+		}
+	}
+	// 教师姓名是课程检索的字段之一
+	if err := reindexCourse(tx, courseID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetRolePermissions 设置课程中合作教师或助教的权限，perms 为 nil 表示恢复默认值
+// 只有负责人和管理员（teacherID 为0）可以设置
+func SetRolePermissions(db *sql.DB, teacherID, courseID int, role string, perms []string) error {
+	if role != RoleCoTeacher && role != RoleTA {
+		return InputError("只能设置合作教师（co_teacher）和助教（ta）的权限")
+	}
+	if teacherID != 0 {
+		if err := checkCourseTeacher(db, teacherID, courseID, PermManage, false); err != nil {
+			return err
+		}
+	} else if err := checkCourseAccess(db, 0, courseID, ""); err != nil {
+		return err
+	}
+
+	if perms == nil {
+		_, err := db.Exec("DELETE FROM course_role_permissions WHERE course_id = ? AND role = ?", courseID, role)
+		return err
+	}
+	perms, err := normalizePermissions(perms)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO course_role_permissions (course_id, role, permissions, updated_at) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE permissions = VALUES(permissions), updated_at = VALUES(updated_at)
+	`, courseID, role, strings.Join(perms, ","), time.Now())
+	return err
+}
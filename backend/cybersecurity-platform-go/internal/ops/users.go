@@ -0,0 +1,123 @@
+// internal/ops/users.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUserNotFound 学生账号不存在
+var ErrUserNotFound = errors.New("学生不存在")
+
+// 注册接口使用的默认头像
+const defaultUserHead = "https://tse3-mm.cn.bing.net/th/id/OIP-C.qidgOqAsPEdzAg5inmSK3AAAAA?rs=1&pid=ImgDetMain"
+
+// NewUser 创建账号所需信息
+type NewUser struct {
+	StuID    string
+	Email    string
+	Password string
+	NickName string
+	UserName string
+}
+
+// CreateUser 创建学生账号（students + userdetail），与注册接口写入的数据一致
+func CreateUser(db *sql.DB, u NewUser) error {
+	if u.StuID == "" || u.Email == "" || u.Password == "" {
+		return errors.New("学号、邮箱和密码都是必需的")
+	}
+	if u.NickName == "" {
+		u.NickName = u.StuID
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ? OR email = ?)", u.StuID, u.Email,
+	).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("学号 %s 或邮箱 %s 已被注册", u.StuID, u.Email)
+	}
+
+	if err := insertUser(tx, u, string(hashed)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertUser 写入 students 和 userdetail，hashed 为 bcrypt 摘要
+func insertUser(q queryExecer, u NewUser, hashed string) error {
+	if _, err := q.Exec(
+		"INSERT INTO students (stuId, password, email) VALUES (?, ?, ?)",
+		u.StuID, hashed, u.Email,
+	); err != nil {
+		return err
+	}
+
+	_, err := q.Exec(
+		"INSERT INTO userdetail (stuId, nickName, userHead, userName, userEmail) VALUES (?, ?, ?, ?, ?)",
+		u.StuID, u.NickName, defaultUserHead, u.UserName, u.Email,
+	)
+	return err
+}
+
+// SetUserDisabled 禁用或启用学生账号
+func SetUserDisabled(db *sql.DB, stuID string, disabled bool) error {
+	if err := requireUser(db, stuID); err != nil {
+		return err
+	}
+
+	_, err := db.Exec("UPDATE students SET disabled = ? WHERE stuId = ?", disabled, stuID)
+	return err
+}
+
+// ResetPassword 重置学生密码，同时注销该学生的所有登录会话
+func ResetPassword(db *sql.DB, stuID, password string) error {
+	if password == "" {
+		return errors.New("新密码不能为空")
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %v", err)
+	}
+
+	if err := requireUser(db, stuID); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec("UPDATE students SET password = ? WHERE stuId = ?", string(hashed), stuID); err != nil {
+		return err
+	}
+	// 重置密码后已登录的会话全部失效
+	_, err = db.Exec("DELETE FROM student_sessions WHERE stuId = ?", stuID)
+	return err
+}
+
+// requireUser 学生不存在时返回 ErrUserNotFound
+// 不能依赖 UPDATE 的影响行数判断，MySQL 在值未变化时返回0
+func requireUser(db *sql.DB, stuID string) error {
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)", stuID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// internal/ops/videos.go
+package ops
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cybersecurity-platform-go/internal/mediainfo"
+)
+
+// 本服务提供的视频地址前缀，对应 VideoDirs 中的文件
+const videoURLPrefix = "/api/videoing/"
+
+// BackfillStats 元数据补全结果
+type BackfillStats struct {
+	Updated int // 已更新
+	Skipped int // 外部地址（CDN等），无法在本地解析
+	Missing int // 在视频目录中找不到文件
+	Invalid int // 文件不是有效的视频
+}
+
+// BackfillVideoMetadata 解析视频目录中的文件，补全 videos 表的时长、分辨率、编码和码率
+// 默认只处理尚未解析过的视频（video_codec 为空），force 为 true 时重新解析全部视频；
+// progress 不为 nil 时每处理一个视频调用一次（err 为找不到文件或解析失败的原因）
+func BackfillVideoMetadata(db *sql.DB, dirs []string, force, dryRun bool, progress func(id int, videoURL string, info *mediainfo.Info, err error)) (BackfillStats, error) {
+	var stats BackfillStats
+
+	query := "SELECT id, url FROM videos"
+	if !force {
+		query += " WHERE video_codec = ''"
+	}
+	rows, err := db.Query(query + " ORDER BY id")
+	if err != nil {
+		return stats, err
+	}
+	type video struct {
+		id  int
+		url string
+	}
+	var videos []video
+	for rows.Next() {
+		var v video
+		if err := rows.Scan(&v.id, &v.url); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		videos = append(videos, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	for _, v := range videos {
+		file, ok := localVideoFile(v.url, dirs)
+		if !ok {
+			stats.Skipped++
+			continue
+		}
+		if file == "" {
+			stats.Missing++
+			if progress != nil {
+				progress(v.id, v.url, nil, os.ErrNotExist)
+			}
+			continue
+		}
+
+		info, err := mediainfo.ProbeFile(file)
+		if err != nil {
+			if !errors.Is(err, mediainfo.ErrInvalidContainer) {
+				return stats, err
+			}
+			stats.Invalid++
+			if progress != nil {
+				progress(v.id, v.url, nil, err)
+			}
+			continue
+		}
+
+		if !dryRun {
+			var size int64
+			if st, err := os.Stat(file); err == nil {
+				size = st.Size()
+			}
+			_, err = db.Exec(`
+				UPDATE videos SET duration = ?, width = ?, height = ?, video_codec = ?, audio_codec = ?, bitrate = ?, size = ?
+				WHERE id = ?
+			`, info.Seconds(), info.Width, info.Height, info.VideoCodec, info.AudioCodec, info.Bitrate, size, v.id)
+			if err != nil {
+				return stats, err
+			}
+		}
+		stats.Updated++
+		if progress != nil {
+			progress(v.id, v.url, info, nil)
+		}
+	}
+	return stats, nil
+}
+
+// localVideoFile 查找视频地址对应的本地文件
+// 不是本服务提供的地址时 ok 为 false；在所有目录中都找不到时返回空路径
+func localVideoFile(videoURL string, dirs []string) (file string, ok bool) {
+	u, err := url.Parse(videoURL)
+	if err != nil || !strings.HasPrefix(u.Path, videoURLPrefix) {
+		return "", false
+	}
+	name := path.Clean("/" + strings.TrimPrefix(u.Path, videoURLPrefix))[1:]
+	if name == "" {
+		return "", true
+	}
+
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, filepath.FromSlash(name))
+		if st, err := os.Stat(candidate); err == nil && st.Mode().IsRegular() {
+			return candidate, true
+		}
+	}
+	return "", true
+}
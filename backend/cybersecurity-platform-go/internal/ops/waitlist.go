@@ -0,0 +1,333 @@
+// internal/ops/waitlist.go
+package ops
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 候补状态
+const (
+	WaitlistWaiting  = "waiting"  // 排队中
+	WaitlistOffered  = "offered"  // 已获得名额，等待学生在有效期内选课
+	WaitlistEnrolled = "enrolled" // 已通过候补选课
+	WaitlistExpired  = "expired"  // 名额过期未选课
+	WaitlistLeft     = "left"     // 学生退出候补
+)
+
+// WaitlistOfferWindow 候补名额的有效期，服务启动时根据配置设置
+var WaitlistOfferWindow = 48 * time.Hour
+
+var (
+	// ErrCourseNotFull 课程还有名额，不需要候补
+	ErrCourseNotFull = errors.New("课程还有名额，请直接加入")
+	// ErrNotOnWaitlist 不在候补名单中
+	ErrNotOnWaitlist = errors.New("不在该课程的候补名单中")
+)
+
+// WaitlistEntry 学生在某门课程候补名单中的状态
+// Position 为排队位置（从1开始），只在排队中时有效；ExpiresAt 为获得的名额的截止时间
+type WaitlistEntry struct {
+	CourseID  int        `json:"courseId"`
+	Status    string     `json:"status"`
+	Position  int        `json:"position"`
+	QueuedAt  time.Time  `json:"queuedAt"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// lockPublishedCourse 锁定已发布的课程行并返回人数限制，候补和选课在同一门课程上串行执行
+func lockPublishedCourse(tx *sql.Tx, courseID int) (int, error) {
+	var limit int
+	err := tx.QueryRow("SELECT limit_count FROM courses WHERE id = ? AND status = 'published' FOR UPDATE", courseID).Scan(&limit)
+	if err == sql.ErrNoRows {
+		return 0, ErrCourseNotFound
+	}
+	return limit, err
+}
+
+// seatsTakenSQL 已占用名额的表达式：在修的学生人数，加上尚未过期、发给其他学生的候补名额
+// 已结课和未通过的学生不再占用名额，结课后空出的名额会发给候补学生
+// 选课时的名额检查和课程列表的剩余名额都使用它，保证两处的计算一致
+// course 为课程ID的SQL表达式（出现两次），随后的参数依次为 候补名额状态、当前时间、排除的学号（不排除时传空字符串）
+func seatsTakenSQL(course string) string {
+	return "(SELECT COUNT(*) FROM student_courses WHERE course_id = " + course + " AND status = 'active') + " +
+		"(SELECT COUNT(*) FROM course_waitlist WHERE course_id = " + course + " AND status = ? AND expires_at > ? AND stuId <> ?)"
+}
+
+// seatsTaken 已占用的名额，stuID 获得的候补名额不计入（该学生正在使用这个名额选课）
+func seatsTaken(tx *sql.Tx, courseID int, stuID string, now time.Time) (int, error) {
+	var n int
+	err := tx.QueryRow("SELECT "+seatsTakenSQL("?"), courseID, courseID, WaitlistOffered, now, stuID).Scan(&n)
+	return n, err
+}
+
+// refreshWaitlist 作废过期的名额，再把空出的名额依次发给排队的学生，返回新发出的名额数
+// 调用方需已锁定课程行
+func refreshWaitlist(tx *sql.Tx, courseID, limit int, now time.Time) (int, error) {
+	expired, err := waitlistStudents(tx,
+		"SELECT stuId FROM course_waitlist WHERE course_id = ? AND status = ? AND expires_at <= ? FOR UPDATE",
+		courseID, WaitlistOffered, now)
+	if err != nil {
+		return 0, err
+	}
+	for _, stuID := range expired {
+		if _, err := tx.Exec("UPDATE course_waitlist SET status = ? WHERE course_id = ? AND stuId = ?", WaitlistExpired, courseID, stuID); err != nil {
+			return 0, err
+		}
+		if err := notify(tx, stuID, NoticeWaitlistExpired, "候补名额已过期",
+			"你在候补名单中获得的名额已过期，名额已顺延给下一位同学，如仍需选课请重新候补。", courseID); err != nil {
+			return 0, err
+		}
+	}
+
+	taken, err := seatsTaken(tx, courseID, "", now)
+	if err != nil {
+		return 0, err
+	}
+	free := limit - taken
+	if free <= 0 {
+		return 0, nil
+	}
+	next, err := waitlistStudents(tx,
+		"SELECT stuId FROM course_waitlist WHERE course_id = ? AND status = ? ORDER BY queued_at, id LIMIT ? FOR UPDATE",
+		courseID, WaitlistWaiting, free)
+	if err != nil {
+		return 0, err
+	}
+	expires := now.Add(WaitlistOfferWindow)
+	for _, stuID := range next {
+		if _, err := tx.Exec(
+			"UPDATE course_waitlist SET status = ?, offered_at = ?, expires_at = ? WHERE course_id = ? AND stuId = ?",
+			WaitlistOffered, now, expires, courseID, stuID,
+		); err != nil {
+			return 0, err
+		}
+		if err := notify(tx, stuID, NoticeWaitlistOffer, "候补课程有名额了",
+			fmt.Sprintf("你候补的课程有空余名额，请在 %s 前完成选课，逾期名额将顺延给下一位同学。", expires.Format("2006-01-02 15:04")), courseID); err != nil {
+			return 0, err
+		}
+	}
+	return len(next), nil
+}
+
+// waitlistStudents 查询候补学生的学号
+func waitlistStudents(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []string
+	for rows.Next() {
+		var stuID string
+		if err := rows.Scan(&stuID); err != nil {
+			return nil, err
+		}
+		list = append(list, stuID)
+	}
+	return list, rows.Err()
+}
+
+// JoinWaitlist 加入满员课程的候补名单（只限自由加入的课程）；已在排队或已获得名额时返回当前状态
+// 之前过期或退出的学生重新排到队尾
+func JoinWaitlist(db *sql.DB, stuID string, courseID int) (*WaitlistEntry, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	limit, mode, err := lockEnrollCourse(tx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if mode != EnrollModeOpen {
+		return nil, ErrWaitlistClosed
+	}
+	from, err := enrollmentStatus(tx, stuID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if from == EnrollActive || from == EnrollCompleted {
+		return nil, ErrAlreadyEnrolled
+	}
+
+	now := time.Now()
+	if _, err := refreshWaitlist(tx, courseID, limit, now); err != nil {
+		return nil, err
+	}
+
+	var status string
+	err = tx.QueryRow("SELECT status FROM course_waitlist WHERE course_id = ? AND stuId = ?", courseID, stuID).Scan(&status)
+	switch {
+	case err == sql.ErrNoRows:
+		if err := checkCourseFull(tx, courseID, limit, now); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO course_waitlist (course_id, stuId, status, queued_at) VALUES (?, ?, ?, ?)",
+			courseID, stuID, WaitlistWaiting, now,
+		); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case status != WaitlistWaiting && status != WaitlistOffered:
+		if err := checkCourseFull(tx, courseID, limit, now); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(
+			"UPDATE course_waitlist SET status = ?, queued_at = ?, offered_at = NULL, expires_at = NULL WHERE course_id = ? AND stuId = ?",
+			WaitlistWaiting, now, courseID, stuID,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	entry, err := waitlistEntry(tx, stuID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return entry, tx.Commit()
+}
+
+// checkCourseFull 课程还有空余名额（且没有人排队）时不能候补
+func checkCourseFull(tx *sql.Tx, courseID, limit int, now time.Time) error {
+	taken, err := seatsTaken(tx, courseID, "", now)
+	if err != nil {
+		return err
+	}
+	if taken < limit {
+		return ErrCourseNotFull
+	}
+	return nil
+}
+
+// LeaveWaitlist 退出候补；已获得的名额顺延给下一位
+func LeaveWaitlist(db *sql.DB, stuID string, courseID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	limit, err := lockPublishedCourse(tx, courseID)
+	if err != nil {
+		return err
+	}
+	res, err := tx.Exec(
+		"UPDATE course_waitlist SET status = ? WHERE course_id = ? AND stuId = ? AND status IN (?, ?)",
+		WaitlistLeft, courseID, stuID, WaitlistWaiting, WaitlistOffered,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotOnWaitlist
+	}
+	if _, err := refreshWaitlist(tx, courseID, limit, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// WaitlistStatus 学生在课程候补名单中的状态，不在名单中时返回 nil
+func WaitlistStatus(db *sql.DB, stuID string, courseID int) (*WaitlistEntry, error) {
+	return waitlistEntry(db, stuID, courseID)
+}
+
+func waitlistEntry(q queryExecer, stuID string, courseID int) (*WaitlistEntry, error) {
+	e := WaitlistEntry{CourseID: courseID}
+	var id int64
+	var expires sql.NullTime
+	err := q.QueryRow(
+		"SELECT id, status, queued_at, expires_at FROM course_waitlist WHERE course_id = ? AND stuId = ?", courseID, stuID,
+	).Scan(&id, &e.Status, &e.QueuedAt, &expires)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.Status {
+	case WaitlistWaiting:
+		if err := q.QueryRow(`
+			SELECT COUNT(*) + 1 FROM course_waitlist
+			WHERE course_id = ? AND status = ? AND (queued_at < ? OR (queued_at = ? AND id < ?))
+		`, courseID, WaitlistWaiting, e.QueuedAt, e.QueuedAt, id).Scan(&e.Position); err != nil {
+			return nil, err
+		}
+	case WaitlistOffered:
+		// 名额已过期但后台任务还没有处理时，按过期显示
+		if expires.Valid && !expires.Time.After(time.Now()) {
+			e.Status = WaitlistExpired
+		}
+	}
+	if expires.Valid && e.Status == WaitlistOffered {
+		e.ExpiresAt = &expires.Time
+	}
+	return &e, nil
+}
+
+// OfferSeats 把课程空出的名额发给候补学生（退课、提高人数限制后调用），返回发出的名额数
+// 课程未发布时不处理
+func OfferSeats(db *sql.DB, courseID int) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	limit, err := lockPublishedCourse(tx, courseID)
+	if err == ErrCourseNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n, err := refreshWaitlist(tx, courseID, limit, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// SweepWaitlists 处理所有有候补的课程：作废过期名额并顺延，返回发出的名额数（后台定期执行）
+func SweepWaitlists(ctx context.Context, db *sql.DB) (int, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT DISTINCT course_id FROM course_waitlist WHERE status IN (?, ?)", WaitlistWaiting, WaitlistOffered)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		n, err := OfferSeats(db, id)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
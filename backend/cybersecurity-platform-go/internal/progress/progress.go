@@ -0,0 +1,183 @@
+// internal/progress/progress.go
+package progress
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// CompleteRatio 已观看部分占视频时长的比例达到该值时课时记为完成
+	CompleteRatio = 0.9
+	// MaxPlaybackRate 播放器允许的最大倍速，用于判断上报的观看区间是否可信
+	MaxPlaybackRate = 2.0
+	// MaxSpan 单次上报的观看区间最大长度，播放器应在暂停、跳转和每隔一段时间时上报
+	MaxSpan = 5 * time.Minute
+	// ResumeTail 播放位置距离结尾不足该时长时，下次从头开始播放
+	ResumeTail = 5 * time.Second
+
+	// 两次上报间隔的容差（网络延迟、计时误差）
+	reportSlack = 5 * time.Second
+	// 合并区间时允许的间隙（秒），避免播放器计时误差产生大量碎片
+	mergeGap = 1.0
+)
+
+var (
+	// ErrInvalidInterval 观看区间无效（结束早于开始、超出视频时长等）
+	ErrInvalidInterval = errors.New("观看区间无效")
+	// ErrImplausible 观看区间长度超过两次上报之间可能播放的时长
+	ErrImplausible = errors.New("观看区间超出实际播放时长")
+)
+
+// Interval 已观看的区间（秒）
+type Interval struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Len 区间长度
+func (iv Interval) Len() float64 {
+	return iv.End - iv.Start
+}
+
+// Merge 合并重叠或相邻的区间，返回按开始时间排序的新切片
+func Merge(intervals []Interval) []Interval {
+	sorted := make([]Interval, 0, len(intervals))
+	for _, iv := range intervals {
+		if iv.End > iv.Start {
+			sorted = append(sorted, iv)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	merged := sorted[:0]
+	for _, iv := range sorted {
+		if n := len(merged); n > 0 && iv.Start <= merged[n-1].End+mergeGap {
+			merged[n-1].End = math.Max(merged[n-1].End, iv.End)
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// Covered 已合并区间覆盖的总时长（秒）
+func Covered(intervals []Interval) float64 {
+	var total float64
+	for _, iv := range intervals {
+		total += iv.Len()
+	}
+	return total
+}
+
+// Ratio 已观看比例，范围 [0, 1]；duration 未知时返回0
+func Ratio(watched, duration float64) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return math.Min(watched/duration, 1)
+}
+
+// Lesson 学生在一个课时上的观看进度
+type Lesson struct {
+	Position  float64    // 最后播放位置（秒）
+	Duration  float64    // 视频时长（秒）
+	Watched   []Interval // 已观看区间（已合并）
+	Completed bool
+	Reported  time.Time // 最后一次上报时间，零值表示尚未上报
+}
+
+// Report 一次进度上报：Start~End 为上次上报以来连续播放的区间，Position 为当前播放位置
+// 只拖动进度条、没有播放时 Start 等于 End
+type Report struct {
+	Position float64
+	Start    float64
+	End      float64
+}
+
+// Apply 记录一次上报，更新播放位置、已观看区间和完成状态
+// 区间超出视频时长的部分会被截断；区间长度超过距上次上报可能播放的时长时返回 ErrImplausible，进度不变。
+// 课时一旦完成就保持完成状态
+func (l *Lesson) Apply(r Report, now time.Time) error {
+	if !finite(r.Position) || !finite(r.Start) || !finite(r.End) ||
+		r.Position < 0 || r.Start < 0 || r.End < r.Start {
+		return ErrInvalidInterval
+	}
+	if l.Duration > 0 {
+		if r.Start >= l.Duration && r.End > r.Start {
+			return ErrInvalidInterval
+		}
+		r.End = math.Min(r.End, l.Duration)
+		r.Position = math.Min(r.Position, l.Duration)
+	}
+
+	span := time.Duration((r.End - r.Start) * float64(time.Second))
+	if span > MaxSpan {
+		return ErrImplausible
+	}
+	if !l.Reported.IsZero() {
+		elapsed := now.Sub(l.Reported)
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		if float64(span) > float64(elapsed)*MaxPlaybackRate+float64(reportSlack) {
+			return ErrImplausible
+		}
+	}
+
+	if r.End > r.Start {
+		l.Watched = Merge(append(l.Watched, Interval{Start: r.Start, End: r.End}))
+	}
+	l.Position = r.Position
+	l.Reported = now
+	if !l.Completed && l.Duration > 0 && Ratio(Covered(l.Watched), l.Duration) >= CompleteRatio {
+		l.Completed = true
+	}
+	return nil
+}
+
+// Resume 下次打开课时时的起始播放位置
+func (l *Lesson) Resume() float64 {
+	if l.Duration > 0 && l.Position >= l.Duration-ResumeTail.Seconds() {
+		return 0
+	}
+	return l.Position
+}
+
+// Percent 课时进度百分比，已完成的课时为100
+func (l *Lesson) Percent() int {
+	if l.Completed {
+		return 100
+	}
+	return Percent(Ratio(Covered(l.Watched), l.Duration))
+}
+
+// Percent 将比例转换为整数百分比（向下取整，容忍浮点误差）
+func Percent(ratio float64) int {
+	return int(math.Floor(ratio*100 + 1e-9))
+}
+
+// Rollup 汇总多个课时的进度：已完成的课时计为1，未完成的按观看比例计算
+type Rollup struct {
+	Total     int     `json:"total"`
+	Completed int     `json:"completed"`
+	Percent   int     `json:"percent"`
+	sum       float64 // 各课时进度之和
+}
+
+// Add 计入一个课时，ratio 为观看比例
+func (r *Rollup) Add(completed bool, ratio float64) {
+	r.Total++
+	if completed {
+		r.Completed++
+		ratio = 1
+	}
+	r.sum += math.Max(0, math.Min(ratio, 1))
+	r.Percent = Percent(r.sum / float64(r.Total))
+}
+
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
@@ -0,0 +1,138 @@
+// internal/search/search.go
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cybersecurity-platform-go/internal/hotsearch"
+)
+
+// NgramSize MySQL ngram 分词的长度（ngram_token_size 默认值），更短的词无法通过全文索引检索
+const NgramSize = 2
+
+// Terms 将搜索框输入拆分为检索词（规范化后按空白拆分并去重）
+func Terms(q string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Fields(hotsearch.Normalize(q)) {
+		t = strings.Trim(t, `"+-~<>()*@`)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// BooleanQuery 生成 MATCH ... AGAINST(... IN BOOLEAN MODE) 的检索表达式
+// 每个检索词作为短语匹配（ngram 下要求各个二元组连续出现），包含任一检索词即可命中，命中越多得分越高
+// 检索词都短于 NgramSize 时返回空字符串，调用方应退回到 LIKE 查询
+func BooleanQuery(terms []string) string {
+	var parts []string
+	for _, t := range terms {
+		if utf8.RuneCountInString(t) >= NgramSize {
+			parts = append(parts, `"`+strings.ReplaceAll(t, `"`, "")+`"`)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// patterns 高亮时匹配的片段：检索词本身，以及较长检索词的二元组（与 ngram 的匹配方式一致），长的优先
+func patterns(terms []string) [][]rune {
+	seen := make(map[string]bool)
+	var list [][]rune
+	add := func(r []rune) {
+		if s := string(r); s != "" && !seen[s] {
+			seen[s] = true
+			list = append(list, r)
+		}
+	}
+	for _, t := range terms {
+		r := []rune(t)
+		add(r)
+		if len(r) > NgramSize {
+			for i := 0; i+NgramSize <= len(r); i++ {
+				if !unicode.IsSpace(r[i]) && !unicode.IsSpace(r[i+NgramSize-1]) {
+					add(r[i : i+NgramSize])
+				}
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return len(list[i]) > len(list[j]) })
+	return list
+}
+
+// Highlight 在文本中标出检索词（<em>…</em>，其余内容做 HTML 转义），没有命中时返回 false
+// width 大于0时只截取第一个命中位置附近约 width 个字符的片段
+func Highlight(text string, terms []string, width int) (string, bool) {
+	src := []rune(text)
+	lower := make([]rune, len(src))
+	for i, r := range src {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	type span struct{ start, end int }
+	var spans []span
+	pats := patterns(terms)
+	for i := 0; i < len(lower); {
+		matched := 0
+		for _, p := range pats {
+			if i+len(p) <= len(lower) && string(lower[i:i+len(p)]) == string(p) {
+				matched = len(p)
+				break
+			}
+		}
+		if matched > 0 {
+			if n := len(spans); n > 0 && spans[n-1].end == i {
+				spans[n-1].end = i + matched
+			} else {
+				spans = append(spans, span{i, i + matched})
+			}
+			i += matched
+		} else {
+			i++
+		}
+	}
+	if len(spans) == 0 {
+		return "", false
+	}
+
+	from, to := 0, len(src)
+	if width > 0 && len(src) > width {
+		from = spans[0].start - width/3
+		if from < 0 {
+			from = 0
+		}
+		to = from + width
+		if to > len(src) {
+			to = len(src)
+			from = to - width
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, s := range spans {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, from), min(s.end, to)
+		b.WriteString(html.EscapeString(string(src[pos:start])))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(string(src[start:end])))
+		b.WriteString("</em>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(src[pos:to])))
+	if to < len(src) {
+		b.WriteString("…")
+	}
+	return strings.Join(strings.Fields(b.String()), " "), true
+}
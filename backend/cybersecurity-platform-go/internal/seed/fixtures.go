@@ -0,0 +1,262 @@
+// internal/seed/fixtures.go
+package seed
+
+import (
+	"database/sql"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 测试账号的默认密码（与 scripts/init_login_data.go 保持一致）
+const DefaultPassword = "123456"
+
+func init() {
+	register(FixtureSet{
+		Name:        "users",
+		Description: "登录测试账号 20230001、20230002（密码 123456）",
+		Apply:       seedUsers,
+	})
+	register(FixtureSet{
+		Name:        "courses",
+		Description: "示例课程、教师、章节、课时与视频",
+		Apply:       seedCourses,
+	})
+	register(FixtureSet{
+		Name:        "forum",
+		Description: "论坛分类与标签",
+		Apply:       seedForum,
+	})
+	register(FixtureSet{
+		Name:        "demo",
+		Description: "完整演示数据（包含以上全部数据集）",
+		DependsOn:   []string{"users", "courses", "forum"},
+	})
+}
+
+// seedUsers 写入登录测试账号
+func seedUsers(db *sql.DB) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(DefaultPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	users := []struct {
+		stuID, email, nickName, userName, userHead string
+	}{
+		{"20230001", "student1@example.com", "小明", "张三", "https://example.com/avatar1.jpg"},
+		{"20230002", "student2@example.com", "小红", "李四", "https://example.com/avatar2.jpg"},
+	}
+
+	for _, u := range users {
+		if _, err := db.Exec(`
+			INSERT INTO students (stuId, password, email) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE password = VALUES(password), email = VALUES(email)
+		`, u.stuID, string(hashed), u.email); err != nil {
+			return fmt.Errorf("写入学生 %s 失败: %v", u.stuID, err)
+		}
+
+		if _, err := db.Exec(`
+			INSERT INTO userdetail (stuId, nickName, userHead, userName, userEmail) VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE nickName = VALUES(nickName), userHead = VALUES(userHead),
+				userName = VALUES(userName), userEmail = VALUES(userEmail)
+		`, u.stuID, u.nickName, u.userHead, u.userName, u.email); err != nil {
+			return fmt.Errorf("写入用户详情 %s 失败: %v", u.stuID, err)
+		}
+	}
+	return nil
+}
+
+// 示例课程数据：课程 -> 章节 -> 课时（课时引用视频文件名）
+type fixtureLesson struct {
+	title, video, videoDesc string
+	duration                int
+}
+
+type fixtureChapter struct {
+	title   string
+	state   int
+	lessons []fixtureLesson
+}
+
+type fixtureCourse struct {
+	title, description, cover string
+	credit                    float64
+	limitCount                int
+	teacher                   int // fixtureTeachers 下标
+	chapters                  []fixtureChapter
+}
+
+var fixtureTeachers = []struct {
+	name, career, intro string
+}{
+	{"张教授", "网络安全专家", "从事网络安全研究20年，发表多篇SCI论文，有丰富的教学和实践经验"},
+	{"李博士", "密码学研究员", "专注于密码学算法研究，参与多个国家级安全项目"},
+	{"李老师", "渗透测试工程师，OWASP贡献者", "具有丰富的实战经验，擅长Web安全测试"},
+}
+
+var fixtureCourses = []fixtureCourse{
+	{
+		title:       "网络安全基础",
+		description: "学习网络安全的基本概念和原理，包括加密、认证、访问控制等",
+		cover:       "/img/course/网络安全.png",
+		credit:      3.0, limitCount: 100, teacher: 0,
+		chapters: []fixtureChapter{
+			{"第一章：网络安全基础", 1, []fixtureLesson{
+				{"1.1 网络安全概念", "security1.mp4", "网络安全概述", 3600},
+				{"1.2 安全威胁分析", "security2.mp4", "安全威胁分析", 4200},
+			}},
+			{"第二章：加密技术", 0, []fixtureLesson{
+				{"2.1 对称加密", "security3.mp4", "加密技术基础", 3900},
+			}},
+		},
+	},
+	{
+		title:       "渗透测试实战",
+		description: "通过实战演练学习渗透测试的方法和工具，掌握漏洞挖掘技巧",
+		cover:       "/img/course/渗透测试.png",
+		credit:      4.0, limitCount: 80, teacher: 2,
+		chapters: []fixtureChapter{
+			{"第一章：渗透测试概述", 1, []fixtureLesson{
+				{"1.1 渗透测试流程", "penetration1.mp4", "渗透测试入门", 3800},
+				{"1.2 法律与道德", "penetration2.mp4", "渗透测试法律与道德", 2400},
+			}},
+			{"第二章：信息收集", 0, []fixtureLesson{
+				{"2.1 信息收集方法", "penetration3.mp4", "漏洞扫描工具", 4500},
+			}},
+		},
+	},
+	{
+		title:       "密码学原理与应用",
+		description: "深入理解密码学原理，学习对称加密、非对称加密、哈希算法等",
+		cover:       "/img/course/密码学.png",
+		credit:      3.5, limitCount: 120, teacher: 1,
+		chapters: []fixtureChapter{
+			{"第一章：古典密码", 1, []fixtureLesson{
+				{"1.1 替换与置换", "crypto1.mp4", "古典密码", 3000},
+			}},
+		},
+	},
+}
+
+// seedCourses 写入示例课程（按标题判断是否已存在）
+func seedCourses(db *sql.DB) error {
+	teacherIDs := make([]int64, len(fixtureTeachers))
+	for i, t := range fixtureTeachers {
+		id, err := upsertByName(db, "SELECT id FROM teachers WHERE name = ?", t.name,
+			"INSERT INTO teachers (name, career, intro) VALUES (?, ?, ?)", t.name, t.career, t.intro)
+		if err != nil {
+			return fmt.Errorf("写入教师 %s 失败: %v", t.name, err)
+		}
+		teacherIDs[i] = id
+	}
+
+	for _, c := range fixtureCourses {
+		var existing int64
+		err := db.QueryRow("SELECT id FROM courses WHERE title = ?", c.title).Scan(&existing)
+		if err == nil {
+			continue // 课程已存在，不覆盖可能已被修改的内容
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		if err := insertFixtureCourse(db, c, teacherIDs[c.teacher]); err != nil {
+			return fmt.Errorf("写入课程 %s 失败: %v", c.title, err)
+		}
+	}
+	return nil
+}
+
+// insertFixtureCourse 在一个事务中写入课程及其章节、课时和视频
+func insertFixtureCourse(db *sql.DB, c fixtureCourse, teacherID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	lessonNum := 0
+	for _, ch := range c.chapters {
+		lessonNum += len(ch.lessons)
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO courses (title, description, cover, lesson_num, credit, limit_count) VALUES (?, ?, ?, ?, ?, ?)",
+		c.title, c.description, c.cover, lessonNum, c.credit, c.limitCount,
+	)
+	if err != nil {
+		return err
+	}
+	courseID, _ := result.LastInsertId()
+
+	if _, err := tx.Exec("INSERT INTO teacher_courses (teacher_id, course_id) VALUES (?, ?)", teacherID, courseID); err != nil {
+		return err
+	}
+
+	for _, ch := range c.chapters {
+		result, err := tx.Exec("INSERT INTO chapters (course_id, title, state) VALUES (?, ?, ?)", courseID, ch.title, ch.state)
+		if err != nil {
+			return err
+		}
+		chapterID, _ := result.LastInsertId()
+
+		for _, l := range ch.lessons {
+			result, err := tx.Exec(
+				"INSERT INTO videos (url, description, duration) VALUES (?, ?, ?)",
+				"/api/videoing/"+l.video, l.videoDesc, l.duration,
+			)
+			if err != nil {
+				return err
+			}
+			videoID, _ := result.LastInsertId()
+
+			if _, err := tx.Exec(
+				"INSERT INTO chapter_children (chapter_id, title, video_id) VALUES (?, ?, ?)",
+				chapterID, l.title, videoID,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// seedForum 写入论坛分类与标签
+func seedForum(db *sql.DB) error {
+	categories := []string{"网络安全", "漏洞分析", "安全工具", "密码学", "法律法规"}
+	for _, name := range categories {
+		if _, err := upsertByName(db, "SELECT id FROM forum_categories WHERE name = ?", name,
+			"INSERT INTO forum_categories (name) VALUES (?)", name); err != nil {
+			return fmt.Errorf("写入分类 %s 失败: %v", name, err)
+		}
+	}
+
+	tags := []string{"渗透测试", "Web安全", "移动安全", "云安全", "数据加密", "身份认证", "防火墙", "入侵检测"}
+	for _, name := range tags {
+		if _, err := upsertByName(db, "SELECT id FROM forum_tags WHERE name = ?", name,
+			"INSERT INTO forum_tags (name) VALUES (?)", name); err != nil {
+			return fmt.Errorf("写入标签 %s 失败: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// upsertByName 按名称查找记录，不存在时插入，返回记录ID
+func upsertByName(db *sql.DB, selectSQL, name, insertSQL string, args ...interface{}) (int64, error) {
+	var id int64
+	err := db.QueryRow(selectSQL, name).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	result, err := db.Exec(insertSQL, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
@@ -0,0 +1,80 @@
+// internal/seed/seed.go
+package seed
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// FixtureSet 一组可重复执行的测试数据
+// 每个数据集都应是幂等的：已存在的数据会被跳过或更新，而不是重复插入
+type FixtureSet struct {
+	Name        string
+	Description string
+	DependsOn   []string
+	Apply       func(db *sql.DB) error
+}
+
+var sets = map[string]FixtureSet{}
+
+// register 注册数据集
+func register(set FixtureSet) {
+	if _, exists := sets[set.Name]; exists {
+		panic("数据集重复注册: " + set.Name)
+	}
+	sets[set.Name] = set
+}
+
+// Sets 返回按名称排序的全部数据集
+func Sets() []FixtureSet {
+	list := make([]FixtureSet, 0, len(sets))
+	for _, set := range sets {
+		list = append(list, set)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Run 按依赖顺序执行指定的数据集
+func Run(db *sql.DB, names ...string) error {
+	done := map[string]bool{}
+	var apply func(name string, path []string) error
+	apply = func(name string, path []string) error {
+		if done[name] {
+			return nil
+		}
+		for _, p := range path {
+			if p == name {
+				return fmt.Errorf("数据集存在循环依赖: %v -> %s", path, name)
+			}
+		}
+
+		set, ok := sets[name]
+		if !ok {
+			return fmt.Errorf("未知的数据集: %s", name)
+		}
+		for _, dep := range set.DependsOn {
+			if err := apply(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+
+		if set.Apply != nil {
+			log.Printf("写入数据集: %s", name)
+			if err := set.Apply(db); err != nil {
+				return fmt.Errorf("数据集 %s 写入失败: %v", name, err)
+			}
+		}
+		done[name] = true
+		return nil
+	}
+
+	for _, name := range names {
+		if err := apply(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
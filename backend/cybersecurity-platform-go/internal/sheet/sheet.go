@@ -0,0 +1,393 @@
+// internal/sheet/sheet.go
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 表格格式
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+const (
+	// MaxRows 最多读取的行数
+	MaxRows = 10000
+	// maxPartSize XLSX 中单个文件解压后的最大大小
+	maxPartSize = 64 << 20
+)
+
+var (
+	// ErrTooManyRows 行数超过 MaxRows
+	ErrTooManyRows = fmt.Errorf("表格不能超过%d行", MaxRows)
+	// ErrUnsupported 无法识别的文件格式
+	ErrUnsupported = errors.New("只支持 CSV 和 XLSX 文件")
+)
+
+// utf8BOM Excel 需要 BOM 才能正确识别 UTF-8 编码的 CSV
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Format 根据文件名或 Content-Type 判断格式，无法判断时返回空字符串
+func Format(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".xlsx"), strings.Contains(name, "spreadsheetml"):
+		return FormatXLSX
+	case strings.HasSuffix(name, ".csv"), strings.Contains(name, "text/csv"):
+		return FormatCSV
+	}
+	return ""
+}
+
+// ContentType 格式对应的 Content-Type
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Read 读取表格的所有行，根据文件内容判断格式：ZIP 文件按 XLSX 读取第一个工作表，其他按 CSV 读取
+// CSV 可以是 UTF-8（可带 BOM）或 GBK 编码，分隔符为逗号、制表符或分号
+func Read(data []byte) ([][]string, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return readXLSX(data)
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil, ErrUnsupported
+	}
+	return readCSV(data)
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, ErrUnsupported
+		}
+		data = decoded
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = csvDelimiter(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var rows [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 CSV 失败: %v", err)
+		}
+		if len(rows) >= MaxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+// csvDelimiter 根据第一行判断分隔符
+func csvDelimiter(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	best, count := ',', bytes.Count(line, []byte{','})
+	for _, d := range []rune{'\t', ';'} {
+		if n := bytes.Count(line, []byte(string(d))); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
+
+// XLSX 中用到的 XML 结构
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxText struct {
+		T string `xml:"t"`
+		R []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	}
+	xlsxSheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R  string   `xml:"r,attr"`
+				T  string   `xml:"t,attr"`
+				V  string   `xml:"v"`
+				IS xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+// String 富文本按顺序拼接
+func (t xlsxText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath := "xl/worksheets/sheet1.xml"
+	var wb xlsxWorkbook
+	var rels xlsxRels
+	if decodePart(files, "xl/workbook.xml", &wb) == nil && decodePart(files, "xl/_rels/workbook.xml.rels", &rels) == nil && len(wb.Sheets) > 0 {
+		for _, rel := range rels.Rels {
+			if rel.ID == wb.Sheets[0].RID {
+				if strings.HasPrefix(rel.Target, "/") {
+					sheetPath = strings.TrimPrefix(rel.Target, "/")
+				} else {
+					sheetPath = path.Join("xl", rel.Target)
+				}
+				break
+			}
+		}
+	}
+
+	var shared []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			SI []xlsxText `xml:"si"`
+		}
+		if err := decodePart(files, "xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.SI {
+			shared = append(shared, si.String())
+		}
+	}
+
+	var ws xlsxSheet
+	if err := decodePart(files, sheetPath, &ws); err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	for _, row := range ws.Rows {
+		// 行号可以不连续（空行不写入文件），按行号补齐
+		index := len(rows)
+		if row.R > 0 {
+			index = row.R - 1
+		}
+		if index >= MaxRows {
+			return nil, ErrTooManyRows
+		}
+		for len(rows) <= index {
+			rows = append(rows, nil)
+		}
+		var record []string
+		for i, c := range row.Cells {
+			col := i
+			if c.R != "" {
+				col = columnIndex(c.R)
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			switch c.T {
+			case "s":
+				n, err := strconv.Atoi(c.V)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, fmt.Errorf("单元格 %s 引用的字符串不存在", c.R)
+				}
+				record[col] = shared[n]
+			case "inlineStr":
+				record[col] = c.IS.String()
+			default:
+				record[col] = numberString(c.V)
+			}
+		}
+		rows[index] = record
+	}
+	return rows, nil
+}
+
+// decodePart 解析 XLSX 中的一个 XML 文件
+func decodePart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("XLSX 文件缺少 %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("解析 %s 失败: %v", name, err)
+	}
+	return nil
+}
+
+// columnIndex 单元格引用（如 "AB12"）的列序号，从0开始
+func columnIndex(ref string) int {
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		n = n*26 + int(c-'A'+1)
+	}
+	return n - 1
+}
+
+// numberString Excel 把学号等纯数字保存为数值，大数可能写成科学计数法，转换回普通写法
+func numberString(v string) string {
+	if !strings.ContainsAny(v, "eE") {
+		return v
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// columnName 列序号（从0开始）对应的列名，如 0 -> A，27 -> AB
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// formulaPrefixes 电子表格软件会把以这些字符开头的单元格当作公式
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula 以公式字符开头的单元格前加单引号，作为文本显示，防止导出的数据（如学生填写的姓名）被当作公式执行
+func escapeFormula(cell string) string {
+	if cell != "" && strings.IndexByte(formulaPrefixes, cell[0]) >= 0 {
+		return "'" + cell
+	}
+	return cell
+}
+
+// Write 按格式写出表格，第一行通常为表头
+// 以 =、+、-、@ 开头的单元格会加上单引号前缀（见 escapeFormula）
+func Write(w io.Writer, format string, rows [][]string) error {
+	escaped := make([][]string, len(rows))
+	for i, row := range rows {
+		escaped[i] = make([]string, len(row))
+		for j, cell := range row {
+			escaped[i][j] = escapeFormula(cell)
+		}
+	}
+	rows = escaped
+
+	switch format {
+	case FormatCSV:
+		if _, err := w.Write(utf8BOM); err != nil {
+			return err
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case FormatXLSX:
+		return writeXLSX(w, rows)
+	}
+	return ErrUnsupported
+}
+
+// XLSX 的固定部分，只有一个工作表，单元格都写为内联字符串
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookXML = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+)
+
+func writeXLSX(w io.Writer, rows [][]string) error {
+	zw := zip.NewWriter(w)
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbookXML},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, columnName(j), i+1)
+			xml.EscapeText(&b, []byte(cell))
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	if _, err := b.WriteTo(f); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,274 @@
+// internal/static/dirs.go
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 查找结果缓存时间；命中缓存时仍会校验文件的大小和修改时间
+	defaultLookupTTL = 5 * time.Minute
+	// 找不到的文件在该时间内直接返回404，不再逐个目录查找
+	defaultNegativeTTL = 10 * time.Second
+	// 缓存条目上限，超过后清空重建，避免被随机路径撑满内存
+	maxCacheEntries = 10000
+	// 小于该大小的文件使用内容哈希作为 ETag，更大的文件（如视频）使用文件元数据计算
+	contentHashLimit = 8 << 20
+)
+
+// DirHandler 多目录静态文件处理器
+// 按顺序在多个根目录中查找文件，保证解析后的路径（包括符号链接）不会离开根目录，
+// 不提供目录列表；查找结果（包括找不到的结果）会被缓存
+type DirHandler struct {
+	roots    []string
+	fallback *FSHandler
+
+	lookupTTL   time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu    sync.RWMutex
+	cache map[string]*dirEntry
+}
+
+// dirEntry 查找缓存条目
+type dirEntry struct {
+	path    string // 解析符号链接后的绝对路径，为空表示找不到
+	size    int64
+	modTime time.Time
+	etag    string
+	expires time.Time
+}
+
+// NewDirHandler 创建多目录静态文件处理器
+// roots 按优先级排列，不存在的目录会被跳过；fallback 不为nil时，所有目录都找不到的文件会从中查找
+func NewDirHandler(roots []string, fallback fs.FS) *DirHandler {
+	h := &DirHandler{
+		lookupTTL:   defaultLookupTTL,
+		negativeTTL: defaultNegativeTTL,
+		now:         time.Now,
+		cache:       make(map[string]*dirEntry),
+	}
+	for _, root := range roots {
+		if root != "" {
+			h.roots = append(h.roots, root)
+		}
+	}
+	if fallback != nil {
+		h.fallback = NewFSHandler(fallback, "")
+	}
+	return h
+}
+
+// SetClock 替换时间函数（用于测试缓存过期）
+func (h *DirHandler) SetClock(now func() time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.now = now
+}
+
+// Flush 清空查找缓存
+func (h *DirHandler) Flush() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cache = make(map[string]*dirEntry)
+}
+
+// ServeHTTP 实现 http.Handler，请求路径应已去掉路由前缀（配合 http.StripPrefix 使用）
+func (h *DirHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Range")
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusOK)
+		return
+	case http.MethodGet, http.MethodHead:
+	default:
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, ok := cleanName(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if h.serveDisk(w, r, name) {
+		return
+	}
+	if h.fallback != nil && h.fallback.ServeFile(w, r, name) {
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// serveDisk 从磁盘目录发送文件，找不到时返回 false（不写入任何响应）
+func (h *DirHandler) serveDisk(w http.ResponseWriter, r *http.Request, name string) bool {
+	entry := h.lookup(name)
+	if entry.path == "" {
+		return false
+	}
+
+	f, err := os.Open(entry.path)
+	if err != nil {
+		h.forget(name)
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		h.forget(name)
+		return false
+	}
+	// 文件在缓存期间被修改，重新计算 ETag
+	if info.Size() != entry.size || !info.ModTime().Equal(entry.modTime) {
+		h.forget(name)
+		entry = h.lookup(name)
+		if entry.path == "" {
+			return false
+		}
+	}
+
+	header := w.Header()
+	// 外层中间件（如媒体访问控制）已设置缓存策略时不覆盖
+	if header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", CacheControl(name))
+	}
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	}
+	if entry.etag != "" {
+		header.Set("ETag", entry.etag)
+	}
+
+	http.ServeContent(w, r, name, info.ModTime(), f)
+	return true
+}
+
+// lookup 查找文件，优先使用缓存
+func (h *DirHandler) lookup(name string) *dirEntry {
+	h.mu.RLock()
+	entry, ok := h.cache[name]
+	now := h.now()
+	h.mu.RUnlock()
+	if ok && now.Before(entry.expires) {
+		return entry
+	}
+
+	entry = h.resolve(name)
+	if entry.path == "" {
+		entry.expires = now.Add(h.negativeTTL)
+	} else {
+		entry.expires = now.Add(h.lookupTTL)
+	}
+
+	h.mu.Lock()
+	if len(h.cache) >= maxCacheEntries {
+		h.cache = make(map[string]*dirEntry)
+	}
+	h.cache[name] = entry
+	h.mu.Unlock()
+	return entry
+}
+
+// forget 删除缓存条目
+func (h *DirHandler) forget(name string) {
+	h.mu.Lock()
+	delete(h.cache, name)
+	h.mu.Unlock()
+}
+
+// resolve 依次在各根目录中查找文件，返回第一个位于根目录内的普通文件
+func (h *DirHandler) resolve(name string) *dirEntry {
+	for _, root := range h.roots {
+		realRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		realRoot, err = filepath.Abs(realRoot)
+		if err != nil {
+			continue
+		}
+
+		realPath, err := filepath.EvalSymlinks(filepath.Join(realRoot, filepath.FromSlash(name)))
+		if err != nil || !within(realRoot, realPath) {
+			continue
+		}
+
+		info, err := os.Stat(realPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		etag, err := fileETag(realPath, info)
+		if err != nil {
+			continue
+		}
+		return &dirEntry{path: realPath, size: info.Size(), modTime: info.ModTime(), etag: etag}
+	}
+	return &dirEntry{}
+}
+
+// cleanName 规范化请求路径，拒绝包含 ".."、空字节或隐藏文件的路径
+func cleanName(p string) (string, bool) {
+	if strings.ContainsRune(p, 0) || strings.Contains(p, "\\") {
+		return "", false
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." || (strings.HasPrefix(seg, ".") && seg != ".") {
+			return "", false
+		}
+	}
+	name := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if name == "" || !fs.ValidPath(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// within 判断 target 是否位于 root 目录内
+func within(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// fileETag 计算文件的强 ETag
+// 小文件使用内容哈希；大文件逐字节计算代价太高，使用路径、大小和修改时间（纳秒）计算，
+// 内容变化时修改时间必然变化，因此同样可以作为强校验值
+func fileETag(realPath string, info os.FileInfo) (string, error) {
+	sum := sha256.New()
+	if info.Size() <= contentHashLimit {
+		f, err := os.Open(realPath)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if _, err := io.Copy(sum, f); err != nil {
+			return "", err
+		}
+	} else {
+		fmt.Fprintf(sum, "%s|%d|%d", realPath, info.Size(), info.ModTime().UnixNano())
+	}
+	return `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`, nil
+}
@@ -0,0 +1,210 @@
+// internal/static/fs.go
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 预压缩文件的扩展名，按优先级排列
+var encodings = []struct {
+	name string // Content-Encoding 取值
+	ext  string // 预压缩文件后缀
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// hashedName 匹配构建工具生成的带内容哈希的文件名，如 app.3f2a1b4c.js、chunk-vendors.3f2a1b4c.css
+var hashedName = regexp.MustCompile(`[.-]([0-9a-f]{8,})\.[A-Za-z0-9]+$`)
+
+// FSHandler 基于 fs.FS（如 embed.FS）的静态文件处理器
+// 支持预压缩的 br/gzip 文件、强 ETag、按文件名设置缓存策略，以及单页应用的 history 回退
+type FSHandler struct {
+	fsys     fs.FS
+	spaIndex string
+
+	etags sync.Map // 文件名（含压缩后缀）-> ETag
+}
+
+// NewFSHandler 创建静态文件处理器
+// spaIndex 非空时，找不到的页面路由（无扩展名的 HTML 请求）会返回该入口文件
+func NewFSHandler(fsys fs.FS, spaIndex string) *FSHandler {
+	return &FSHandler{fsys: fsys, spaIndex: spaIndex}
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *FSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" && h.spaIndex != "" {
+		name = h.spaIndex
+	}
+
+	if name != "" && h.ServeFile(w, r, name) {
+		return
+	}
+
+	if h.spaIndex != "" && isHistoryRequest(r, name) && h.ServeFile(w, r, h.spaIndex) {
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// ServeFile 发送 fs 中的文件，文件不存在或是目录时返回 false（不写入任何响应）
+func (h *FSHandler) ServeFile(w http.ResponseWriter, r *http.Request, name string) bool {
+	if !fs.ValidPath(name) {
+		return false
+	}
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	// 优先发送客户端支持的预压缩版本
+	servedName, encoding := name, ""
+	for _, enc := range encodings {
+		if !acceptsEncoding(r, enc.name) {
+			continue
+		}
+		if ci, err := fs.Stat(h.fsys, name+enc.ext); err == nil && !ci.IsDir() {
+			servedName, encoding, info = name+enc.ext, enc.name, ci
+			break
+		}
+	}
+
+	content, err := h.open(servedName)
+	if err != nil {
+		return false
+	}
+	if c, ok := content.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Accept-Encoding")
+	if header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", CacheControl(name))
+	}
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	} else if encoding != "" {
+		// 压缩内容无法嗅探类型
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	if etag, err := h.etag(servedName); err == nil {
+		header.Set("ETag", etag)
+	}
+
+	http.ServeContent(w, r, name, info.ModTime(), content)
+	return true
+}
+
+// open 打开文件并返回可 Seek 的内容（Range 请求需要）
+func (h *FSHandler) open(name string) (io.ReadSeeker, error) {
+	f, err := h.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// etag 计算并缓存文件内容的强 ETag
+// 嵌入的文件在进程生命周期内不会变化，因此只需计算一次
+func (h *FSHandler) etag(name string) (string, error) {
+	if v, ok := h.etags.Load(name); ok {
+		return v.(string), nil
+	}
+
+	f, err := h.fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`
+	h.etags.Store(name, etag)
+	return etag, nil
+}
+
+// CacheControl 根据文件名返回缓存策略
+//   - HTML 入口文件：每次都需要向服务器验证，保证发布后立即生效
+//   - 带内容哈希的文件：内容变化时文件名也会变化，可以长期缓存
+//   - 其他文件：缓存一小时
+func CacheControl(name string) string {
+	base := path.Base(name)
+	switch {
+	case strings.HasSuffix(base, ".html"):
+		return "no-cache"
+	case isHashedName(base):
+		return "public, max-age=31536000, immutable"
+	default:
+		return "public, max-age=3600"
+	}
+}
+
+// isHashedName 判断文件名是否带内容哈希
+// 纯数字的片段（如头像文件名中的时间戳）不算哈希，这类文件可能被同名覆盖
+func isHashedName(base string) bool {
+	m := hashedName.FindStringSubmatch(base)
+	return m != nil && strings.ContainsAny(m[1], "abcdef")
+}
+
+// isHistoryRequest 判断是否为前端路由的页面请求（需要回退到入口文件）
+// 带扩展名的资源请求不回退，避免缺失的 JS/CSS 被错误地返回为 HTML
+func isHistoryRequest(r *http.Request, name string) bool {
+	if path.Ext(name) != "" {
+		return false
+	}
+	accept := r.Header.Get("Accept")
+	return accept == "" || strings.Contains(accept, "text/html") || strings.Contains(accept, "*/*")
+}
+
+// acceptsEncoding 检查客户端是否接受指定的内容编码
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(fields[0]) != encoding {
+			continue
+		}
+		// 显式声明 q=0 表示不接受
+		for _, param := range fields[1:] {
+			if p := strings.ReplaceAll(strings.TrimSpace(param), " ", ""); p == "q=0" || p == "q=0.0" || p == "q=0.00" || p == "q=0.000" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+// internal/storage/local.go
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local 本地文件系统存储
+// 对象默认存放在 root 目录下，可以通过 Mount 将某个前缀映射到其他目录（兼容原有的头像、上传目录配置）
+type Local struct {
+	root    string
+	mounts  []localMount
+	baseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+// localMount 前缀到目录的映射
+type localMount struct {
+	prefix string
+	dir    string
+}
+
+// NewLocal 创建本地存储
+// baseURL 和 secret 用于生成预签名下载地址（由 ServeHTTP 校验）
+func NewLocal(root, baseURL string, secret []byte) *Local {
+	return &Local{
+		root:    root,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
+		now:     time.Now,
+	}
+}
+
+// Mount 将前缀映射到指定目录，例如 Mount("images/user/", "/data/avatars")
+func (l *Local) Mount(prefix, dir string) {
+	if dir == "" {
+		return
+	}
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	l.mounts = append(l.mounts, localMount{prefix: prefix, dir: dir})
+	// 更长的前缀优先匹配
+	sort.SliceStable(l.mounts, func(i, j int) bool { return len(l.mounts[i].prefix) > len(l.mounts[j].prefix) })
+}
+
+// SetClock 替换时间函数（用于测试预签名地址过期）
+func (l *Local) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+// path 返回对象键对应的文件路径
+func (l *Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	for _, m := range l.mounts {
+		if strings.HasPrefix(key, m.prefix) {
+			return filepath.Join(m.dir, filepath.FromSlash(strings.TrimPrefix(key, m.prefix))), nil
+		}
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Put 写入对象：先写入同目录下的临时文件再重命名，避免读到写了一半的文件
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Get 读取对象
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, ObjectInfo{}, mapOSError(err)
+	}
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		f.Close()
+		return nil, ObjectInfo{}, ErrNotExist
+	}
+	return f, localInfo(key, fi), nil
+}
+
+// Stat 获取对象元数据
+func (l *Local) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return ObjectInfo{}, mapOSError(err)
+	}
+	if fi.IsDir() {
+		return ObjectInfo{}, ErrNotExist
+	}
+	return localInfo(key, fi), nil
+}
+
+// Delete 删除对象
+func (l *Local) Delete(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List 遍历前缀下的对象（包括映射目录中的对象），跳过隐藏文件和写入中的临时文件
+func (l *Local) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	type source struct {
+		keyPrefix string
+		dir       string
+	}
+	sources := []source{{"", l.root}}
+	for _, m := range l.mounts {
+		sources = append(sources, source{m.prefix, m.dir})
+	}
+
+	var infos []ObjectInfo
+	for _, src := range sources {
+		err := filepath.WalkDir(src.dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if p == src.dir && os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && p != src.dir {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(src.dir, p)
+			if err != nil {
+				return err
+			}
+			key := src.keyPrefix + filepath.ToSlash(rel)
+			if !strings.HasPrefix(key, prefix) {
+				return nil
+			}
+			// 根目录下属于映射前缀的文件由映射目录负责，避免重复
+			if owner, err := l.path(key); err != nil || owner != p {
+				return nil
+			}
+
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			infos = append(infos, localInfo(key, fi))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	for _, info := range infos {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PresignGet 生成带签名的下载地址，由 ServeHTTP 校验后发送文件
+func (l *Local) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	exp := strconv.FormatInt(l.now().Add(expires).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", exp)
+	q.Set("signature", l.sign(key, exp))
+	return l.baseURL + "/api/storage/" + (&url.URL{Path: key}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+// sign 计算预签名地址的签名
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ServeHTTP 发送预签名地址指向的文件（需配合 http.StripPrefix("/api/storage/", ...) 使用）
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	exp := r.URL.Query().Get("expires")
+	sig := r.URL.Query().Get("signature")
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || len(l.secret) == 0 || !hmac.Equal([]byte(sig), []byte(l.sign(key, exp))) {
+		http.Error(w, "签名无效", http.StatusForbidden)
+		return
+	}
+	if l.now().Unix() > expUnix {
+		http.Error(w, "链接已过期", http.StatusForbidden)
+		return
+	}
+
+	rc, info, err := l.Get(r.Context(), key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer rc.Close()
+
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(expUnix-l.now().Unix(), 10))
+	http.ServeContent(w, r, path.Base(key), info.ModTime, rc.(io.ReadSeeker))
+}
+
+// localInfo 根据文件信息构建对象元数据
+func localInfo(key string, fi os.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         key,
+		Size:        fi.Size(),
+		ModTime:     fi.ModTime(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+	}
+}
+
+// mapOSError 将文件不存在错误转换为 ErrNotExist
+func mapOSError(err error) error {
+	if os.IsNotExist(err) {
+		return ErrNotExist
+	}
+	return err
+}
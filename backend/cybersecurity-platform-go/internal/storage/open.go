@@ -0,0 +1,107 @@
+// internal/storage/open.go
+package storage
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"cybersecurity-platform-go/internal/config"
+	"cybersecurity-platform-go/internal/static"
+)
+
+// Open 根据配置创建存储，driver 为空时使用 cfg.StorageDriver
+func Open(cfg *config.Config, driver string) (Storage, error) {
+	if driver == "" {
+		driver = cfg.StorageDriver
+	}
+
+	switch strings.ToLower(driver) {
+	case "", "local":
+		secret := []byte(cfg.StorageSigningKey)
+		if len(secret) == 0 {
+			// 未配置密钥时使用随机密钥：预签名地址在服务重启后失效
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		local := NewLocal(cfg.StorageLocalRoot, cfg.BaseURL, secret)
+		// 兼容原有目录配置
+		local.Mount(PrefixUserImages, cfg.UserImageDir)
+		local.Mount(PrefixCourseImages, cfg.CourseImageDir)
+		local.Mount(PrefixForumUploads, cfg.ForumUploadDir)
+		local.Mount(PrefixVideos, existingDir(cfg.VideoDirs))
+		local.Mount(PrefixPdfs, existingDir(cfg.PdfDirs))
+		return local, nil
+
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+
+	default:
+		return nil, fmt.Errorf("未知的存储类型: %s（可用: local, s3）", driver)
+	}
+}
+
+// existingDir 返回第一个存在的目录，都不存在时返回空字符串（不映射）
+func existingDir(dirs []string) string {
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// RedirectHandler 将文件请求重定向到远程存储的预签名地址
+// 用于 S3 等远程存储：请求路径（去掉路由前缀后）加上 keyPrefix 即为对象键；
+// fallback 中存在的文件（嵌入的默认资源）直接发送
+func RedirectHandler(s Storage, keyPrefix string, ttl time.Duration, fallback fs.FS) http.Handler {
+	var fallbackHandler *static.FSHandler
+	if fallback != nil {
+		fallbackHandler = static.NewFSHandler(fallback, "")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if fallbackHandler != nil && fallbackHandler.ServeFile(w, r, name) {
+			return
+		}
+
+		key := keyPrefix + name
+		if !ValidKey(key) || strings.HasSuffix(key, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		target, err := s.PresignGet(r.Context(), key, ttl)
+		if err != nil {
+			log.Printf("生成预签名地址失败 %s: %v", key, err)
+			http.NotFound(w, r)
+			return
+		}
+
+		// 预签名地址有时效，浏览器只能在有效期内缓存重定向结果
+		if w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(ttl/time.Second)/2))
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+}
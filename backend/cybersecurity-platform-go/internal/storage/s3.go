@@ -0,0 +1,276 @@
+// internal/storage/s3.go
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config S3 兼容存储配置（AWS S3、MinIO 等）
+type S3Config struct {
+	Endpoint  string // 服务地址，如 https://s3.amazonaws.com 或 http://minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // 使用 endpoint/bucket/key 形式的地址（MinIO 需要）
+}
+
+// S3 S3 兼容对象存储
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	signer   signer
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 创建 S3 存储
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("S3 存储需要配置 endpoint 和 bucket")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("S3 endpoint 格式错误: %s", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	return &S3{
+		cfg:      cfg,
+		endpoint: endpoint,
+		signer:   signer{accessKey: cfg.AccessKey, secretKey: cfg.SecretKey, region: cfg.Region},
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+// SetClock 替换时间函数（用于测试签名）
+func (s *S3) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// objectURL 构建对象地址
+func (s *S3) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	if s.cfg.PathStyle {
+		u.Path = "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	// 让实际发送的路径编码与签名时的编码一致
+	u.RawPath = canonicalURI(&u)
+	u.RawQuery = ""
+	if query != nil {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return &u
+}
+
+// do 签名并发送请求
+func (s *S3) do(ctx context.Context, method string, u *url.URL, body io.Reader, size int64, payloadHash string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	s.signer.signRequest(req, payloadHash, s.now())
+	return s.client.Do(req)
+}
+
+// Put 上传对象
+// 已知大小时流式上传（UNSIGNED-PAYLOAD），未知大小时先读入内存再上传
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	payloadHash := unsignedPayload
+	if size < 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		payloadHash = hex.EncodeToString(sum[:])
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if size == 0 {
+		r = http.NoBody
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.objectURL(key, nil), r, size, payloadHash, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// Get 下载对象
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	if !ValidKey(key) {
+		return nil, ObjectInfo{}, ErrInvalidKey
+	}
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(key, nil), nil, 0, emptyPayloadHash, nil)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, ObjectInfo{}, s3Error(resp)
+	}
+	return resp.Body, headerInfo(key, resp), nil
+}
+
+// Stat 获取对象元数据
+func (s *S3) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	if !ValidKey(key) {
+		return ObjectInfo{}, ErrInvalidKey
+	}
+	resp, err := s.do(ctx, http.MethodHead, s.objectURL(key, nil), nil, 0, emptyPayloadHash, nil)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ObjectInfo{}, s3Error(resp)
+	}
+	return headerInfo(key, resp), nil
+}
+
+// Delete 删除对象（S3 删除不存在的对象同样返回成功）
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(key, nil), nil, 0, emptyPayloadHash, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		if err := s3Error(resp); !errors.Is(err, ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listResult ListObjectsV2 响应
+type listResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+		ETag         string    `xml:"ETag"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List 分页遍历前缀下的对象
+func (s *S3) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	token := ""
+	for {
+		q := url.Values{}
+		q.Set("list-type", "2")
+		q.Set("prefix", prefix)
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+
+		u := s.objectURL("", q)
+		resp, err := s.do(ctx, http.MethodGet, u, nil, 0, emptyPayloadHash, nil)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp)
+			resp.Body.Close()
+			return err
+		}
+
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("解析对象列表失败: %v", err)
+		}
+
+		for _, c := range result.Contents {
+			if err := fn(ObjectInfo{Key: c.Key, Size: c.Size, ModTime: c.LastModified, ETag: c.ETag}); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// PresignGet 生成预签名下载地址（最长7天）
+func (s *S3) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	if expires <= 0 || expires > 7*24*time.Hour {
+		return "", fmt.Errorf("预签名有效期必须在 1 秒到 7 天之间")
+	}
+	return s.signer.presign(http.MethodGet, s.objectURL(key, nil), expires, s.now()), nil
+}
+
+// headerInfo 从响应头构建对象元数据
+func headerInfo(key string, resp *http.Response) ObjectInfo {
+	info := ObjectInfo{
+		Key:         key,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}
+	info.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	info.ModTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return info
+}
+
+// s3Error 将错误响应转换为 error
+func s3Error(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotExist
+	}
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("S3 错误 %d %s: %s", resp.StatusCode, body.Code, body.Message)
+	}
+	return fmt.Errorf("S3 错误: HTTP %d", resp.StatusCode)
+}
@@ -0,0 +1,129 @@
+// internal/storage/storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 对象键前缀：上传文件按用途存放在不同的前缀下，与本地 static 目录结构保持一致
+const (
+	PrefixUserImages   = "images/user/"
+	PrefixCourseImages = "images/course/"
+	PrefixVideos       = "videos/"
+	PrefixPdfs         = "pdfs/"
+	PrefixForumUploads = "forum/uploads/"
+)
+
+// ErrNotExist 对象不存在
+var ErrNotExist = errors.New("对象不存在")
+
+// ErrInvalidKey 对象键不合法（为空、包含 ".."、反斜杠等）
+var ErrInvalidKey = errors.New("对象键不合法")
+
+// ObjectInfo 对象元数据
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+	ETag        string
+}
+
+// Storage 对象存储接口
+// 键使用 "/" 分隔，例如 "images/user/avatar-1.jpg"
+type Storage interface {
+	// Put 写入对象，size 未知时传 -1
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，调用方负责关闭返回的 ReadCloser
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+	// Stat 获取对象元数据，对象不存在时返回 ErrNotExist
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// List 按键的字典序遍历指定前缀下的所有对象
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+	// PresignGet 生成有时效的下载地址
+	PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+var (
+	defaultMu    sync.RWMutex
+	defaultStore Storage
+)
+
+// SetDefault 设置全局存储（服务启动时根据配置调用）
+func SetDefault(s Storage) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultStore = s
+}
+
+// Default 获取全局存储，未设置时返回 nil
+func Default() Storage {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultStore
+}
+
+// ValidKey 检查对象键是否合法
+func ValidKey(key string) bool {
+	return key != "" && key != "." && fs.ValidPath(key) && !strings.ContainsAny(key, "\\\x00")
+}
+
+// MigrateStats 迁移统计
+type MigrateStats struct {
+	Copied  int
+	Skipped int
+	Bytes   int64
+}
+
+// Migrate 将 src 中指定前缀下的对象复制到 dst
+// 目标中已存在且大小相同的对象会被跳过，因此中断后可以重复执行；dryRun 为 true 时只统计不复制
+func Migrate(ctx context.Context, src, dst Storage, prefix string, dryRun bool, progress func(info ObjectInfo, skipped bool)) (MigrateStats, error) {
+	var stats MigrateStats
+	err := src.List(ctx, prefix, func(info ObjectInfo) error {
+		if existing, err := dst.Stat(ctx, info.Key); err == nil && existing.Size == info.Size {
+			stats.Skipped++
+			if progress != nil {
+				progress(info, true)
+			}
+			return nil
+		} else if err != nil && !errors.Is(err, ErrNotExist) {
+			return fmt.Errorf("检查目标对象 %s 失败: %w", info.Key, err)
+		}
+
+		if !dryRun {
+			if err := copyObject(ctx, src, dst, info.Key); err != nil {
+				return err
+			}
+		}
+		stats.Copied++
+		stats.Bytes += info.Size
+		if progress != nil {
+			progress(info, false)
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// copyObject 复制单个对象
+func copyObject(ctx context.Context, src, dst Storage, key string) error {
+	r, info, err := src.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("读取对象 %s 失败: %w", key, err)
+	}
+	defer r.Close()
+
+	if err := dst.Put(ctx, key, r, info.Size, info.ContentType); err != nil {
+		return fmt.Errorf("写入对象 %s 失败: %w", key, err)
+	}
+	return nil
+}
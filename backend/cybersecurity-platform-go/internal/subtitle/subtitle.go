@@ -0,0 +1,301 @@
+// internal/subtitle/subtitle.go
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 字幕格式
+const (
+	FormatVTT = "vtt"
+	FormatSRT = "srt"
+)
+
+// MaxCues 单个字幕文件最多的字幕条数
+const MaxCues = 20000
+
+// Cue 一条字幕
+type Cue struct {
+	ID       string // 标识（可选）
+	Start    time.Duration
+	End      time.Duration
+	Settings string // WebVTT 的位置设置，例如 "align:start line:0"
+	Text     string // 原始文本，可能包含 <i>、<v 说话人> 等标签
+}
+
+// PlainText 去掉标签和实体后的纯文本（用于检索）
+func (c Cue) PlainText() string {
+	text := tagPattern.ReplaceAllString(c.Text, "")
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// ParseError 字幕格式错误
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("第 %d 行: %s", e.Line, e.Msg)
+}
+
+var (
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+	// SRT 中 WebVTT 不支持的 <font> 标签
+	fontPattern = regexp.MustCompile(`(?i)</?font[^>]*>`)
+	// 时间轴，例如 "00:01:02.500 --> 00:01:04.000 align:start"
+	timingPattern = regexp.MustCompile(`^(\S+)\s+-->\s+(\S+)(?:\s+(.*))?$`)
+)
+
+// DetectFormat 根据内容判断字幕格式
+func DetectFormat(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	if bytes.HasPrefix(data, []byte("WEBVTT")) {
+		return FormatVTT
+	}
+	return FormatSRT
+}
+
+// Parse 解析 WebVTT 或 SRT 字幕，format 为空时自动判断
+// 校验编码（必须为 UTF-8）、时间轴格式和顺序，返回的字幕按开始时间排列
+func Parse(data []byte, format string) ([]Cue, error) {
+	if !utf8.Valid(data) {
+		return nil, &ParseError{Line: 1, Msg: "字幕文件必须使用 UTF-8 编码"}
+	}
+	if format == "" {
+		format = DetectFormat(data)
+	}
+
+	text := strings.TrimPrefix(string(data), "\uFEFF")
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+
+	var cues []Cue
+	var err error
+	switch format {
+	case FormatVTT:
+		cues, err = parseVTT(lines)
+	case FormatSRT:
+		cues, err = parseSRT(lines)
+	default:
+		return nil, fmt.Errorf("不支持的字幕格式: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(cues) == 0 {
+		return nil, &ParseError{Line: len(lines), Msg: "字幕文件中没有字幕"}
+	}
+	return cues, nil
+}
+
+// parseVTT 解析 WebVTT
+func parseVTT(lines []string) ([]Cue, error) {
+	header := lines[0]
+	if header != "WEBVTT" && !strings.HasPrefix(header, "WEBVTT ") && !strings.HasPrefix(header, "WEBVTT\t") {
+		return nil, &ParseError{Line: 1, Msg: "缺少 WEBVTT 文件头"}
+	}
+
+	var cues []Cue
+	i := 1
+	// 文件头之后到第一个空行之间是头部元数据
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+		i++
+	}
+	for i < len(lines) {
+		if strings.TrimSpace(lines[i]) == "" {
+			i++
+			continue
+		}
+		block, start := readBlock(lines, &i)
+
+		first := strings.TrimSpace(block[0])
+		if first == "NOTE" || strings.HasPrefix(first, "NOTE ") || strings.HasPrefix(first, "NOTE\t") ||
+			first == "STYLE" || first == "REGION" {
+			continue
+		}
+
+		cue := Cue{}
+		timing := 0
+		if !strings.Contains(block[0], "-->") {
+			cue.ID = first
+			timing = 1
+		}
+		if timing >= len(block) {
+			return nil, &ParseError{Line: start + timing + 1, Msg: "缺少时间轴"}
+		}
+		if err := parseTiming(block[timing], &cue, false); err != nil {
+			return nil, &ParseError{Line: start + timing + 1, Msg: err.Error()}
+		}
+		cue.Text = strings.Join(block[timing+1:], "\n")
+		if err := appendCue(&cues, cue, start+1); err != nil {
+			return nil, err
+		}
+	}
+	return cues, nil
+}
+
+// parseSRT 解析 SRT
+func parseSRT(lines []string) ([]Cue, error) {
+	var cues []Cue
+	for i := 0; i < len(lines); {
+		if strings.TrimSpace(lines[i]) == "" {
+			i++
+			continue
+		}
+		block, start := readBlock(lines, &i)
+
+		timing := 0
+		if !strings.Contains(block[0], "-->") {
+			// 序号行
+			if _, err := strconv.Atoi(strings.TrimSpace(block[0])); err != nil {
+				return nil, &ParseError{Line: start + 1, Msg: "无效的字幕序号"}
+			}
+			timing = 1
+		}
+		if timing >= len(block) {
+			return nil, &ParseError{Line: start + timing + 1, Msg: "缺少时间轴"}
+		}
+
+		cue := Cue{}
+		if err := parseTiming(block[timing], &cue, true); err != nil {
+			return nil, &ParseError{Line: start + timing + 1, Msg: err.Error()}
+		}
+		// SRT 的文本不能包含 "-->"，WebVTT 中也不允许
+		cue.Text = fontPattern.ReplaceAllString(strings.Join(block[timing+1:], "\n"), "")
+		if err := appendCue(&cues, cue, start+1); err != nil {
+			return nil, err
+		}
+	}
+	return cues, nil
+}
+
+// readBlock 读取到下一个空行为止的行，返回这些行和起始行号（从0开始）
+func readBlock(lines []string, i *int) ([]string, int) {
+	start := *i
+	for *i < len(lines) && strings.TrimSpace(lines[*i]) != "" {
+		*i++
+	}
+	return lines[start:*i], start
+}
+
+// appendCue 校验并追加字幕
+func appendCue(cues *[]Cue, cue Cue, line int) error {
+	if strings.TrimSpace(cue.Text) == "" {
+		return &ParseError{Line: line, Msg: "字幕内容为空"}
+	}
+	if strings.Contains(cue.Text, "-->") {
+		return &ParseError{Line: line, Msg: "字幕内容不能包含 \"-->\""}
+	}
+	if len(*cues) >= MaxCues {
+		return &ParseError{Line: line, Msg: fmt.Sprintf("字幕条数超过 %d", MaxCues)}
+	}
+	if n := len(*cues); n > 0 && cue.Start < (*cues)[n-1].Start {
+		return &ParseError{Line: line, Msg: "字幕开始时间早于上一条字幕"}
+	}
+	*cues = append(*cues, cue)
+	return nil
+}
+
+// parseTiming 解析时间轴行
+func parseTiming(line string, cue *Cue, srt bool) error {
+	m := timingPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return fmt.Errorf("无效的时间轴 %q", line)
+	}
+	start, err := parseTimestamp(m[1], srt)
+	if err != nil {
+		return err
+	}
+	end, err := parseTimestamp(m[2], srt)
+	if err != nil {
+		return err
+	}
+	if end <= start {
+		return fmt.Errorf("结束时间必须晚于开始时间")
+	}
+	cue.Start, cue.End = start, end
+	if !srt {
+		cue.Settings = m[3]
+	}
+	return nil
+}
+
+// parseTimestamp 解析 [hh:]mm:ss.ttt（SRT 使用逗号作为毫秒分隔符）
+func parseTimestamp(s string, srt bool) (time.Duration, error) {
+	invalid := fmt.Errorf("无效的时间 %q", s)
+
+	sep := "."
+	if srt {
+		sep = ","
+		// 部分工具导出的 SRT 使用句点
+		if !strings.Contains(s, ",") {
+			sep = "."
+		}
+	}
+	clock, frac, ok := strings.Cut(s, sep)
+	if !ok || len(frac) != 3 {
+		return 0, invalid
+	}
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 || (srt && len(parts) != 3) {
+		return 0, invalid
+	}
+
+	var values []int
+	for _, p := range append(parts, frac) {
+		if p == "" || strings.Trim(p, "0123456789") != "" {
+			return 0, invalid
+		}
+		v, _ := strconv.Atoi(p)
+		values = append(values, v)
+	}
+	if len(parts) == 2 {
+		values = append([]int{0}, values...)
+	}
+	h, m, sec, ms := values[0], values[1], values[2], values[3]
+	if m > 59 || sec > 59 {
+		return 0, invalid
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
+
+// FormatTimestamp 格式化为 WebVTT 时间 hh:mm:ss.ttt
+func FormatTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// WriteVTT 生成 WebVTT 文件
+func WriteVTT(cues []Cue) []byte {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n")
+	for _, c := range cues {
+		b.WriteString("\n")
+		if c.ID != "" {
+			b.WriteString(c.ID + "\n")
+		}
+		b.WriteString(FormatTimestamp(c.Start) + " --> " + FormatTimestamp(c.End))
+		if c.Settings != "" {
+			b.WriteString(" " + c.Settings)
+		}
+		b.WriteString("\n" + c.Text + "\n")
+	}
+	return b.Bytes()
+}
+
+// 语言标签，例如 zh、en、zh-Hans、pt-BR
+var langPattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8}){0,2}$`)
+
+// ValidLang 检查语言标签（BCP 47 的常用子集）
+func ValidLang(lang string) bool {
+	return langPattern.MatchString(lang)
+}
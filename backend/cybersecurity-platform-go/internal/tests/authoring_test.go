@@ -0,0 +1,139 @@
+// internal/tests/authoring_test.go
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"cybersecurity-platform-go/internal/handlers"
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePdfURL(t *testing.T) {
+	for raw, want := range map[string]string{
+		"intro.pdf":                       "/api/pdfs/intro.pdf",
+		"/api/pdfs/lessons/3.pdf":         "/api/pdfs/lessons/3.pdf",
+		" 第一章.PDF ":                       "/api/pdfs/第一章.PDF",
+		"https://cdn.example.com/a/b.pdf": "https://cdn.example.com/a/b.pdf",
+	} {
+		got, err := ops.NormalizePdfURL(raw)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, want, got, raw)
+	}
+	for _, raw := range []string{"", "notes.txt", "../secret.pdf", "/api/pdfs/a/../../b.pdf", "a\\b.pdf"} {
+		_, err := ops.NormalizePdfURL(raw)
+		var input ops.InputError
+		assert.ErrorAs(t, err, &input, raw)
+	}
+}
+
+func TestCourseInputValidation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	blank, credit, limit := "  ", 120.0, 0
+	_, err = ops.CreateCourse(db, 1, ops.CourseInput{})
+	assert.ErrorAs(t, err, new(ops.InputError))
+	_, err = ops.CreateCourse(db, 1, ops.CourseInput{Title: &blank})
+	assert.ErrorAs(t, err, new(ops.InputError))
+	assert.ErrorAs(t, ops.UpdateCourse(db, 1, 2, ops.CourseInput{Credit: &credit}), new(ops.InputError))
+	assert.ErrorAs(t, ops.UpdateCourse(db, 1, 2, ops.CourseInput{LimitCount: &limit}), new(ops.InputError))
+
+	// 校验失败时不访问数据库
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthoringRequiresCourseTeacher(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	title := "新标题"
+	expectCourseRole(mock, 7, 3, nil, nil)
+	assert.ErrorIs(t, ops.UpdateCourse(db, 7, 3, ops.CourseInput{Title: &title}), ops.ErrNotCourseTeacher)
+
+	mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN teacher_courses tc ON tc.teacher_id = ? AND tc.course_id = c.id")).
+		WithArgs(7, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permissions"}))
+	assert.ErrorIs(t, ops.UpdateCourse(db, 7, 4, ops.CourseInput{Title: &title}), ops.ErrCourseNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateLessonSyncsLessonNum(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	title, videoID, pdf := "1.1 环境搭建", 12, "setup.pdf"
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT course_id FROM chapters WHERE id = ?")).
+		WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"course_id"}).AddRow(3))
+	expectTeaches(mock, 7, 3)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM videos v WHERE v.id = ?")).
+		WithArgs(7, 7, 12).WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO chapter_children (chapter_id, title, video_id, pdf_url, sort_order)")).
+		WithArgs(5, title, 12, "/api/pdfs/setup.pdf", 5).
+		WillReturnResult(sqlmock.NewResult(40, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE courses SET lesson_num = (")).
+		WithArgs(3, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	// 同一事务中更新课程检索索引
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT title, description FROM courses WHERE id = ?")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"title", "description"}).AddRow("Web安全", "入门课程"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT t.name FROM teacher_courses tc")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("张老师"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM chapters ch WHERE ch.course_id = ?")).
+		WithArgs(3, 3).WillReturnRows(sqlmock.NewRows([]string{"title"}).AddRow("第一章").AddRow(title))
+	mock.ExpectExec(regexp.QuoteMeta("REPLACE INTO course_search")).
+		WithArgs(3, "Web安全", "张老师", "第一章\n"+title, "入门课程").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	id, err := ops.CreateLesson(db, 7, 5, ops.LessonInput{Title: &title, VideoID: &videoID, PdfURL: &pdf})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(40), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderRejectsIncompleteList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	expectTeaches(mock, 7, 3)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM chapters WHERE course_id = ?")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(3))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, ops.ReorderChapters(db, 7, 3, []int{1, 2}), ops.ErrBadOrder)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthoringRoutesMounted 教师路由挂载到主路由后都能到达（未带令牌返回401），不会落到 /api/ 的图谱路由返回404
+func TestAuthoringRoutesMounted(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", http.NotFound)
+	handlers.MountAuthoringRoutes(mux, nil)
+
+	for _, route := range [][2]string{
+		{http.MethodGet, "/api/teacher/courses"},
+		{http.MethodPut, "/api/teacher/courses/1/students/2021001"},
+		{http.MethodPut, "/api/teacher/chapters/1/prerequisites"},
+		{http.MethodDelete, "/api/teacher/lessons/1"},
+		{http.MethodDelete, "/api/teacher/invites/1"},
+		{http.MethodGet, "/api/teacher/requests"},
+		{http.MethodPut, "/api/teacher/requests/1"},
+		{http.MethodPut, "/api/teacher/reviews/1/reply"},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(route[0], route[1], nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, route[0]+" "+route[1])
+	}
+}
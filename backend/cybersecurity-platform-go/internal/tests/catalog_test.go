@@ -0,0 +1,54 @@
+// internal/tests/catalog_test.go
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCourseListFilterSQL(t *testing.T) {
+	lo, hi, enrolled := 2.0, 4.0, false
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	q := ops.CourseListFilter{
+		SubjectIDs: []int{1, 4},
+		TeacherID:  3,
+		CreditMin:  &lo,
+		CreditMax:  &hi,
+		Available:  true,
+		Now:        now,
+		StuID:      "2021001",
+		Enrolled:   &enrolled,
+		Sort:       ops.SortPopular,
+	}.SQL()
+
+	// 已占用名额与选课时的检查相同，未过期的候补名额也计入
+	assert.Equal(t, []interface{}{ops.WaitlistOffered, now, "", "2021001"}, q.ColumnArgs)
+	assert.Equal(t, []interface{}{1, 4, 3, 2.0, 4.0, ops.WaitlistOffered, now, "", "2021001"}, q.WhereArgs)
+	assert.Len(t, q.Where, 6)
+	assert.Contains(t, q.Where[0], "subject_id IN (?, ?)")
+	assert.True(t, strings.HasPrefix(q.Where[4], "c.limit_count > ((SELECT COUNT(*) FROM student_courses"))
+	assert.Contains(t, q.Where[4], "FROM course_waitlist WHERE course_id = c.id AND status = ? AND expires_at > ?")
+	assert.True(t, strings.HasPrefix(q.Where[5], "NOT EXISTS"))
+	assert.Equal(t, "COALESCE(e.enrolled, 0) DESC, c.id DESC", q.OrderBy)
+	assert.NotContains(t, q.Joins, "lesson_progress")
+}
+
+func TestCourseListFilterDefaults(t *testing.T) {
+	// 未登录时不返回选课状态，也忽略选课状态筛选
+	enrolled := true
+	q := ops.CourseListFilter{Enrolled: &enrolled, Sort: 99}.SQL()
+	assert.Empty(t, q.Where)
+	assert.Len(t, q.ColumnArgs, 3)
+	assert.Equal(t, "c.id DESC", q.OrderBy)
+
+	q = ops.CourseListFilter{Sort: ops.SortActive}.SQL()
+	assert.Contains(t, q.Joins, "lesson_progress")
+
+	assert.Equal(t, 0, ops.RemainingSeats(30, 31))
+	assert.Equal(t, 5, ops.RemainingSeats(30, 25))
+}
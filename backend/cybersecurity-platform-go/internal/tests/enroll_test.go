@@ -0,0 +1,344 @@
+// internal/tests/enroll_test.go
+package tests
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/migrate"
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectEnrollStart 学生存在、锁定自由加入的课程行（人数限制 limit）、没有选课记录
+func expectEnrollStart(mock sqlmock.Sqlmock, limit int) {
+	expectEnrollStartMode(mock, limit, ops.EnrollModeOpen)
+}
+
+// expectEnrollStartMode 同 expectEnrollStart，课程的选课方式为 mode
+func expectEnrollStartMode(mock sqlmock.Sqlmock, limit int, mode string) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)")).
+		WithArgs("2021001").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT limit_count, enroll_mode FROM courses WHERE id = ? AND status = 'published' FOR UPDATE")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"limit_count", "enroll_mode"}).AddRow(limit, mode))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE")).
+		WithArgs("2021001", 3).WillReturnRows(sqlmock.NewRows([]string{"status"}))
+	expectPrerequisites(mock)
+}
+
+// expectPrerequisites 课程3的先修课程及学生2021001在其中的选课状态，每行为 group_no, id, title, status
+func expectPrerequisites(mock sqlmock.Sqlmock, rows ...[]interface{}) {
+	r := sqlmock.NewRows([]string{"group_no", "id", "title", "status"})
+	for _, row := range rows {
+		r.AddRow(row[0], row[1], row[2], row[3])
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM course_prerequisites p")).WithArgs("2021001", 3).WillReturnRows(r)
+}
+
+// expectSeatsTaken 在修人数加上发给其他学生的候补名额
+func expectSeatsTaken(mock sqlmock.Sqlmock, stuID string, n int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT (SELECT COUNT(*) FROM student_courses WHERE course_id = ? AND status = 'active')")).
+		WithArgs(3, 3, ops.WaitlistOffered, sqlmock.AnyArg(), stuID).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(n))
+}
+
+// expectExpiredOffers 查询已过期的候补名额
+func expectExpiredOffers(mock sqlmock.Sqlmock, stuIDs ...string) {
+	rows := sqlmock.NewRows([]string{"stuId"})
+	for _, id := range stuIDs {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("AND expires_at <= ? FOR UPDATE")).
+		WithArgs(3, ops.WaitlistOffered, sqlmock.AnyArg()).WillReturnRows(rows)
+}
+
+func TestEnrollFullCourse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	expectEnrollStart(mock, 30)
+	expectExpiredOffers(mock)
+	expectSeatsTaken(mock, "", 30)
+	expectSeatsTaken(mock, "2021001", 30)
+	mock.ExpectRollback()
+
+	_, err = ops.Enroll(db, "2021001", 3, ops.EnrollOptions{})
+	assert.ErrorIs(t, err, ops.ErrCourseFull)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnrollRetryIsIdempotent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 两个请求同时通过检查时，后插入的一个违反唯一键，视为已加入
+	expectEnrollStart(mock, 30)
+	expectExpiredOffers(mock)
+	expectSeatsTaken(mock, "", 12)
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY queued_at, id LIMIT ? FOR UPDATE")).
+		WithArgs(3, ops.WaitlistWaiting, 18).WillReturnRows(sqlmock.NewRows([]string{"stuId"}))
+	expectSeatsTaken(mock, "2021001", 12)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO student_courses (stuId, course_id, status, joined_at) VALUES (?, ?, ?, ?)")).
+		WithArgs("2021001", 3, ops.EnrollActive, sqlmock.AnyArg()).WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectRollback()
+
+	_, err = ops.Enroll(db, "2021001", 3, ops.EnrollOptions{})
+	assert.ErrorIs(t, err, ops.ErrAlreadyEnrolled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWaitlistOfferPassesToNextStudent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 2021002 的名额过期，空出的名额顺延给排在最前的 2021003，两人都会收到通知
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT limit_count FROM courses WHERE id = ? AND status = 'published' FOR UPDATE")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"limit_count"}).AddRow(30))
+	expectExpiredOffers(mock, "2021002")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE course_waitlist SET status = ? WHERE course_id = ? AND stuId = ?")).
+		WithArgs(ops.WaitlistExpired, 3, "2021002").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO notifications")).
+		WithArgs("2021002", ops.NoticeWaitlistExpired, sqlmock.AnyArg(), sqlmock.AnyArg(), 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectSeatsTaken(mock, "", 29)
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY queued_at, id LIMIT ? FOR UPDATE")).
+		WithArgs(3, ops.WaitlistWaiting, 1).WillReturnRows(sqlmock.NewRows([]string{"stuId"}).AddRow("2021003"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE course_waitlist SET status = ?, offered_at = ?, expires_at = ?")).
+		WithArgs(ops.WaitlistOffered, sqlmock.AnyArg(), sqlmock.AnyArg(), 3, "2021003").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO notifications")).
+		WithArgs("2021003", ops.NoticeWaitlistOffer, sqlmock.AnyArg(), sqlmock.AnyArg(), 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	n, err := ops.OfferSeats(db, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompletedEnrollmentFreesSeat(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 已结课的学生不再占用名额，空出的名额发给排在最前的候补学生
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)")).
+		WithArgs("2021001").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, limit_count FROM courses WHERE id = ? FOR UPDATE")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"status", "limit_count"}).AddRow(ops.CoursePublished, 30))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE")).
+		WithArgs("2021001", 3).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(ops.EnrollActive))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE student_courses SET status = ?, ended_at = ?")).
+		WithArgs(ops.EnrollCompleted, sqlmock.AnyArg(), "2021001", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO enrollment_events").WillReturnResult(sqlmock.NewResult(1, 1))
+	expectExpiredOffers(mock)
+	expectSeatsTaken(mock, "", 29)
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY queued_at, id LIMIT ? FOR UPDATE")).
+		WithArgs(3, ops.WaitlistWaiting, 1).WillReturnRows(sqlmock.NewRows([]string{"stuId"}).AddRow("2021003"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE course_waitlist SET status = ?, offered_at = ?, expires_at = ?")).
+		WithArgs(ops.WaitlistOffered, sqlmock.AnyArg(), sqlmock.AnyArg(), 3, "2021003").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO notifications")).
+		WithArgs("2021003", ops.NoticeWaitlistOffer, sqlmock.AnyArg(), sqlmock.AnyArg(), 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = ops.SetEnrollment(db, 0, 3, "2021001", ops.EnrollCompleted, "")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDropDeadline(t *testing.T) {
+	defer func(w time.Duration) { ops.EnrollDropWindow = w }(ops.EnrollDropWindow)
+	joined := time.Date(2024, 9, 2, 10, 0, 0, 0, time.Local)
+
+	// 课程设置的截止时间优先
+	course := time.Date(2024, 9, 20, 23, 59, 0, 0, time.Local)
+	assert.Equal(t, course, *ops.DropDeadline(&course, joined))
+
+	ops.EnrollDropWindow = 14 * 24 * time.Hour
+	e := ops.Enrollment{Status: ops.EnrollActive, JoinedAt: joined, DropDeadline: ops.DropDeadline(nil, joined)}
+	assert.Equal(t, joined.AddDate(0, 0, 14), *e.DropDeadline)
+	assert.True(t, e.CanDrop(joined.AddDate(0, 0, 14)))
+	assert.False(t, e.CanDrop(joined.AddDate(0, 0, 15)))
+
+	// 已结课的记录不能退课；窗口为0时不限制
+	e.Status = ops.EnrollCompleted
+	assert.False(t, e.CanDrop(joined))
+	ops.EnrollDropWindow = 0
+	assert.Nil(t, ops.DropDeadline(nil, joined))
+}
+
+func TestDropCourseAfterDeadline(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, limit_count, drop_deadline FROM courses WHERE id = ? FOR UPDATE")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"status", "limit_count", "drop_deadline"}).
+		AddRow(ops.CoursePublished, 30, time.Now().Add(-time.Hour)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, joined_at FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE")).
+		WithArgs("2021001", 3).WillReturnRows(sqlmock.NewRows([]string{"status", "joined_at"}).
+		AddRow(ops.EnrollActive, time.Now().AddDate(0, 0, -1)))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, ops.DropCourse(db, "2021001", 3, ""), ops.ErrDropDeadlinePassed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnrollAfterDrop(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 退课后重新选课恢复原记录，并记录一条 dropped -> active 的历史
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)")).
+		WithArgs("2021001").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT limit_count, enroll_mode FROM courses WHERE id = ? AND status = 'published' FOR UPDATE")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"limit_count", "enroll_mode"}).AddRow(30, ops.EnrollModeOpen))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE")).
+		WithArgs("2021001", 3).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(ops.EnrollDropped))
+	expectPrerequisites(mock)
+	expectExpiredOffers(mock)
+	expectSeatsTaken(mock, "", 30)
+	expectSeatsTaken(mock, "2021001", 29)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE student_courses SET status = ?, joined_at = ?, ended_at = NULL WHERE stuId = ? AND course_id = ?")).
+		WithArgs(ops.EnrollActive, sqlmock.AnyArg(), "2021001", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE course_waitlist SET status = ?")).
+		WithArgs(ops.WaitlistEnrolled, 3, "2021001", ops.WaitlistWaiting, ops.WaitlistOffered).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE enrollment_requests SET status = ?")).
+		WithArgs(ops.RequestApproved, ops.ActorStudent, sqlmock.AnyArg(), 3, "2021001", ops.RequestPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO enrollment_events")).
+		WithArgs("2021001", 3, ops.EnrollDropped, ops.EnrollActive, ops.ActorStudent, "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	pending, err := ops.Enroll(db, "2021001", 3, ops.EnrollOptions{})
+	assert.NoError(t, err)
+	assert.False(t, pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnrollApprovalCreatesRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 需要审核的课程只提交申请，不检查人数也不加入课程
+	expectEnrollStartMode(mock, 30, ops.EnrollModeApproval)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO enrollment_requests (course_id, stuId, status, message, created_at)")).
+		WithArgs(3, "2021001", ops.RequestPending, "想学习渗透测试", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	pending, err := ops.Enroll(db, "2021001", 3, ops.EnrollOptions{Message: " 想学习渗透测试 "})
+	assert.NoError(t, err)
+	assert.True(t, pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnrollInviteCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	expectInvite := func(maxUses, used int, expires interface{}) {
+		expectEnrollStartMode(mock, 30, ops.EnrollModeInvite)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM course_invites WHERE course_id = ? AND code = ? FOR UPDATE")).
+			WithArgs(3, "ABCD2345").WillReturnRows(sqlmock.NewRows([]string{"id", "max_uses", "used", "expires_at", "revoked_at"}).
+			AddRow(5, maxUses, used, expires, nil))
+		mock.ExpectRollback()
+	}
+
+	// 没有邀请码时不查询邀请码
+	expectEnrollStartMode(mock, 30, ops.EnrollModeInvite)
+	mock.ExpectRollback()
+	_, err = ops.Enroll(db, "2021001", 3, ops.EnrollOptions{})
+	assert.ErrorIs(t, err, ops.ErrInviteRequired)
+
+	expectInvite(0, 3, time.Now().Add(-time.Minute))
+	_, err = ops.Enroll(db, "2021001", 3, ops.EnrollOptions{InviteCode: "abcd2345"})
+	assert.ErrorIs(t, err, ops.ErrInviteExpired)
+
+	expectInvite(10, 10, nil)
+	_, err = ops.Enroll(db, "2021001", 3, ops.EnrollOptions{InviteCode: " ABCD2345"})
+	assert.ErrorIs(t, err, ops.ErrInviteUsedUp)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestEnrollConcurrent 多名学生同时抢同一门课（每人重复提交），选课人数不超过上限且没有重复记录
+// 需要真实的 MySQL：设置 TEST_MYSQL_DSN（如 root:pass@tcp(localhost:3306)/cyber_test?parseTime=true&loc=Local）
+func TestEnrollConcurrent(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("未设置 TEST_MYSQL_DSN")
+	}
+	db, err := sql.Open("mysql", dsn)
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(50)
+	_, err = migrate.Up(db)
+	assert.NoError(t, err)
+
+	const limit, students = 10, 60
+	res, err := db.Exec("INSERT INTO courses (title, limit_count, status) VALUES ('并发选课测试', ?, 'published')", limit)
+	assert.NoError(t, err)
+	courseID, _ := res.LastInsertId()
+	var stuIDs []string
+	for i := 0; i < students; i++ {
+		stuID := fmt.Sprintf("enroll-race-%d-%d", courseID, i)
+		_, err := db.Exec("INSERT INTO students (stuId, email, password) VALUES (?, ?, '')", stuID, stuID+"@test.local")
+		assert.NoError(t, err)
+		stuIDs = append(stuIDs, stuID)
+	}
+	defer func() {
+		db.Exec("DELETE FROM student_courses WHERE course_id = ?", courseID)
+		db.Exec("DELETE FROM courses WHERE id = ?", courseID)
+		for _, id := range stuIDs {
+			db.Exec("DELETE FROM students WHERE stuId = ?", id)
+		}
+	}()
+
+	var mu sync.Mutex
+	results := make(map[error]int)
+	var wg sync.WaitGroup
+	for _, stuID := range stuIDs {
+		for attempt := 0; attempt < 2; attempt++ {
+			wg.Add(1)
+			go func(stuID string) {
+				defer wg.Done()
+				_, err := ops.Enroll(db, stuID, int(courseID), ops.EnrollOptions{})
+				mu.Lock()
+				results[err]++
+				mu.Unlock()
+			}(stuID)
+		}
+	}
+	wg.Wait()
+
+	var count, distinct int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*), COUNT(DISTINCT stuId) FROM student_courses WHERE course_id = ?", courseID).Scan(&count, &distinct))
+	assert.Equal(t, limit, count)
+	assert.Equal(t, limit, distinct)
+	assert.Equal(t, limit, results[nil])
+	assert.Equal(t, 2*students, results[nil]+results[ops.ErrAlreadyEnrolled]+results[ops.ErrCourseFull])
+}
@@ -0,0 +1,109 @@
+// internal/tests/health_test.go
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/handlers"
+	"cybersecurity-platform-go/internal/health"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthProbes(t *testing.T) {
+	var dbErr error
+	checker := health.NewChecker(time.Second, time.Minute,
+		health.PingCheck("mysql", true, func(ctx context.Context) error { return dbErr }),
+		health.FuncCheck("cache", false, func() error { return errors.New("缓存异常") }),
+	)
+
+	// 非关键依赖异常：就绪探针仍返回200
+	rec := httptest.NewRecorder()
+	checker.ReadinessHandler()(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	checker.DetailsHandler()(rec, httptest.NewRequest(http.MethodGet, "/health/details", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"degraded"`)
+	assert.Contains(t, rec.Body.String(), "缓存异常")
+
+	// 关键依赖异常：新建检查器（避免缓存）后返回503，存活探针不受影响
+	dbErr = errors.New("连接被拒绝")
+	checker = health.NewChecker(time.Second, time.Minute,
+		health.PingCheck("mysql", true, func(ctx context.Context) error { return dbErr }),
+	)
+
+	rec = httptest.NewRecorder()
+	checker.ReadinessHandler()(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "连接被拒绝", "就绪探针不应暴露错误详情")
+
+	rec = httptest.NewRecorder()
+	checker.LivenessHandler()(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHealthCacheAndTimeout(t *testing.T) {
+	var calls int32
+	now := time.Now()
+
+	checker := health.NewChecker(50*time.Millisecond, 5*time.Second,
+		health.PingCheck("mysql", true, func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}),
+		health.PingCheck("slow", false, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	)
+	checker.SetClock(func() time.Time { return now })
+
+	report := checker.Run(context.Background(), false)
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, health.StatusDown, report.Checks[1].Status, "超时的检查应视为失败")
+
+	checker.Run(context.Background(), false)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "缓存有效期内不应重复检查")
+
+	now = now.Add(6 * time.Second)
+	checker.Run(context.Background(), true)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "缓存过期后应重新检查")
+}
+
+func TestAdminAuth(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	cases := []struct {
+		name   string
+		token  string
+		header string
+		value  string
+		want   int
+	}{
+		{"未配置令牌", "", "X-Token", "anything", http.StatusForbidden},
+		{"缺少令牌", "secret", "", "", http.StatusUnauthorized},
+		{"令牌错误", "secret", "X-Token", "wrong", http.StatusUnauthorized},
+		{"X-Token", "secret", "X-Token", "secret", http.StatusOK},
+		{"Bearer", "secret", "Authorization", "Bearer secret", http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/health/details", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			rec := httptest.NewRecorder()
+			handlers.AdminAuth(tc.token, ok)(rec, req)
+			assert.Equal(t, tc.want, rec.Code)
+		})
+	}
+}
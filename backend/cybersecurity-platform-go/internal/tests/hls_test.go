@@ -0,0 +1,285 @@
+// internal/tests/hls_test.go
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/hls"
+	"cybersecurity-platform-go/internal/media"
+	"cybersecurity-platform-go/internal/static"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSamples 测试轨道：每个样本1秒，内容为 fill 字节加样本序号
+type testSamples struct {
+	handler, fourcc string
+	id              uint32
+	count           int
+	size            int
+	keyEvery        int // 每隔多少个样本一个关键帧，0 表示全部是关键帧
+	fill            byte
+}
+
+func (s testSamples) data(i int) []byte {
+	b := bytes.Repeat([]byte{s.fill}, s.size)
+	b[0] = byte(i)
+	return b
+}
+
+// sampleTrak 构造带样本表的 trak，offsets 为每个样本所在块的偏移量（每个块一个样本）
+func sampleTrak(s testSamples, offsets []uint32) []byte {
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[12:], s.id)
+	if s.handler == "vide" {
+		binary.BigEndian.PutUint32(tkhd[76:], 640<<16)
+		binary.BigEndian.PutUint32(tkhd[80:], 360<<16)
+	}
+	mdhd := make([]byte, 24)
+	binary.BigEndian.PutUint32(mdhd[12:], 1000)
+	binary.BigEndian.PutUint32(mdhd[16:], uint32(1000*s.count))
+	hdlr := append(make([]byte, 8), []byte(s.handler+"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")...)
+
+	stsd := append(append(make([]byte, 4), be32(1)...), mp4Box(s.fourcc, make([]byte, 78))...)
+	stts := append(make([]byte, 4), be32(1)...)
+	stts = append(append(stts, be32(uint32(s.count))...), be32(1000)...)
+	stsz := append(make([]byte, 4), be32(uint32(s.size))...)
+	stsz = append(stsz, be32(uint32(s.count))...)
+	stsc := append(append(make([]byte, 4), be32(1)...), append(append(be32(1), be32(1)...), be32(1)...)...)
+	stco := append(make([]byte, 4), be32(uint32(len(offsets)))...)
+	for _, o := range offsets {
+		stco = append(stco, be32(o)...)
+	}
+	tables := [][]byte{mp4Box("stsd", stsd), mp4Box("stts", stts), mp4Box("stsz", stsz), mp4Box("stsc", stsc), mp4Box("stco", stco)}
+	if s.keyEvery > 0 {
+		var keys []byte
+		n := 0
+		for i := 0; i < s.count; i += s.keyEvery {
+			keys = append(keys, be32(uint32(i+1))...)
+			n++
+		}
+		tables = append(tables, mp4Box("stss", append(append(make([]byte, 4), be32(uint32(n))...), keys...)))
+	}
+
+	return mp4Box("trak",
+		mp4Box("tkhd", tkhd),
+		mp4Box("mdia", mp4Box("mdhd", mdhd), mp4Box("hdlr", hdlr),
+			mp4Box("minf", mp4Box("stbl", tables...))),
+	)
+}
+
+// buildSampleMP4 构造样本交错存放的普通 MP4
+func buildSampleMP4(tracks ...testSamples) []byte {
+	ftyp := mp4Box("ftyp", []byte("isom\x00\x00\x02\x00isomavc1"))
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+
+	build := func(mdatStart int) ([]byte, []byte) {
+		offsets := make([][]uint32, len(tracks))
+		var mdat []byte
+		for i := 0; i < tracks[0].count; i++ {
+			for t, s := range tracks {
+				if i < s.count {
+					offsets[t] = append(offsets[t], uint32(mdatStart+8+len(mdat)))
+					mdat = append(mdat, s.data(i)...)
+				}
+			}
+		}
+		parts := [][]byte{mp4Box("mvhd", mvhd)}
+		for t, s := range tracks {
+			parts = append(parts, sampleTrak(s, offsets[t]))
+		}
+		return mp4Box("moov", parts...), mp4Box("mdat", mdat)
+	}
+
+	moov, _ := build(0)
+	moov, mdat := build(len(ftyp) + len(moov))
+	return bytes.Join([][]byte{ftyp, moov, mdat}, nil)
+}
+
+// childBoxes 解析内存中的 box，返回类型到内容的映射（同类型取第一个）和顺序
+func childBoxes(b []byte) (map[string][]byte, []string) {
+	boxes := map[string][]byte{}
+	var order []string
+	for len(b) >= 8 {
+		size := int(binary.BigEndian.Uint32(b))
+		typ := string(b[4:8])
+		if _, ok := boxes[typ]; !ok {
+			boxes[typ] = b[8:size]
+		}
+		order = append(order, typ)
+		b = b[size:]
+	}
+	return boxes, order
+}
+
+func TestHLSRemux(t *testing.T) {
+	video := testSamples{handler: "vide", fourcc: "avc1", id: 1, count: 30, size: 10, keyEvery: 2, fill: 0xAA}
+	audio := testSamples{handler: "soun", fourcc: "mp4a", id: 2, count: 30, size: 4, fill: 0xBB}
+	file := buildSampleMP4(video, audio)
+
+	out := t.TempDir()
+	variant, err := hls.Remux(bytes.NewReader(file), int64(len(file)), out, 4*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 640, variant.Width)
+	assert.Equal(t, 360, variant.Height)
+	assert.Greater(t, variant.Bandwidth, int64(0))
+
+	playlist, err := os.ReadFile(filepath.Join(out, "index.m3u8"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(playlist), "#EXT-X-TARGETDURATION:4\n")
+	assert.Contains(t, string(playlist), `#EXT-X-MAP:URI="init.mp4"`)
+	assert.Equal(t, 7, strings.Count(string(playlist), "#EXTINF:4.000,"))
+	assert.Contains(t, string(playlist), "#EXTINF:2.000,\nseg_00007.m4s\n#EXT-X-ENDLIST")
+
+	// 初始化分段：样本表已清空，声明了每个轨道的 trex
+	init, err := os.ReadFile(filepath.Join(out, "init.mp4"))
+	assert.NoError(t, err)
+	top, order := childBoxes(init)
+	assert.Equal(t, []string{"ftyp", "moov"}, order)
+	_, moovOrder := childBoxes(top["moov"])
+	assert.Equal(t, []string{"mvhd", "trak", "trak", "mvex"}, moovOrder)
+	assert.NotContains(t, string(top["moov"]), "stss")
+
+	// 第二个分段包含 4s-8s 的视频和音频样本，data_offset 指向正确的数据
+	seg, err := os.ReadFile(filepath.Join(out, "seg_00001.m4s"))
+	assert.NoError(t, err)
+	top, order = childBoxes(seg)
+	assert.Equal(t, []string{"moof", "mdat"}, order)
+	_, moofOrder := childBoxes(top["moof"])
+	assert.Equal(t, []string{"mfhd", "traf", "traf"}, moofOrder)
+
+	var trafs [][]byte
+	for b := top["moof"]; len(b) >= 8; {
+		size := int(binary.BigEndian.Uint32(b))
+		if string(b[4:8]) == "traf" {
+			trafs = append(trafs, b[8:size])
+		}
+		b = b[size:]
+	}
+	for i, s := range []testSamples{video, audio} {
+		boxes, _ := childBoxes(trafs[i])
+		assert.Equal(t, s.id, binary.BigEndian.Uint32(boxes["tfhd"][4:]))
+		assert.Equal(t, uint64(4000), binary.BigEndian.Uint64(boxes["tfdt"][4:]))
+
+		trun := boxes["trun"]
+		assert.Equal(t, uint32(4), binary.BigEndian.Uint32(trun[4:]))
+		dataOffset := int(binary.BigEndian.Uint32(trun[8:]))
+		assert.Equal(t, s.data(4), seg[dataOffset:dataOffset+s.size])
+		if s.keyEvery > 0 {
+			// 第一个样本是关键帧，第二个不是
+			assert.Equal(t, uint32(0x02000000), binary.BigEndian.Uint32(trun[12+8:]))
+			assert.Equal(t, uint32(0x01010000), binary.BigEndian.Uint32(trun[12+16+8:]))
+		}
+	}
+}
+
+func TestHLSRemuxUnsupported(t *testing.T) {
+	audio := buildSampleMP4(testSamples{handler: "soun", fourcc: "mp4a", id: 1, count: 5, size: 4, fill: 1})
+	_, err := hls.Remux(bytes.NewReader(audio), int64(len(audio)), t.TempDir(), 0)
+	assert.ErrorIs(t, err, hls.ErrUnsupported)
+
+	fragmented := append(mp4Box("ftyp", []byte("iso6\x00\x00\x00\x01")), mp4Box("moov", mp4Box("mvhd", make([]byte, 100)))...)
+	fragmented = append(fragmented, mp4Box("moof", mp4Box("mfhd", make([]byte, 8)))...)
+	_, err = hls.Remux(bytes.NewReader(fragmented), int64(len(fragmented)), t.TempDir(), 0)
+	assert.ErrorIs(t, err, hls.ErrUnsupported)
+}
+
+func TestHLSPackageMaster(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "lesson.mp4")
+	assert.NoError(t, os.WriteFile(src, buildSampleMP4(
+		testSamples{handler: "vide", fourcc: "avc1", id: 1, count: 12, size: 10, keyEvery: 3, fill: 0xAA},
+	), 0644))
+
+	out := t.TempDir()
+	variants, err := hls.Package(context.Background(), src, out, hls.Options{})
+	assert.NoError(t, err)
+	assert.Len(t, variants, 1)
+
+	master, err := os.ReadFile(filepath.Join(out, hls.MasterPlaylist))
+	assert.NoError(t, err)
+	assert.Contains(t, string(master), "RESOLUTION=640x360")
+	assert.Contains(t, string(master), "\nsource/index.m3u8\n")
+	assert.FileExists(t, filepath.Join(out, "source", "seg_00001.m4s"))
+}
+
+func TestSignedPlaylists(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "hls", "7", "source")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "hls", "7", "master.m3u8"),
+		[]byte("#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1000\nsource/index.m3u8\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "index.m3u8"),
+		[]byte("#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4.000,\nseg_00000.m4s\n#EXTINF:4.000,\nhttps://cdn.example.com/ad.m4s\n#EXT-X-ENDLIST\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "seg_00000.m4s"), []byte("segment"), 0644))
+
+	signer := media.NewSigner([]byte("secret"), time.Hour)
+	access := func(ctx context.Context, stuID string, courseID int) (bool, error) {
+		return stuID == "20230001" && courseID == 3, nil
+	}
+	open := func(ctx context.Context, name string) (io.ReadCloser, error) {
+		f, err := os.Open(filepath.Join(root, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			return nil, fs.ErrNotExist
+		}
+		return f, err
+	}
+	h := media.Protect(signer, access, media.SignPlaylists(signer, "/api/videoing/", open,
+		http.StripPrefix("/api/videoing/", static.NewDirHandler([]string{root}, nil))))
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+	lines := func(body string) []string {
+		var uris []string
+		for _, l := range strings.Split(body, "\n") {
+			if l != "" && !strings.HasPrefix(l, "#") {
+				uris = append(uris, l)
+			}
+		}
+		return uris
+	}
+
+	master, _ := signer.Sign("/api/videoing/hls/7/master.m3u8", media.Grant{StuID: "20230001", CourseID: 3})
+	rec := get(master)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/vnd.apple.mpegurl", rec.Header().Get("Content-Type"))
+	variant := lines(rec.Body.String())[0]
+	assert.True(t, strings.HasPrefix(variant, "/api/videoing/hls/7/source/index.m3u8?"), variant)
+
+	// 子播放列表中的分段同样带有签名，外部地址不变
+	rec = get(variant)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	uris := lines(body)
+	assert.Equal(t, "https://cdn.example.com/ad.m4s", uris[1])
+	u, _ := url.Parse(uris[0])
+	assert.Equal(t, "/api/videoing/hls/7/source/seg_00000.m4s", u.Path)
+	grant, err := signer.Verify(u.Path, u.Query())
+	assert.NoError(t, err)
+	assert.Equal(t, media.Grant{StuID: "20230001", CourseID: 3}, grant)
+	assert.Contains(t, body, `#EXT-X-MAP:URI="/api/videoing/hls/7/source/init.mp4?`)
+
+	rec = get(uris[0])
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "segment", rec.Body.String())
+
+	// 未签名的分段和不存在的播放列表
+	assert.Equal(t, http.StatusForbidden, get("/api/videoing/hls/7/source/seg_00000.m4s").Code)
+	missing, _ := signer.Sign("/api/videoing/hls/8/master.m3u8", media.Grant{StuID: "20230001", CourseID: 3})
+	assert.Equal(t, http.StatusNotFound, get(missing).Code)
+}
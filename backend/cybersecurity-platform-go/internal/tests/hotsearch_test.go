@@ -0,0 +1,58 @@
+// internal/tests/hotsearch_test.go
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/hotsearch"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSearchTerm(t *testing.T) {
+	for raw, want := range map[string]string{
+		"  SQL   注入 ": "sql 注入",
+		"ＸＳＳ攻击":       "xss攻击",
+		"Web\t安全":     "web 安全",
+		"12345":       "",
+		"？？！":         "",
+	} {
+		assert.Equal(t, want, hotsearch.Normalize(raw), raw)
+	}
+	long := hotsearch.Normalize("渗透测试渗透测试渗透测试渗透测试渗透测试渗透测试渗透测试渗透测试渗透测试渗透测试渗透测试渗透测试渗透测试")
+	assert.Equal(t, hotsearch.MaxTermLen, len([]rune(long)))
+}
+
+func TestRankHotSearch(t *testing.T) {
+	opts := hotsearch.DefaultOptions()
+	opts.Limit = 4
+	stats := []hotsearch.Stats{
+		{Term: "渗透测试", Recent: 0, Window: 9},  // 9
+		{Term: "sql注入", Recent: 3, Window: 4}, // 13，最近升温
+		{Term: "广告词", Recent: 5, Window: 5},   // 屏蔽
+		{Term: "课程", Recent: 8, Window: 20},   // 停用词
+		{Term: "a", Recent: 8, Window: 20},    // 过短
+		{Term: "个人搜索", Recent: 1, Window: 1},  // 人数不足
+		{Term: "xss", Recent: 0, Window: 9},   // 与 渗透测试 同分，按字典序
+		{Term: "密码学", Recent: 0, Window: 2},
+	}
+	terms := hotsearch.Rank(stats, []string{"网络安全", "sql注入"}, map[string]bool{"广告词": true}, opts)
+
+	var list []string
+	for _, term := range terms {
+		list = append(list, term.Term)
+	}
+	assert.Equal(t, []string{"网络安全", "sql注入", "xss", "渗透测试"}, list)
+	assert.True(t, terms[0].Pinned)
+	assert.True(t, terms[1].Pinned)
+	assert.Equal(t, 9.0, terms[2].Score)
+}
+
+func TestHotSearchBoard(t *testing.T) {
+	board := hotsearch.NewBoard(hotsearch.DefaultOptions())
+	assert.Equal(t, []string{}, board.List())
+
+	board.Set([]hotsearch.Term{{Term: "网络安全"}, {Term: "渗透测试"}}, time.Now())
+	assert.Equal(t, []string{"网络安全", "渗透测试"}, board.List())
+}
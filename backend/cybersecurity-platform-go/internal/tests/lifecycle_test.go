@@ -0,0 +1,111 @@
+// internal/tests/lifecycle_test.go
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func expectCourseStatus(mock sqlmock.Sqlmock, courseID int, status string) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM courses WHERE id = ? FOR UPDATE")).
+		WithArgs(courseID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(status))
+}
+
+func expectTeaches(mock sqlmock.Sqlmock, teacherID, courseID int) {
+	expectCourseRole(mock, teacherID, courseID, ops.RoleLead, nil)
+}
+
+// expectCourseRole 教师在课程中的角色（nil 表示不是课程的教师）和课程单独设置的权限（nil 表示使用默认值）
+func expectCourseRole(mock sqlmock.Sqlmock, teacherID, courseID int, role, permissions interface{}) {
+	mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN teacher_courses tc ON tc.teacher_id = ? AND tc.course_id = c.id")).
+		WithArgs(teacherID, courseID).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permissions"}).AddRow(role, permissions))
+}
+
+func TestTeacherSubmitsCourseForReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	expectCourseStatus(mock, 3, ops.CourseDraft)
+	expectTeaches(mock, 7, 3)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM chapter_children cc")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE courses")).
+		WithArgs(ops.CourseReview, "", ops.CourseReview, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 提交审核时清空上次的审核意见
+	assert.NoError(t, ops.SetCourseStatus(db, 7, 3, ops.CourseReview, "忽略"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEmptyCourseCannotBeSubmitted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	expectCourseStatus(mock, 3, ops.CourseDraft)
+	expectTeaches(mock, 7, 3)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM chapter_children cc")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, ops.SetCourseStatus(db, 7, 3, ops.CourseReview, ""), ops.ErrCourseEmpty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCourseTransitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 教师不能自己发布课程
+	expectCourseStatus(mock, 3, ops.CourseReview)
+	expectTeaches(mock, 7, 3)
+	mock.ExpectRollback()
+	assert.ErrorIs(t, ops.SetCourseStatus(db, 7, 3, ops.CoursePublished, ""), ops.ErrInvalidTransition)
+
+	// 管理员不能直接发布草稿
+	expectCourseStatus(mock, 3, ops.CourseDraft)
+	mock.ExpectRollback()
+	assert.ErrorIs(t, ops.SetCourseStatus(db, 0, 3, ops.CoursePublished, ""), ops.ErrInvalidTransition)
+
+	// 管理员退回时保留审核意见
+	expectCourseStatus(mock, 3, ops.CourseReview)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE courses")).
+		WithArgs(ops.CourseDraft, "缺少课程简介", ops.CourseDraft, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, ops.SetCourseStatus(db, 0, 3, ops.CourseDraft, " 缺少课程简介 "))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParseReleaseAt(t *testing.T) {
+	got, err := ops.ParseReleaseAt("")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = ops.ParseReleaseAt("2024-09-02 08:00")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 9, 2, 8, 0, 0, 0, time.Local), *got)
+
+	got, err = ops.ParseReleaseAt("2024-09-02T08:00:00Z")
+	assert.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2024, 9, 2, 8, 0, 0, 0, time.UTC)))
+
+	_, err = ops.ParseReleaseAt("下周一")
+	assert.ErrorAs(t, err, new(ops.InputError))
+}
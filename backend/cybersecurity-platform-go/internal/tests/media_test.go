@@ -0,0 +1,99 @@
+// internal/tests/media_test.go
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/media"
+	"cybersecurity-platform-go/internal/static"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaSigner(t *testing.T) {
+	now := time.Now()
+	signer := media.NewSigner([]byte("secret"), time.Hour)
+	signer.SetClock(func() time.Time { return now })
+
+	signed, err := signer.Sign("http://localhost:3000/api/videoing/lesson%201.mp4?v=2", media.Grant{StuID: "20230001", CourseID: 7})
+	assert.NoError(t, err)
+	u, _ := url.Parse(signed)
+	assert.Equal(t, "/api/videoing/lesson 1.mp4", u.Path)
+	assert.Equal(t, "2", u.Query().Get("v"))
+
+	grant, err := signer.Verify(u.Path, u.Query())
+	assert.NoError(t, err)
+	assert.Equal(t, media.Grant{StuID: "20230001", CourseID: 7}, grant)
+
+	// 修改路径、学号或课程都会导致校验失败
+	_, err = signer.Verify("/api/videoing/other.mp4", u.Query())
+	assert.ErrorIs(t, err, media.ErrInvalidSignature)
+	for param, value := range map[string]string{"uid": "20230002", "cid": "8", "exp": "9999999999"} {
+		q := u.Query()
+		q.Set(param, value)
+		_, err = signer.Verify(u.Path, q)
+		assert.ErrorIs(t, err, media.ErrInvalidSignature, param)
+	}
+
+	// 其他密钥签发的链接无效
+	_, err = media.NewSigner([]byte("other"), time.Hour).Verify(u.Path, u.Query())
+	assert.ErrorIs(t, err, media.ErrInvalidSignature)
+
+	now = now.Add(2 * time.Hour)
+	_, err = signer.Verify(u.Path, u.Query())
+	assert.ErrorIs(t, err, media.ErrExpired)
+}
+
+func TestMediaProtect(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "intro.pdf"), []byte("0123456789"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "cover.jpg"), []byte("jpg"), 0644))
+
+	signer := media.NewSigner([]byte("secret"), time.Hour)
+	enrolled := map[string]bool{"20230001": true}
+	access := func(ctx context.Context, stuID string, courseID int) (bool, error) {
+		return courseID == 3 && enrolled[stuID], nil
+	}
+	h := media.Protect(signer, access, http.StripPrefix("/api/pdfs/", static.NewDirHandler([]string{root}, nil)), ".pdf")
+
+	get := func(target string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 未签名
+	assert.Equal(t, http.StatusForbidden, get("/api/pdfs/intro.pdf").Code)
+	// 非保护的扩展名直接放行
+	assert.Equal(t, http.StatusOK, get("/api/pdfs/cover.jpg").Code)
+
+	// 已选课学生可以按范围读取
+	signed, _ := signer.Sign("/api/pdfs/intro.pdf", media.Grant{StuID: "20230001", CourseID: 3})
+	rec := get(signed, "Range", "bytes=2-5")
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "2345", rec.Body.String())
+	assert.Equal(t, "private, no-store", rec.Header().Get("Cache-Control"))
+
+	// 签名不能用于其他文件
+	assert.Equal(t, http.StatusForbidden, get(strings.Replace(signed, "intro.pdf", "cover.pdf", 1)).Code)
+
+	// 退课后已签发的链接失效
+	delete(enrolled, "20230001")
+	assert.Equal(t, http.StatusForbidden, get(signed).Code)
+
+	// 未选课学生
+	signed, _ = signer.Sign("/api/pdfs/intro.pdf", media.Grant{StuID: "20230009", CourseID: 3})
+	assert.Equal(t, http.StatusForbidden, get(signed).Code)
+}
@@ -0,0 +1,174 @@
+// internal/tests/mediainfo_test.go
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"cybersecurity-platform-go/internal/mediainfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mp4Box 构造 MP4 box
+func mp4Box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	copy(b[4:], typ)
+	return append(b, body...)
+}
+
+func be32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+// mp4Track 构造 trak，handler 为 vide 或 soun
+func mp4Track(handler, fourcc string, width, height int, timescale, duration uint32) []byte {
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], uint32(width)<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], uint32(height)<<16)
+
+	mdhd := make([]byte, 24)
+	binary.BigEndian.PutUint32(mdhd[12:], timescale)
+	binary.BigEndian.PutUint32(mdhd[16:], duration)
+
+	hdlr := append(make([]byte, 8), []byte(handler+"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")...)
+
+	entry := make([]byte, 78)
+	binary.BigEndian.PutUint16(entry[24:], uint16(width))
+	binary.BigEndian.PutUint16(entry[26:], uint16(height))
+	stsd := append(append(make([]byte, 4), be32(1)...), mp4Box(fourcc, entry)...)
+
+	return mp4Box("trak",
+		mp4Box("tkhd", tkhd),
+		mp4Box("mdia",
+			mp4Box("mdhd", mdhd),
+			mp4Box("hdlr", hdlr),
+			mp4Box("minf", mp4Box("stbl", mp4Box("stsd", stsd))),
+		),
+	)
+}
+
+// buildMP4 构造只有元数据和少量媒体数据的 MP4 文件，时长单位为毫秒
+func buildMP4(durationMS uint32, tracks ...[]byte) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], durationMS)
+
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte("isom\x00\x00\x02\x00isomavc1")),
+		mp4Box("moov", append([][]byte{mp4Box("mvhd", mvhd)}, tracks...)...),
+		mp4Box("mdat", bytes.Repeat([]byte{0xAB}, 200)),
+	}, nil)
+}
+
+// ebml 构造 EBML 元素，size 为 -1 时使用未知大小
+func ebml(id uint32, size int, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	var b []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if byte(id>>shift) != 0 || len(b) > 0 {
+			b = append(b, byte(id>>shift))
+		}
+	}
+	if size < 0 {
+		return append(append(b, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF), body...)
+	}
+	sz := make([]byte, 8)
+	binary.BigEndian.PutUint64(sz, uint64(len(body)))
+	sz[0] = 0x01
+	return append(append(b, sz...), body...)
+}
+
+func ebmlUint(id uint32, v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return ebml(id, 0, bytes.TrimLeft(b, "\x00"))
+}
+
+func ebmlFloat(id uint32, v float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(v))
+	return ebml(id, 0, b)
+}
+
+// buildWebM 构造 WebM 文件，时长单位为毫秒（TimecodeScale 为默认的 1ms）
+func buildWebM(docType string, durationMS float64, unknownSize bool) []byte {
+	segmentSize := 0
+	if unknownSize {
+		segmentSize = -1
+	}
+	return append(
+		ebml(0x1A45DFA3, 0, ebml(0x4282, 0, []byte(docType))),
+		ebml(0x18538067, segmentSize,
+			ebml(0x1549A966, 0, ebmlUint(0x2AD7B1, 1000000), ebmlFloat(0x4489, durationMS)),
+			ebml(0x1654AE6B, 0,
+				ebml(0xAE, 0, ebmlUint(0x83, 1), ebml(0x86, 0, []byte("V_VP9")),
+					ebml(0xE0, 0, ebmlUint(0xB0, 640), ebmlUint(0xBA, 360))),
+				ebml(0xAE, 0, ebmlUint(0x83, 2), ebml(0x86, 0, []byte("A_OPUS"))),
+			),
+			ebml(0x1F43B675, segmentSize, bytes.Repeat([]byte{0xA3}, 100)),
+		)...,
+	)
+}
+
+func TestProbeMP4(t *testing.T) {
+	file := buildMP4(12500,
+		mp4Track("vide", "avc1", 1280, 720, 90000, 1125000),
+		mp4Track("soun", "mp4a", 0, 0, 48000, 600000),
+	)
+	info, err := mediainfo.Probe(bytes.NewReader(file), int64(len(file)))
+	assert.NoError(t, err)
+	assert.Equal(t, "mp4", info.Container)
+	assert.Equal(t, 12.5, info.Duration)
+	assert.Equal(t, 13, info.Seconds())
+	assert.Equal(t, 1280, info.Width)
+	assert.Equal(t, 720, info.Height)
+	assert.Equal(t, "h264", info.VideoCodec)
+	assert.Equal(t, "aac", info.AudioCodec)
+	assert.Equal(t, int64(float64(len(file))*8/12.5), info.Bitrate)
+
+	// mvhd 中没有时长时使用视频轨道的时长
+	file = buildMP4(0, mp4Track("vide", "hvc1", 1920, 1080, 90000, 180000))
+	info, err = mediainfo.Probe(bytes.NewReader(file), int64(len(file)))
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, info.Duration)
+	assert.Equal(t, "hevc", info.VideoCodec)
+	assert.Equal(t, "", info.AudioCodec)
+}
+
+func TestProbeInvalid(t *testing.T) {
+	valid := buildMP4(1000, mp4Track("vide", "avc1", 640, 360, 1000, 1000))
+
+	cases := map[string][]byte{
+		"空文件":    {},
+		"文本文件":   []byte("this is not a video file at all"),
+		"截断的MP4": valid[:len(valid)-50],
+		"只有音频":   buildMP4(1000, mp4Track("soun", "mp4a", 0, 0, 1000, 1000)),
+		"没有moov": append(mp4Box("ftyp", []byte("isom\x00\x00\x02\x00")), mp4Box("mdat", []byte("data"))...),
+		"未知文档类型": buildWebM("pdf", 1000, false),
+	}
+	for name, file := range cases {
+		_, err := mediainfo.Probe(bytes.NewReader(file), int64(len(file)))
+		assert.ErrorIs(t, err, mediainfo.ErrInvalidContainer, name)
+	}
+}
+
+func TestProbeWebM(t *testing.T) {
+	for _, unknownSize := range []bool{false, true} {
+		file := buildWebM("webm", 12500, unknownSize)
+		info, err := mediainfo.Probe(bytes.NewReader(file), int64(len(file)))
+		assert.NoError(t, err)
+		assert.Equal(t, "webm", info.Container)
+		assert.InDelta(t, 12.5, info.Duration, 1e-9)
+		assert.Equal(t, 640, info.Width)
+		assert.Equal(t, 360, info.Height)
+		assert.Equal(t, "vp9", info.VideoCodec)
+		assert.Equal(t, "opus", info.AudioCodec)
+	}
+}
@@ -0,0 +1,57 @@
+// internal/tests/migrate_test.go
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/migrate"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMigrateUpResumes 某个迁移的第1条语句已执行（上次在第2条失败），再次执行时从第2条继续
+func TestMigrateUpResumes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 取最后一个包含多条语句的迁移，其余迁移都已执行
+	var last migrate.Migration
+	for _, m := range migrate.Migrations() {
+		if len(m.Statements) > 1 {
+			last = m
+		}
+	}
+	assert.True(t, len(last.Statements) > 1)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migration_steps").WillReturnResult(sqlmock.NewResult(0, 0))
+	applied := sqlmock.NewRows([]string{"version", "name", "applied_at"})
+	for _, m := range migrate.Migrations() {
+		if m.Version != last.Version {
+			applied.AddRow(m.Version, m.Name, time.Now())
+		}
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, name, applied_at FROM schema_migrations")).WillReturnRows(applied)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT step FROM schema_migration_steps WHERE version = ?")).
+		WithArgs(last.Version).WillReturnRows(sqlmock.NewRows([]string{"step"}).AddRow(1))
+	for i := 1; i < len(last.Statements); i++ {
+		mock.ExpectExec(regexp.QuoteMeta(last.Statements[i])).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migration_steps (version, step) VALUES (?, ?)")).
+			WithArgs(last.Version, i+1).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES (?, ?)")).
+		WithArgs(last.Version, last.Name).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migration_steps WHERE version = ?")).
+		WithArgs(last.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	done, err := migrate.Up(db)
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,110 @@
+// internal/tests/prerequisite_test.go
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePrerequisites(t *testing.T) {
+	groups, err := ops.NormalizePrerequisites(3, [][]int{{1, 1}, {}, {2, 5}})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{1}, {2, 5}}, groups)
+
+	_, err = ops.NormalizePrerequisites(3, [][]int{{1, 3}})
+	assert.ErrorAs(t, err, new(ops.InputError))
+	_, err = ops.NormalizePrerequisites(3, [][]int{{0}})
+	assert.ErrorAs(t, err, new(ops.InputError))
+}
+
+func TestFindPrerequisiteCycle(t *testing.T) {
+	// 3 要求 1 或 2，2 要求 4，4 要求 3：3 → 2 → 4 → 3
+	edges := map[int][]int{3: {1, 2}, 2: {4}, 4: {3}, 1: {}}
+	assert.Equal(t, []int{3, 2, 4, 3}, ops.FindPrerequisiteCycle(edges, 3))
+
+	// 菱形依赖不是环
+	edges = map[int][]int{3: {1, 2}, 1: {4}, 2: {4}}
+	assert.Nil(t, ops.FindPrerequisiteCycle(edges, 3))
+}
+
+func TestCheckChapterPrerequisites(t *testing.T) {
+	groups := []ops.PrerequisiteGroup{
+		{Items: []ops.PrerequisiteItem{{ID: 10, Title: "网络基础"}}},
+		{Items: []ops.PrerequisiteItem{{ID: 11, Title: "Linux"}, {ID: 12, Title: "Windows"}}},
+	}
+	check := ops.CheckChapterPrerequisites(groups, map[int]bool{10: true, 12: true})
+	assert.True(t, check.Satisfied)
+	assert.Empty(t, check.Missing)
+	assert.Equal(t, "finished", check.Groups[1].Items[1].Status)
+
+	check = ops.CheckChapterPrerequisites(groups, map[int]bool{11: true})
+	assert.False(t, check.Satisfied)
+	assert.Len(t, check.Missing, 1)
+	assert.Equal(t, 10, check.Missing[0].Items[0].ID)
+	// 原规则不被修改
+	assert.False(t, groups[1].Items[0].Satisfied)
+}
+
+func TestEnrollMissingPrerequisites(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 需要完成《网络基础》，并且完成《Linux入门》或《Windows基础》之一
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)")).
+		WithArgs("2021001").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT limit_count, enroll_mode FROM courses WHERE id = ? AND status = 'published' FOR UPDATE")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"limit_count", "enroll_mode"}).AddRow(30, ops.EnrollModeOpen))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE")).
+		WithArgs("2021001", 3).WillReturnRows(sqlmock.NewRows([]string{"status"}))
+	expectPrerequisites(mock,
+		[]interface{}{0, 1, "网络基础", ops.EnrollActive},
+		[]interface{}{1, 2, "Linux入门", ""},
+		[]interface{}{1, 5, "Windows基础", ops.EnrollCompleted},
+	)
+	mock.ExpectRollback()
+
+	_, err = ops.Enroll(db, "2021001", 3, ops.EnrollOptions{})
+	var prereq *ops.PrerequisiteError
+	assert.ErrorAs(t, err, &prereq)
+	assert.Len(t, prereq.Missing, 1)
+	assert.Equal(t, "需要先完成先修课程：《网络基础》", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetCoursePrerequisitesRejectsCycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 课程1已经要求课程3，再让课程3要求课程1会形成循环
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM courses WHERE id = ?)")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM courses WHERE id IN (?)")).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	// 先锁定哨兵行串行执行，再加锁读取最新的关系图
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM app_locks WHERE name = 'prerequisites' FOR UPDATE")).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("prerequisites"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT course_id, required_course_id FROM course_prerequisites WHERE course_id <> ? LOCK IN SHARE MODE")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"course_id", "required_course_id"}).AddRow(1, 3))
+	for _, c := range []struct {
+		id    int
+		title string
+	}{{3, "渗透测试"}, {1, "网络基础"}, {3, "渗透测试"}} {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT title FROM courses WHERE id = ?")).
+			WithArgs(c.id).WillReturnRows(sqlmock.NewRows([]string{"title"}).AddRow(c.title))
+	}
+	mock.ExpectRollback()
+
+	err = ops.SetCoursePrerequisites(db, 0, 3, [][]int{{1}})
+	assert.ErrorAs(t, err, new(ops.InputError))
+	assert.Equal(t, "先修规则形成循环：《渗透测试》 → 《网络基础》 → 《渗透测试》", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
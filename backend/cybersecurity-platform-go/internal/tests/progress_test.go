@@ -0,0 +1,92 @@
+// internal/tests/progress_test.go
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/progress"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressMerge(t *testing.T) {
+	merged := progress.Merge([]progress.Interval{
+		{Start: 30, End: 40},
+		{Start: 0, End: 10},
+		{Start: 10.5, End: 20}, // 间隙不足1秒，合并
+		{Start: 35, End: 50},
+		{Start: 60, End: 60}, // 空区间
+	})
+	assert.Equal(t, []progress.Interval{{Start: 0, End: 20}, {Start: 30, End: 50}}, merged)
+	assert.Equal(t, 40.0, progress.Covered(merged))
+}
+
+func TestProgressCoverage(t *testing.T) {
+	now := time.Now()
+	l := &progress.Lesson{Duration: 100}
+
+	// 拖动到结尾不算观看
+	assert.NoError(t, l.Apply(progress.Report{Position: 99, Start: 99, End: 99}, now))
+	assert.False(t, l.Completed)
+	assert.Equal(t, 0, l.Percent())
+	assert.Equal(t, 0.0, l.Resume())
+
+	// 反复观看同一段不会增加进度
+	for i := 0; i < 5; i++ {
+		now = now.Add(30 * time.Second)
+		assert.NoError(t, l.Apply(progress.Report{Position: 30, Start: 0, End: 30}, now))
+	}
+	assert.Equal(t, 30, l.Percent())
+	assert.Equal(t, 30.0, l.Resume())
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, l.Apply(progress.Report{Position: 89, Start: 30, End: 89}, now))
+	assert.False(t, l.Completed)
+
+	// 超出时长的部分被截断，覆盖90%后完成
+	now = now.Add(time.Minute)
+	assert.NoError(t, l.Apply(progress.Report{Position: 120, Start: 89, End: 120}, now))
+	assert.True(t, l.Completed)
+	assert.Equal(t, 100, l.Percent())
+	assert.Equal(t, []progress.Interval{{Start: 0, End: 100}}, l.Watched)
+	assert.Equal(t, 0.0, l.Resume())
+
+	// 完成后回看不会取消完成状态
+	now = now.Add(time.Minute)
+	assert.NoError(t, l.Apply(progress.Report{Position: 10, Start: 5, End: 10}, now))
+	assert.True(t, l.Completed)
+	assert.Equal(t, 10.0, l.Resume())
+}
+
+func TestProgressRejectsImplausibleReports(t *testing.T) {
+	now := time.Now()
+	l := &progress.Lesson{Duration: 3600}
+
+	assert.ErrorIs(t, l.Apply(progress.Report{Start: 20, End: 10}, now), progress.ErrInvalidInterval)
+	assert.ErrorIs(t, l.Apply(progress.Report{Start: -1, End: 10}, now), progress.ErrInvalidInterval)
+	assert.ErrorIs(t, l.Apply(progress.Report{Start: 3600, End: 3700}, now), progress.ErrInvalidInterval)
+	assert.ErrorIs(t, l.Apply(progress.Report{Start: 0, End: 600}, now), progress.ErrImplausible)
+
+	assert.NoError(t, l.Apply(progress.Report{Position: 10, Start: 0, End: 10}, now))
+
+	// 10秒内最多以2倍速播放20秒（另有5秒容差）
+	now = now.Add(10 * time.Second)
+	assert.ErrorIs(t, l.Apply(progress.Report{Position: 70, Start: 10, End: 70}, now), progress.ErrImplausible)
+	assert.Equal(t, 10.0, l.Position)
+	assert.NoError(t, l.Apply(progress.Report{Position: 30, Start: 10, End: 30}, now))
+	assert.Equal(t, 30.0, progress.Covered(l.Watched))
+}
+
+func TestProgressRollup(t *testing.T) {
+	var r progress.Rollup
+	r.Add(true, 0.95)
+	r.Add(false, 0.5)
+	r.Add(false, 0)
+	assert.Equal(t, 3, r.Total)
+	assert.Equal(t, 1, r.Completed)
+	assert.Equal(t, 50, r.Percent)
+
+	assert.Equal(t, 29, progress.Percent(0.29))
+	assert.Equal(t, 0.0, progress.Ratio(10, 0))
+}
@@ -0,0 +1,73 @@
+// internal/tests/review_test.go
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectReviewEligibility 锁定课程、查询选课状态和各视频课时的进度（已完成、观看秒数、时长）
+func expectReviewEligibility(mock sqlmock.Sqlmock, status string, lessons ...[]interface{}) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM courses WHERE id = ? AND status IN ('published', 'archived') FOR UPDATE")).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM student_courses WHERE stuId = ? AND course_id = ?")).
+		WithArgs("2021001", 3).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(status))
+	rows := sqlmock.NewRows([]string{"completed", "watched", "duration"})
+	for _, l := range lessons {
+		rows.AddRow(l[0], l[1], l[2])
+	}
+	mock.ExpectQuery("FROM chapters ch").WithArgs("2021001", 3).WillReturnRows(rows)
+}
+
+func TestSubmitReviewBelowProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 两个课时只看了一个的20%，进度10%，低于要求的30%
+	ops.ReviewMinProgress = 30
+	expectReviewEligibility(mock, ops.EnrollActive, []interface{}{false, 120.0, 600.0}, []interface{}{false, 0.0, 600.0})
+	mock.ExpectRollback()
+
+	err = ops.SubmitReview(db, "2021001", 3, ops.ReviewInput{Rating: 5})
+	assert.ErrorIs(t, err, ops.ErrReviewProgress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubmitReviewWithContentIsPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ops.ReviewMinProgress = 30
+	expectReviewEligibility(mock, ops.EnrollActive, []interface{}{true, 600.0, 600.0}, []interface{}{false, 0.0, 600.0})
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT content, status FROM course_reviews WHERE course_id = ? AND stuId = ? FOR UPDATE")).
+		WithArgs(3, "2021001").WillReturnRows(sqlmock.NewRows([]string{"content", "status"}))
+	mock.ExpectExec("INSERT INTO course_reviews").
+		WithArgs(3, "2021001", 4, "讲得很清楚", ops.ReviewPending, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// 审核中的评价也计入评分汇总，被拒绝的不计入
+	mock.ExpectExec("UPDATE courses c LEFT JOIN").WithArgs(3, ops.ReviewRejected, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = ops.SubmitReview(db, "2021001", 3, ops.ReviewInput{Rating: 4, Content: "  讲得很清楚 "})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubmitReviewValidation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	err = ops.SubmitReview(db, "2021001", 3, ops.ReviewInput{Rating: 6})
+	assert.ErrorAs(t, err, new(ops.InputError))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
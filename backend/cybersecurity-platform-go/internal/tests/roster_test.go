@@ -0,0 +1,186 @@
+// internal/tests/roster_test.go
+package tests
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"cybersecurity-platform-go/internal/ops"
+	"cybersecurity-platform-go/internal/sheet"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestSheetXLSXRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"学号", "姓名", "邮箱"},
+		{"2021001", "张三 <A&B>", "zhang@example.com"},
+		{"2021002", "", "li@example.com"},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, sheet.Write(&buf, sheet.FormatXLSX, rows))
+
+	got, err := sheet.Read(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, rows, got)
+}
+
+func TestSheetCSVEncodings(t *testing.T) {
+	// Excel 导出的 CSV 常见 GBK 编码或制表符分隔
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte("学号,姓名\n2021001,张三\n"))
+	assert.NoError(t, err)
+	got, err := sheet.Read(gbk)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"学号", "姓名"}, {"2021001", "张三"}}, got)
+
+	got, err = sheet.Read([]byte("\xEF\xBB\xBFstuId\temail\n2021001\ta@b.c\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"stuId", "email"}, {"2021001", "a@b.c"}}, got)
+
+	var buf bytes.Buffer
+	assert.NoError(t, sheet.Write(&buf, sheet.FormatCSV, [][]string{{"学号"}, {"2021001"}}))
+	got, err = sheet.Read(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"学号"}, {"2021001"}}, got)
+}
+
+func TestSheetWriteEscapesFormulas(t *testing.T) {
+	rows := [][]string{{"姓名", "备注"}, {"=HYPERLINK(\"http://x\")", "@SUM(A1)"}, {"+1", "-2"}, {"张三", "a=b"}}
+	for _, format := range []string{sheet.FormatCSV, sheet.FormatXLSX} {
+		var buf bytes.Buffer
+		assert.NoError(t, sheet.Write(&buf, format, rows))
+		got, err := sheet.Read(buf.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"姓名", "备注"}, {"'=HYPERLINK(\"http://x\")", "'@SUM(A1)"}, {"'+1", "'-2"}, {"张三", "a=b"}}, got)
+	}
+	// 不修改调用方的数据
+	assert.Equal(t, "+1", rows[2][0])
+}
+
+func TestParseRoster(t *testing.T) {
+	_, err := ops.ParseRoster([][]string{{"2021001", "张三"}})
+	assert.ErrorAs(t, err, new(ops.InputError))
+
+	rows, err := ops.ParseRoster([][]string{
+		{"姓名", "Student ID", "E-mail"},
+		{"张三", " 2021001 ", "zhang@example.com"},
+		{"", "", ""},
+		{"李四", "2021002"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []ops.RosterRow{
+		{Line: 2, StuID: "2021001", Name: "张三", Email: "zhang@example.com"},
+		{Line: 4, StuID: "2021002", Name: "李四"},
+	}, rows)
+}
+
+func TestImportRosterDryRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rows := []ops.RosterRow{
+		{Line: 2, StuID: "2021001"},                           // 已有账号，加入课程
+		{Line: 3, StuID: "2021002"},                           // 已在课程中
+		{Line: 4, StuID: "2021003", Email: "new@example.com"}, // 新建账号
+		{Line: 5, StuID: "2021004"},                           // 没有账号也没有邮箱
+		{Line: 6, StuID: "2021001"},                           // 文件内重复
+		{Line: 7, StuID: "2021005", Email: "bad-email"},       // 邮箱格式错误
+	}
+
+	mock.ExpectBegin()
+	expectTeaches(mock, 7, 3)
+	expectRegistered := func(stuID string, ok bool) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)")).
+			WithArgs(stuID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(ok))
+	}
+	expectStatus := func(stuID string, status ...string) {
+		r := sqlmock.NewRows([]string{"status"})
+		for _, s := range status {
+			r.AddRow(s)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE")).
+			WithArgs(stuID, 3).WillReturnRows(r)
+	}
+	expectRegistered("2021001", true)
+	expectStatus("2021001", ops.EnrollDropped)
+	expectRegistered("2021002", true)
+	expectStatus("2021002", ops.EnrollActive)
+	expectRegistered("2021003", false)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE email = ?)")).
+		WithArgs("new@example.com").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	expectRegistered("2021004", false)
+	mock.ExpectRollback()
+
+	result, err := ops.ImportRoster(db, 7, 3, rows, ops.RosterImportOptions{DryRun: true, CreateAccounts: true})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	var results []string
+	for _, row := range result.Rows {
+		results = append(results, row.Result)
+	}
+	assert.Equal(t, []string{ops.RosterEnrolled, ops.RosterExisting, ops.RosterCreated,
+		ops.RosterInvalid, ops.RosterInvalid, ops.RosterInvalid}, results)
+	assert.Equal(t, "学号与第2行重复", result.Rows[4].Error)
+	assert.Empty(t, result.Rows[2].Password)
+	assert.Equal(t, 1, result.Enrolled)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Existing)
+	assert.Equal(t, 3, result.Failed)
+}
+
+func TestImportRosterTooManyRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rows := make([]ops.RosterRow, ops.RosterMaxRows+1)
+	_, err = ops.ImportRoster(db, 7, 3, rows, ops.RosterImportOptions{})
+	assert.ErrorAs(t, err, new(ops.InputError))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportRosterHashesBeforeLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rows := []ops.RosterRow{
+		{Line: 2, StuID: "2021001", Email: "old@example.com"}, // 已有账号，不生成密码
+		{Line: 3, StuID: "2021003", Email: "new@example.com"}, // 新建账号
+	}
+
+	// 先在事务外查询已注册的学号并生成密码摘要，然后才锁定课程
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT stuId FROM students WHERE stuId IN (?, ?)")).
+		WithArgs("2021001", "2021003").WillReturnRows(sqlmock.NewRows([]string{"stuId"}).AddRow("2021001"))
+	mock.ExpectBegin()
+	expectTeaches(mock, 7, 3)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)")).
+		WithArgs("2021001").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM student_courses WHERE stuId = ? AND course_id = ? FOR UPDATE")).
+		WithArgs("2021001", 3).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(ops.EnrollActive))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE stuId = ?)")).
+		WithArgs("2021003").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM students WHERE email = ?)")).
+		WithArgs("new@example.com").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO students (stuId, password, email) VALUES (?, ?, ?)")).
+		WithArgs("2021003", sqlmock.AnyArg(), "new@example.com").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO userdetail").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO student_courses").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE course_waitlist").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE enrollment_requests").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO enrollment_events").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	result, err := ops.ImportRoster(db, 7, 3, rows, ops.RosterImportOptions{CreateAccounts: true})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, ops.RosterExisting, result.Rows[0].Result)
+	assert.Empty(t, result.Rows[0].Password)
+	assert.Equal(t, ops.RosterCreated, result.Rows[1].Result)
+	assert.Len(t, result.Rows[1].Password, 12)
+}
@@ -0,0 +1,40 @@
+// internal/tests/search_test.go
+package tests
+
+import (
+	"testing"
+
+	"cybersecurity-platform-go/internal/search"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTerms(t *testing.T) {
+	terms := search.Terms("  SQL注入  +渗透测试 sql注入 \"xss\" 安")
+	assert.Equal(t, []string{"sql注入", "渗透测试", "xss", "安"}, terms)
+	// 单字检索词无法走 ngram 索引
+	assert.Equal(t, `"sql注入" "渗透测试" "xss"`, search.BooleanQuery(terms))
+	assert.Equal(t, "", search.BooleanQuery(search.Terms("安")))
+}
+
+func TestSearchHighlight(t *testing.T) {
+	terms := search.Terms("渗透测试")
+
+	h, ok := search.Highlight("Web<渗透测试>实战", terms, 0)
+	assert.True(t, ok)
+	assert.Equal(t, "Web&lt;<em>渗透测试</em>&gt;实战", h)
+
+	// 与 ngram 一致，检索词的二元组也能命中
+	h, ok = search.Highlight("内网渗透与测试", terms, 0)
+	assert.True(t, ok)
+	assert.Equal(t, "内网<em>渗透</em>与<em>测试</em>", h)
+
+	_, ok = search.Highlight("密码学基础", terms, 0)
+	assert.False(t, ok)
+
+	// 只截取命中位置附近的片段
+	text := "第一章 网络基础\n第二章 操作系统\n第三章 渗透测试流程\n第四章 漏洞利用\n第五章 报告编写"
+	h, ok = search.Highlight(text, terms, 12)
+	assert.True(t, ok)
+	assert.Equal(t, "…第三章 <em>渗透测试</em>流程 第…", h)
+}
@@ -0,0 +1,45 @@
+// internal/tests/session_test.go
+package tests
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStudentSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+
+	// 签发令牌时清理过期会话，数据库只保存摘要
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM student_sessions WHERE stuId = ? AND expires_at <= ?")).
+		WithArgs("2021001", now).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO student_sessions").
+		WithArgs(sqlmock.AnyArg(), "2021001", now, now.Add(ops.StudentSessionTTL)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	token, err := ops.IssueStudentSession(db, "2021001", now)
+	assert.NoError(t, err)
+	assert.Len(t, token, 64)
+
+	mock.ExpectQuery("FROM student_sessions s").WithArgs(ops.TeacherTokenHash(token), now).
+		WillReturnRows(sqlmock.NewRows([]string{"stuId"}).AddRow("2021001"))
+	stuID, err := ops.StudentSession(db, token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "2021001", stuID)
+
+	// 过期、已注销或账号已禁用
+	mock.ExpectQuery("FROM student_sessions s").WithArgs(ops.TeacherTokenHash(token), now).
+		WillReturnRows(sqlmock.NewRows([]string{"stuId"}))
+	_, err = ops.StudentSession(db, token, now)
+	assert.ErrorIs(t, err, ops.ErrSessionInvalid)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,99 @@
+// internal/tests/static_dirs_test.go
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/static"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveDir(h http.Handler, target string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.URL.Path = target
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDirHandlerRootsAndEscapes(t *testing.T) {
+	base := t.TempDir()
+	primary := filepath.Join(base, "primary")
+	secondary := filepath.Join(base, "secondary")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{primary, secondary, outside, filepath.Join(primary, "sub")} {
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(primary, "a.jpg"), []byte("primary"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(secondary, "a.jpg"), []byte("secondary"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(secondary, "b.jpg"), []byte("only-secondary"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(primary, ".env"), []byte("DB_PASSWORD=x"), 0644))
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(primary, "link.txt")); err != nil {
+		t.Skipf("不支持符号链接: %v", err)
+	}
+	assert.NoError(t, os.Symlink(outside, filepath.Join(primary, "linkdir")))
+
+	h := static.NewDirHandler([]string{filepath.Join(base, "missing"), primary, secondary}, nil)
+
+	// 按目录优先级查找
+	rec := serveDir(h, "a.jpg")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "primary", rec.Body.String())
+	assert.Equal(t, "public, max-age=3600", rec.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+	assert.Equal(t, "only-secondary", serveDir(h, "/b.jpg").Body.String())
+
+	// 路径穿越、符号链接逃逸、隐藏文件和目录列表都返回404
+	for _, p := range []string{"../outside/secret.txt", "/sub/../../outside/secret.txt", "link.txt", "linkdir/secret.txt", ".env", "sub/", "/", "sub"} {
+		rec := serveDir(h, p)
+		assert.Equal(t, http.StatusNotFound, rec.Code, p)
+		assert.NotContains(t, rec.Body.String(), "secret", p)
+	}
+
+	// 条件请求
+	etag := serveDir(h, "a.jpg").Header().Get("ETag")
+	assert.Equal(t, http.StatusNotModified, serveDir(h, "a.jpg", "If-None-Match", etag).Code)
+}
+
+func TestDirHandlerLookupCache(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	h := static.NewDirHandler([]string{root}, nil)
+	h.SetClock(func() time.Time { return now })
+
+	// 找不到的结果会被缓存一段时间
+	assert.Equal(t, http.StatusNotFound, serveDir(h, "late.png").Code)
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "late.png"), []byte("v1"), 0644))
+	assert.Equal(t, http.StatusNotFound, serveDir(h, "late.png").Code)
+
+	now = now.Add(time.Minute)
+	rec := serveDir(h, "late.png")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	firstETag := rec.Header().Get("ETag")
+
+	// 缓存期间文件被修改：重新计算 ETag
+	later := now.Add(time.Hour)
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "late.png"), []byte("version-2"), 0644))
+	assert.NoError(t, os.Chtimes(filepath.Join(root, "late.png"), later, later))
+	rec = serveDir(h, "late.png")
+	assert.Equal(t, "version-2", rec.Body.String())
+	assert.NotEqual(t, firstETag, rec.Header().Get("ETag"))
+
+	// 文件被删除
+	assert.NoError(t, os.Remove(filepath.Join(root, "late.png")))
+	assert.Equal(t, http.StatusNotFound, serveDir(h, "late.png").Code)
+
+	// 带内容哈希的文件名使用长期缓存
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "app.3f2a1b4c.js"), []byte("js"), 0644))
+	assert.Equal(t, "public, max-age=31536000, immutable", serveDir(h, "app.3f2a1b4c.js").Header().Get("Cache-Control"))
+}
@@ -0,0 +1,98 @@
+// internal/tests/static_fs_test.go
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"cybersecurity-platform-go/internal/static"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDist() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":            {Data: []byte("<html>app</html>")},
+		"js/app.3f2a1b4c.js":    {Data: []byte("console.log('app')")},
+		"js/app.3f2a1b4c.js.gz": {Data: []byte("gzip-bytes")},
+		"js/app.3f2a1b4c.js.br": {Data: []byte("br-bytes")},
+		"favicon.ico":           {Data: []byte("ico")},
+		"img/logo.png":          {Data: []byte("png")},
+	}
+}
+
+func TestEmbeddedSPAFallback(t *testing.T) {
+	h := static.NewFSHandler(newTestDist(), "index.html")
+
+	cases := []struct {
+		name   string
+		path   string
+		accept string
+		status int
+		body   string
+	}{
+		{"首页", "/", "text/html", http.StatusOK, "<html>app</html>"},
+		{"前端路由回退", "/course/12", "text/html,application/xhtml+xml", http.StatusOK, "<html>app</html>"},
+		{"缺失的资源不回退", "/js/missing.js", "*/*", http.StatusNotFound, ""},
+		{"非页面请求不回退", "/course/12", "application/json", http.StatusNotFound, ""},
+		{"目录不列出内容", "/js/", "application/json", http.StatusNotFound, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Accept", tc.accept)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.body != "" {
+				assert.Equal(t, tc.body, rec.Body.String())
+				assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestEmbeddedPrecompressed(t *testing.T) {
+	h := static.NewFSHandler(newTestDist(), "index.html")
+
+	req := httptest.NewRequest(http.MethodGet, "/js/app.3f2a1b4c.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "br-bytes", rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Type"), "javascript")
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+
+	req = httptest.NewRequest(http.MethodGet, "/js/app.3f2a1b4c.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br;q=0")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+	req = httptest.NewRequest(http.MethodGet, "/js/app.3f2a1b4c.js", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "console.log('app')", rec.Body.String())
+
+	// 相同内容再次请求时返回304
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	req = httptest.NewRequest(http.MethodGet, "/js/app.3f2a1b4c.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+}
+
+func TestCacheControlPolicy(t *testing.T) {
+	assert.Equal(t, "no-cache", static.CacheControl("index.html"))
+	assert.Equal(t, "public, max-age=31536000, immutable", static.CacheControl("css/chunk-vendors.9a8b7c6d.css"))
+	assert.Equal(t, "public, max-age=3600", static.CacheControl("favicon.ico"))
+	assert.Equal(t, "public, max-age=3600", static.CacheControl("avatar-1755575458236-655681257.jpg"))
+}
@@ -0,0 +1,77 @@
+// internal/tests/subject_test.go
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSubjectTree(t *testing.T) {
+	flat := []ops.Subject{
+		{ID: 1, Title: "网络安全"},
+		{ID: 2, ParentID: 1, Title: "Web安全"},
+		{ID: 3, ParentID: 2, Title: "SQL注入"},
+		{ID: 4, ParentID: 2, Title: "XSS"},
+		{ID: 5, Title: "密码学"},
+		{ID: 6, ParentID: 99, Title: "父分类已删除"},
+	}
+	// 课程10同时属于 Web安全 和 SQL注入，只计一次
+	courses := map[int][]int{2: {10}, 3: {10, 11}, 4: {12}}
+
+	tree := ops.BuildSubjectTree(flat, courses)
+	assert.Len(t, tree, 3)
+	assert.Equal(t, "网络安全", tree[0].Title)
+	assert.Equal(t, 1, tree[0].CateID)
+	assert.Equal(t, 3, tree[0].Count)
+	assert.Equal(t, 3, tree[0].Children[0].Count)
+	assert.Equal(t, 2, tree[0].Children[0].Children[0].Count)
+	assert.Equal(t, []int{1, 2, 3, 4}, tree[0].SubtreeIDs())
+	assert.Equal(t, 0, tree[1].Count)
+	assert.NotNil(t, tree[1].Children)
+	assert.Equal(t, 0, tree[2].ParentID)
+}
+
+func TestSubjectCannotMoveUnderDescendant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 把 网络安全(1) 移动到 SQL注入(3) 下：3 → 2 → 1
+	parent := 3
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(parent_id, 0), title FROM subjects WHERE id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id", "title"}).AddRow(0, "网络安全"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT parent_id FROM subjects WHERE id = ?")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT parent_id FROM subjects WHERE id = ?")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(1))
+
+	err = ops.UpdateSubject(db, 1, ops.SubjectInput{ParentID: &parent})
+	assert.ErrorAs(t, err, new(ops.InputError))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSubjectWithChildren(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM subjects WHERE parent_id = ?) FROM subjects WHERE id = ?")).
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"has_children"}).AddRow(true))
+	assert.ErrorIs(t, ops.DeleteSubject(db, 2), ops.ErrSubjectHasChildren)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM subjects WHERE parent_id = ?) FROM subjects WHERE id = ?")).
+		WithArgs(8, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"has_children"}))
+	assert.ErrorIs(t, ops.DeleteSubject(db, 8), ops.ErrSubjectNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
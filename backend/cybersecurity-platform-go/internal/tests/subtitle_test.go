@@ -0,0 +1,84 @@
+// internal/tests/subtitle_test.go
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/subtitle"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubtitleSRTToVTT(t *testing.T) {
+	srt := "\xEF\xBB\xBF1\r\n00:00:01,000 --> 00:00:04,500\r\n<font color=\"red\">什么是</font> <i>SQL 注入</i>？\r\n\r\n" +
+		"2\r\n00:01:02,250 --> 00:01:05,000\r\n攻击者在输入中拼接 SQL 语句\r\n第二行\r\n"
+
+	assert.Equal(t, subtitle.FormatSRT, subtitle.DetectFormat([]byte(srt)))
+	cues, err := subtitle.Parse([]byte(srt), "")
+	assert.NoError(t, err)
+	assert.Len(t, cues, 2)
+	assert.Equal(t, time.Second, cues[0].Start)
+	assert.Equal(t, 4500*time.Millisecond, cues[0].End)
+	assert.Equal(t, "什么是 <i>SQL 注入</i>？", cues[0].Text)
+	assert.Equal(t, "什么是 SQL 注入？", cues[0].PlainText())
+	assert.Equal(t, "攻击者在输入中拼接 SQL 语句 第二行", cues[1].PlainText())
+
+	assert.Equal(t, "WEBVTT\n\n"+
+		"00:00:01.000 --> 00:00:04.500\n什么是 <i>SQL 注入</i>？\n\n"+
+		"00:01:02.250 --> 00:01:05.000\n攻击者在输入中拼接 SQL 语句\n第二行\n",
+		string(subtitle.WriteVTT(cues)))
+}
+
+func TestSubtitleVTT(t *testing.T) {
+	vtt := "WEBVTT - 第一课\nKind: captions\n\n" +
+		"NOTE 这是注释\n跨两行\n\n" +
+		"STYLE\n::cue { color: yellow }\n\n" +
+		"intro\n00:05.000 --> 00:07.000 align:start line:0\n<v 老师>大家好 &amp; 欢迎\n\n" +
+		"01:00:00.000 --> 01:00:01.000\n结束\n"
+
+	cues, err := subtitle.Parse([]byte(vtt), subtitle.FormatVTT)
+	assert.NoError(t, err)
+	assert.Len(t, cues, 2)
+	assert.Equal(t, "intro", cues[0].ID)
+	assert.Equal(t, 5*time.Second, cues[0].Start)
+	assert.Equal(t, "align:start line:0", cues[0].Settings)
+	assert.Equal(t, "大家好 & 欢迎", cues[0].PlainText())
+	assert.Equal(t, time.Hour, cues[1].Start)
+
+	// 重新生成的 WebVTT 可以再次解析
+	again, err := subtitle.Parse(subtitle.WriteVTT(cues), "")
+	assert.NoError(t, err)
+	assert.Equal(t, cues, again)
+}
+
+func TestSubtitleValidation(t *testing.T) {
+	cases := []struct {
+		name, format, data string
+		line               int
+	}{
+		{"缺少文件头", subtitle.FormatVTT, "00:01.000 --> 00:02.000\n文本\n", 1},
+		{"结束早于开始", subtitle.FormatVTT, "WEBVTT\n\n00:05.000 --> 00:02.000\n文本\n", 3},
+		{"无效时间", subtitle.FormatVTT, "WEBVTT\n\n00:75.000 --> 00:80.000\n文本\n", 3},
+		{"内容为空", subtitle.FormatSRT, "1\n00:00:01,000 --> 00:00:02,000\n", 1},
+		{"顺序错误", subtitle.FormatSRT, "1\n00:00:05,000 --> 00:00:06,000\n甲\n\n2\n00:00:01,000 --> 00:00:02,000\n乙\n", 5},
+		{"无效序号", subtitle.FormatSRT, "一\n00:00:01,000 --> 00:00:02,000\n甲\n", 1},
+		{"缺少时间轴", subtitle.FormatSRT, "1\n", 2},
+		{"SRT 缺少小时", subtitle.FormatSRT, "1\n00:01,000 --> 00:02,000\n甲\n", 2},
+		{"没有字幕", subtitle.FormatVTT, "WEBVTT\n\n", 3},
+	}
+	for _, c := range cases {
+		_, err := subtitle.Parse([]byte(c.data), c.format)
+		var perr *subtitle.ParseError
+		if assert.ErrorAs(t, err, &perr, c.name) {
+			assert.Equal(t, c.line, perr.Line, c.name)
+		}
+	}
+
+	_, err := subtitle.Parse([]byte("WEBVTT\n\n00:01.000 --> 00:02.000\n\xff\xfe\n"), "")
+	assert.Error(t, err)
+
+	for lang, ok := range map[string]bool{"zh": true, "en": true, "zh-Hans": true, "pt-BR": true, "": false, "zh_CN": false, "../x": false, "english": false} {
+		assert.Equal(t, ok, subtitle.ValidLang(lang), lang)
+	}
+}
@@ -0,0 +1,66 @@
+// internal/tests/teaching_test.go
+package tests
+
+import (
+	"testing"
+
+	"cybersecurity-platform-go/internal/ops"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePermissions(t *testing.T) {
+	perms, err := ops.ParsePermissions(" reviews,content,,reviews ")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ops.PermContent, ops.PermReviews}, perms)
+
+	perms, err = ops.ParsePermissions("")
+	assert.NoError(t, err)
+	assert.Empty(t, perms)
+
+	// 删除课程和设置教师只属于负责人，不能配置
+	_, err = ops.ParsePermissions("students,manage")
+	assert.ErrorAs(t, err, new(ops.InputError))
+}
+
+func TestCourseRolePermissions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	title := "新标题"
+	// 助教默认只能管理学生，不能修改课程内容
+	expectCourseRole(mock, 7, 3, ops.RoleTA, nil)
+	assert.ErrorIs(t, ops.UpdateCourse(db, 7, 3, ops.CourseInput{Title: &title}), ops.ErrNoCoursePermission)
+
+	// 课程单独设置的权限优先于默认值：该课程的合作教师不能查看评价
+	expectCourseRole(mock, 8, 3, ops.RoleCoTeacher, "content,students")
+	_, err = ops.TeacherCourseReviews(db, 8, 3)
+	assert.ErrorIs(t, err, ops.ErrNoCoursePermission)
+
+	// 设置课程教师只有负责人可以
+	mock.ExpectBegin()
+	expectCourseRole(mock, 8, 3, ops.RoleCoTeacher, "content,students,reviews,publish")
+	mock.ExpectRollback()
+	err = ops.SetCourseTeachers(db, 8, 3, []ops.CourseTeacherInput{{TeacherID: 8, Role: ops.RoleLead}})
+	assert.ErrorIs(t, err, ops.ErrNoCoursePermission)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetCourseTeachersValidation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	for _, list := range [][]ops.CourseTeacherInput{
+		{{TeacherID: 7, Role: ops.RoleTA}},                                      // 没有负责人
+		{{TeacherID: 7, Role: ops.RoleLead}, {TeacherID: 7, Role: ops.RoleTA}},  // 教师重复
+		{{TeacherID: 7, Role: ops.RoleLead}, {TeacherID: 8, Role: "assistant"}}, // 无效角色
+	} {
+		assert.ErrorAs(t, ops.SetCourseTeachers(db, 0, 3, list), new(ops.InputError))
+	}
+	// 校验失败时不访问数据库
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
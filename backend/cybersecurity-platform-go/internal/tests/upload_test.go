@@ -0,0 +1,226 @@
+// internal/tests/upload_test.go
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cybersecurity-platform-go/internal/storage"
+	"cybersecurity-platform-go/internal/upload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memSessions 内存中的上传会话存储
+type memSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*upload.Session
+	videos   map[int]int64 // 教师ID -> 已完成视频占用的字节数
+}
+
+func newMemSessions() *memSessions {
+	return &memSessions{sessions: map[string]*upload.Session{}, videos: map[int]int64{}}
+}
+
+func (m *memSessions) Create(ctx context.Context, s *upload.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *s
+	m.sessions[s.ID] = &copied
+	return nil
+}
+
+func (m *memSessions) Get(ctx context.Context, id string) (*upload.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, upload.ErrNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (m *memSessions) Advance(ctx context.Context, id string, from, to int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok || s.Offset != from || s.Status != upload.StatusUploading {
+		return false, nil
+	}
+	s.Offset = to
+	return true, nil
+}
+
+func (m *memSessions) Complete(ctx context.Context, id string, videoID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.sessions[id]
+	s.Status, s.VideoID = upload.StatusComplete, videoID
+	m.videos[s.TeacherID] += s.Size
+	return nil
+}
+
+func (m *memSessions) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[id]; ok && s.Status == upload.StatusUploading {
+		delete(m.sessions, id)
+	}
+	return nil
+}
+
+func (m *memSessions) UsedBytes(ctx context.Context, teacherID int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	used := m.videos[teacherID]
+	for _, s := range m.sessions {
+		if s.TeacherID == teacherID && s.Status == upload.StatusUploading {
+			used += s.Size
+		}
+	}
+	return used, nil
+}
+
+func (m *memSessions) Stale(ctx context.Context, before time.Time) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []string
+	for id, s := range m.sessions {
+		if s.Status == upload.StatusUploading && s.UpdatedAt.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func chunkChecksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func newUploadService(t *testing.T, quota int64) (*upload.Service, *memSessions, string, *[]string) {
+	root := t.TempDir()
+	tmp := t.TempDir()
+	sessions := newMemSessions()
+	var finalized []string
+	finalize := func(ctx context.Context, sess *upload.Session, key string) (int64, error) {
+		finalized = append(finalized, key)
+		return int64(len(finalized)), nil
+	}
+	svc := upload.NewService(upload.Config{TempDir: tmp, MaxSize: 1 << 20, Quota: quota, MaxChunk: 256},
+		sessions, storage.NewLocal(root, "", []byte("k")), finalize)
+	return svc, sessions, root, &finalized
+}
+
+func TestUploadResumable(t *testing.T) {
+	ctx := context.Background()
+	svc, _, root, finalized := newUploadService(t, 0)
+
+	data := buildMP4(3000, mp4Track("vide", "avc1", 640, 360, 1000, 3000))
+	assert.Greater(t, len(data), 512)
+	sum := sha256.Sum256(data)
+	sess, err := svc.Create(ctx, 1, `C:\videos\lesson.mp4`, int64(len(data)), hex.EncodeToString(sum[:]), "第一课")
+	assert.NoError(t, err)
+	assert.Equal(t, "lesson.mp4", sess.Filename)
+
+	// 其他教师看不到该会话
+	_, err = svc.Status(ctx, sess.ID, 2)
+	assert.ErrorIs(t, err, upload.ErrNotFound)
+
+	// 第一个分片
+	got, err := svc.Append(ctx, sess.ID, 1, 0, bytes.NewReader(data[:256]), chunkChecksum(data[:256]))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(256), got.Offset)
+
+	// 重复发送同一分片（客户端未收到响应后重试）返回服务器的偏移量
+	_, err = svc.Append(ctx, sess.ID, 1, 0, bytes.NewReader(data[:256]), "")
+	var mismatch *upload.OffsetMismatchError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, int64(256), mismatch.Offset)
+
+	// 分片超过限制
+	_, err = svc.Append(ctx, sess.ID, 1, 256, bytes.NewReader(data[256:]), "")
+	assert.ErrorIs(t, err, upload.ErrChunkTooLarge)
+
+	// 分片校验失败不改变偏移量
+	_, err = svc.Append(ctx, sess.ID, 1, 256, bytes.NewReader(data[256:512]), chunkChecksum(data[:256]))
+	assert.ErrorIs(t, err, upload.ErrChecksumMismatch)
+	_, err = svc.Append(ctx, sess.ID, 1, 256, bytes.NewReader(data[256:512]), "md5 xxx")
+	assert.ErrorIs(t, err, upload.ErrBadChecksum)
+
+	// 断点续传
+	status, err := svc.Status(ctx, sess.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(256), status.Offset)
+	_, err = svc.Append(ctx, sess.ID, 1, 256, bytes.NewReader(data[256:512]), chunkChecksum(data[256:512]))
+	assert.NoError(t, err)
+	got, err = svc.Append(ctx, sess.ID, 1, 512, bytes.NewReader(data[512:]), "")
+	assert.NoError(t, err)
+	assert.Equal(t, upload.StatusComplete, got.Status)
+	assert.Equal(t, int64(1), got.VideoID)
+	assert.Equal(t, 3, got.Media.Seconds())
+	assert.Equal(t, "h264", got.Media.VideoCodec)
+
+	key := "videos/" + sess.ID + ".mp4"
+	assert.Equal(t, []string{key}, *finalized)
+	stored, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(key)))
+	assert.NoError(t, err)
+	assert.Equal(t, data, stored)
+
+	_, err = svc.Append(ctx, sess.ID, 1, int64(len(data)), strings.NewReader(""), "")
+	assert.ErrorIs(t, err, upload.ErrCompleted)
+}
+
+func TestUploadChecksumAndLimits(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, finalized := newUploadService(t, 30)
+
+	_, err := svc.Create(ctx, 1, "notes.txt", 10, "", "")
+	assert.ErrorIs(t, err, upload.ErrUnsupportedType)
+	_, err = svc.Create(ctx, 1, "big.mp4", 2<<20, "", "")
+	assert.ErrorIs(t, err, upload.ErrTooLarge)
+	_, err = svc.Create(ctx, 1, "a.mp4", 4, "abc", "")
+	assert.ErrorIs(t, err, upload.ErrBadChecksum)
+
+	// 整个文件校验失败时丢弃会话
+	wrong := sha256.Sum256([]byte("other"))
+	sess, err := svc.Create(ctx, 1, "a.webm", 4, hex.EncodeToString(wrong[:]), "")
+	assert.NoError(t, err)
+	_, err = svc.Append(ctx, sess.ID, 1, 0, strings.NewReader("data"), "")
+	assert.ErrorIs(t, err, upload.ErrChecksumMismatch)
+	_, err = svc.Status(ctx, sess.ID, 1)
+	assert.ErrorIs(t, err, upload.ErrNotFound)
+	assert.Empty(t, *finalized)
+
+	// 不是有效视频的文件在完成时被拒绝
+	sess, err = svc.Create(ctx, 1, "fake.mp4", 4, "", "")
+	assert.NoError(t, err)
+	_, err = svc.Append(ctx, sess.ID, 1, 0, strings.NewReader("data"), "")
+	assert.ErrorIs(t, err, upload.ErrInvalidVideo)
+	_, err = svc.Status(ctx, sess.ID, 1)
+	assert.ErrorIs(t, err, upload.ErrNotFound)
+	assert.Empty(t, *finalized)
+
+	// 未完成的会话占用配额，取消后释放
+	first, err := svc.Create(ctx, 1, "a.mp4", 20, "", "")
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, 1, "b.mp4", 20, "", "")
+	assert.ErrorIs(t, err, upload.ErrQuotaExceeded)
+	_, err = svc.Create(ctx, 2, "b.mp4", 20, "", "")
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, svc.Abort(ctx, first.ID, 2), upload.ErrNotFound)
+	assert.NoError(t, svc.Abort(ctx, first.ID, 1))
+	_, err = svc.Create(ctx, 1, "b.mp4", 20, "", "")
+	assert.NoError(t, err)
+}
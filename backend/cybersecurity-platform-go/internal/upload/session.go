@@ -0,0 +1,175 @@
+// internal/upload/session.go
+package upload
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cybersecurity-platform-go/internal/mediainfo"
+)
+
+// 上传会话状态
+const (
+	StatusUploading = "uploading"
+	StatusComplete  = "complete"
+)
+
+// Session 分片上传会话
+type Session struct {
+	ID          string          `json:"uploadId"`
+	TeacherID   int             `json:"teacherId"`
+	Filename    string          `json:"filename"`
+	Size        int64           `json:"size"`
+	Offset      int64           `json:"offset"`
+	Checksum    string          `json:"sha256,omitempty"` // 整个文件的 SHA-256（十六进制），为空表示不校验
+	Description string          `json:"description"`
+	Status      string          `json:"status"`
+	VideoID     int64           `json:"videoId,omitempty"`
+	Media       *mediainfo.Info `json:"media,omitempty"` // 上传完成后解析的视频元数据
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// SessionStore 上传会话的持久化存储
+type SessionStore interface {
+	Create(ctx context.Context, s *Session) error
+	// Get 查询会话，不存在时返回 ErrNotFound
+	Get(ctx context.Context, id string) (*Session, error)
+	// Advance 仅当当前偏移量等于 from 时更新为 to，返回是否更新成功
+	Advance(ctx context.Context, id string, from, to int64) (bool, error)
+	Complete(ctx context.Context, id string, videoID int64) error
+	Delete(ctx context.Context, id string) error
+	// UsedBytes 教师已占用的空间：已完成的视频和未完成会话声明的大小
+	UsedBytes(ctx context.Context, teacherID int) (int64, error)
+	// Stale 返回在 before 之前最后更新、仍未完成的会话ID
+	Stale(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// SQLStore 基于 video_uploads 表的会话存储
+type SQLStore struct {
+	db func() (*sql.DB, error)
+}
+
+// NewSQLStore 创建会话存储，db 通常为 database.GetDB
+func NewSQLStore(db func() (*sql.DB, error)) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// Create 保存新会话
+func (s *SQLStore) Create(ctx context.Context, sess *Session) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO video_uploads (id, teacher_id, filename, size, received, checksum, description, status) VALUES (?, ?, ?, ?, 0, ?, ?, ?)",
+		sess.ID, sess.TeacherID, sess.Filename, sess.Size, sess.Checksum, sess.Description, StatusUploading,
+	)
+	return err
+}
+
+// Get 查询会话
+func (s *SQLStore) Get(ctx context.Context, id string) (*Session, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var sess Session
+	var description sql.NullString
+	var videoID sql.NullInt64
+	err = db.QueryRowContext(ctx, `
+		SELECT id, teacher_id, filename, size, received, checksum, description, status, video_id, created_at, updated_at
+		FROM video_uploads WHERE id = ?
+	`, id).Scan(&sess.ID, &sess.TeacherID, &sess.Filename, &sess.Size, &sess.Offset, &sess.Checksum,
+		&description, &sess.Status, &videoID, &sess.CreatedAt, &sess.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	sess.Description = description.String
+	sess.VideoID = videoID.Int64
+	return &sess, nil
+}
+
+// Advance 更新偏移量（乐观锁，避免并发的分片请求互相覆盖）
+func (s *SQLStore) Advance(ctx context.Context, id string, from, to int64) (bool, error) {
+	db, err := s.db()
+	if err != nil {
+		return false, err
+	}
+	result, err := db.ExecContext(ctx,
+		"UPDATE video_uploads SET received = ? WHERE id = ? AND received = ? AND status = ?",
+		to, id, from, StatusUploading,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// Complete 标记会话完成
+func (s *SQLStore) Complete(ctx context.Context, id string, videoID int64) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx,
+		"UPDATE video_uploads SET status = ?, video_id = ? WHERE id = ?", StatusComplete, videoID, id,
+	)
+	return err
+}
+
+// Delete 删除会话
+func (s *SQLStore) Delete(ctx context.Context, id string) error {
+	db, err := s.db()
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "DELETE FROM video_uploads WHERE id = ? AND status = ?", id, StatusUploading)
+	return err
+}
+
+// UsedBytes 统计教师占用的空间
+func (s *SQLStore) UsedBytes(ctx context.Context, teacherID int) (int64, error) {
+	db, err := s.db()
+	if err != nil {
+		return 0, err
+	}
+	var used int64
+	err = db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE((SELECT SUM(size) FROM videos WHERE teacher_id = ?), 0) +
+			COALESCE((SELECT SUM(size) FROM video_uploads WHERE teacher_id = ? AND status = ?), 0)
+	`, teacherID, teacherID, StatusUploading).Scan(&used)
+	return used, err
+}
+
+// Stale 查询长时间未更新的会话
+func (s *SQLStore) Stale(ctx context.Context, before time.Time) ([]string, error) {
+	db, err := s.db()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx,
+		"SELECT id FROM video_uploads WHERE status = ? AND updated_at < ?", StatusUploading, before,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
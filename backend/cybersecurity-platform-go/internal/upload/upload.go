@@ -0,0 +1,382 @@
+// internal/upload/upload.go
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cybersecurity-platform-go/internal/mediainfo"
+	"cybersecurity-platform-go/internal/storage"
+)
+
+var (
+	// ErrNotFound 上传会话不存在、已过期或不属于当前教师
+	ErrNotFound = errors.New("上传会话不存在")
+	// ErrCompleted 上传已完成
+	ErrCompleted = errors.New("上传已完成")
+	// ErrTooLarge 文件超过单个文件大小限制
+	ErrTooLarge = errors.New("文件超过大小限制")
+	// ErrQuotaExceeded 超出教师的存储配额
+	ErrQuotaExceeded = errors.New("超出存储配额")
+	// ErrUnsupportedType 不支持的视频格式
+	ErrUnsupportedType = errors.New("不支持的视频格式")
+	// ErrChunkTooLarge 分片超过大小限制或超出文件声明的大小
+	ErrChunkTooLarge = errors.New("分片过大")
+	// ErrChecksumMismatch 分片或文件的校验和不匹配
+	ErrChecksumMismatch = errors.New("校验和不匹配")
+	// ErrBadChecksum 校验和格式错误
+	ErrBadChecksum = errors.New("校验和格式错误")
+	// ErrInvalidVideo 文件不是可播放的视频
+	ErrInvalidVideo = errors.New("文件不是可播放的视频")
+)
+
+// OffsetMismatchError 分片的起始偏移量与服务器记录不一致，客户端应从 Offset 处继续上传
+type OffsetMismatchError struct {
+	Offset int64
+}
+
+func (e *OffsetMismatchError) Error() string {
+	return fmt.Sprintf("上传偏移量不匹配，服务器已接收 %d 字节", e.Offset)
+}
+
+// 支持上传的视频扩展名
+var videoExts = map[string]bool{".mp4": true, ".m4v": true, ".mov": true, ".webm": true}
+
+// FinalizeFunc 上传完成后调用：文件已写入存储的 key，返回创建的视频ID
+type FinalizeFunc func(ctx context.Context, sess *Session, key string) (int64, error)
+
+// Config 上传限制
+type Config struct {
+	TempDir  string        // 分片暂存目录
+	MaxSize  int64         // 单个文件最大字节数
+	Quota    int64         // 每位教师的存储配额（字节），0 表示不限制
+	MaxChunk int64         // 单个分片最大字节数
+	Expiry   time.Duration // 会话无活动多久后过期
+}
+
+// Service 可断点续传的分片上传服务
+// 分片按顺序追加到暂存文件，每个分片可带 SHA-256 校验；全部接收后校验整个文件，
+// 写入存储并通过 FinalizeFunc 创建视频记录
+type Service struct {
+	cfg      Config
+	sessions SessionStore
+	store    storage.Storage
+	finalize FinalizeFunc
+	now      func() time.Time
+
+	locks sync.Map // 会话ID -> *sync.Mutex，同一会话的分片串行处理
+}
+
+// NewService 创建上传服务
+func NewService(cfg Config, sessions SessionStore, store storage.Storage, finalize FinalizeFunc) *Service {
+	if cfg.MaxChunk <= 0 {
+		cfg.MaxChunk = 64 << 20
+	}
+	if cfg.Expiry <= 0 {
+		cfg.Expiry = 24 * time.Hour
+	}
+	return &Service{cfg: cfg, sessions: sessions, store: store, finalize: finalize, now: time.Now}
+}
+
+// ChunkSize 建议的分片大小
+func (s *Service) ChunkSize() int64 {
+	if s.cfg.MaxChunk < 8<<20 {
+		return s.cfg.MaxChunk
+	}
+	return 8 << 20
+}
+
+// Create 创建上传会话，checksum 为整个文件的 SHA-256（十六进制，可为空）
+func (s *Service) Create(ctx context.Context, teacherID int, filename string, size int64, checksum, description string) (*Session, error) {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if !videoExts[strings.ToLower(path.Ext(filename))] {
+		return nil, ErrUnsupportedType
+	}
+	if size <= 0 || (s.cfg.MaxSize > 0 && size > s.cfg.MaxSize) {
+		return nil, ErrTooLarge
+	}
+	checksum = strings.ToLower(checksum)
+	if checksum != "" {
+		if b, err := hex.DecodeString(checksum); err != nil || len(b) != sha256.Size {
+			return nil, ErrBadChecksum
+		}
+	}
+
+	// 清理过期会话，释放其占用的配额
+	if _, err := s.Cleanup(ctx); err != nil {
+		log.Printf("清理过期上传会话失败: %v", err)
+	}
+
+	if s.cfg.Quota > 0 {
+		used, err := s.sessions.UsedBytes(ctx, teacherID)
+		if err != nil {
+			return nil, err
+		}
+		if used+size > s.cfg.Quota {
+			return nil, ErrQuotaExceeded
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.cfg.TempDir, 0755); err != nil {
+		return nil, err
+	}
+
+	sess := &Session{
+		ID:          id,
+		TeacherID:   teacherID,
+		Filename:    filename,
+		Size:        size,
+		Checksum:    checksum,
+		Description: description,
+		Status:      StatusUploading,
+		CreatedAt:   s.now(),
+		UpdatedAt:   s.now(),
+	}
+	if err := s.sessions.Create(ctx, sess); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// Status 查询会话（断点续传时用于获取已接收的字节数）
+func (s *Service) Status(ctx context.Context, id string, teacherID int) (*Session, error) {
+	sess, err := s.sessions.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sess.TeacherID != teacherID {
+		return nil, ErrNotFound
+	}
+	return sess, nil
+}
+
+// Append 追加分片
+// offset 必须等于服务器已接收的字节数；chunkChecksum 为 "sha256 <base64>" 格式（与 tus 的 Upload-Checksum 一致），可为空。
+// 接收完最后一个分片后自动完成上传；完成步骤失败时可以用空分片重试
+func (s *Service) Append(ctx context.Context, id string, teacherID int, offset int64, chunk io.Reader, chunkChecksum string) (*Session, error) {
+	var want []byte
+	if chunkChecksum != "" {
+		algo, value, _ := strings.Cut(chunkChecksum, " ")
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if !strings.EqualFold(algo, "sha256") || err != nil || len(decoded) != sha256.Size {
+			return nil, ErrBadChecksum
+		}
+		want = decoded
+	}
+
+	lock := s.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	sess, err := s.Status(ctx, id, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if sess.Status == StatusComplete {
+		return sess, ErrCompleted
+	}
+	if offset != sess.Offset {
+		return sess, &OffsetMismatchError{Offset: sess.Offset}
+	}
+
+	f, err := os.OpenFile(s.partPath(id), os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// 丢弃上次中断时写入了一半的数据
+	if err := f.Truncate(sess.Offset); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(sess.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	limit := sess.Size - sess.Offset
+	if limit > s.cfg.MaxChunk {
+		limit = s.cfg.MaxChunk
+	}
+	sum := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, sum), io.LimitReader(chunk, limit+1))
+	if err != nil {
+		f.Truncate(sess.Offset)
+		return nil, err
+	}
+	if n > limit {
+		f.Truncate(sess.Offset)
+		return sess, ErrChunkTooLarge
+	}
+	if want != nil && !bytes.Equal(sum.Sum(nil), want) {
+		f.Truncate(sess.Offset)
+		return sess, ErrChecksumMismatch
+	}
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+
+	if n > 0 {
+		ok, err := s.sessions.Advance(ctx, id, sess.Offset, sess.Offset+n)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// 其他实例处理了同一会话
+			current, _ := s.sessions.Get(ctx, id)
+			if current != nil {
+				return current, &OffsetMismatchError{Offset: current.Offset}
+			}
+			return nil, ErrNotFound
+		}
+		sess.Offset += n
+		sess.UpdatedAt = s.now()
+	}
+
+	if sess.Offset == sess.Size {
+		if err := s.complete(ctx, sess); err != nil {
+			return sess, err
+		}
+	}
+	return sess, nil
+}
+
+// complete 校验整个文件并解析视频元数据，写入存储并创建视频记录
+func (s *Service) complete(ctx context.Context, sess *Session) error {
+	part := s.partPath(sess.ID)
+
+	if sess.Checksum != "" {
+		f, err := os.Open(part)
+		if err != nil {
+			return err
+		}
+		sum := sha256.New()
+		_, err = io.Copy(sum, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		if hex.EncodeToString(sum.Sum(nil)) != sess.Checksum {
+			// 文件已损坏，无法续传，删除会话后需要重新上传
+			s.discard(ctx, sess.ID)
+			return ErrChecksumMismatch
+		}
+	}
+
+	info, err := mediainfo.ProbeFile(part)
+	if errors.Is(err, mediainfo.ErrInvalidContainer) {
+		s.discard(ctx, sess.ID)
+		return ErrInvalidVideo
+	}
+	if err != nil {
+		return err
+	}
+	sess.Media = info
+
+	ext := strings.ToLower(path.Ext(sess.Filename))
+	key := storage.PrefixVideos + sess.ID + ext
+
+	f, err := os.Open(part)
+	if err != nil {
+		return err
+	}
+	err = s.store.Put(ctx, key, f, sess.Size, mime.TypeByExtension(ext))
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("保存视频失败: %w", err)
+	}
+
+	videoID, err := s.finalize(ctx, sess, key)
+	if err != nil {
+		s.store.Delete(ctx, key)
+		return err
+	}
+	if err := s.sessions.Complete(ctx, sess.ID, videoID); err != nil {
+		return err
+	}
+
+	os.Remove(part)
+	sess.Status = StatusComplete
+	sess.VideoID = videoID
+	return nil
+}
+
+// Abort 取消上传并删除已接收的数据
+func (s *Service) Abort(ctx context.Context, id string, teacherID int) error {
+	lock := s.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	sess, err := s.Status(ctx, id, teacherID)
+	if err != nil {
+		return err
+	}
+	if sess.Status == StatusComplete {
+		return ErrCompleted
+	}
+	return s.discard(ctx, id)
+}
+
+// Cleanup 删除过期的未完成会话，返回删除的数量
+func (s *Service) Cleanup(ctx context.Context) (int, error) {
+	ids, err := s.sessions.Stale(ctx, s.now().Add(-s.cfg.Expiry))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := s.discard(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+// discard 删除会话和暂存文件
+func (s *Service) discard(ctx context.Context, id string) error {
+	if err := s.sessions.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.partPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.locks.Delete(id)
+	return nil
+}
+
+// lock 获取会话锁
+func (s *Service) lock(id string) *sync.Mutex {
+	v, _ := s.locks.LoadOrStore(id, &sync.Mutex{})
+	return v.(*sync.Mutex)
+}
+
+// partPath 暂存文件路径（会话ID为服务器生成的十六进制字符串，不会逃逸出暂存目录）
+func (s *Service) partPath(id string) string {
+	return filepath.Join(s.cfg.TempDir, id+".part")
+}
+
+// newID 生成会话ID
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,24 @@
+//go:build embedui
+
+// internal/webui/embed.go
+package webui
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var distFS embed.FS
+
+// Dist 返回嵌入的前端文件；未找到 index.html 时视为不可用
+func Dist() (fs.FS, bool) {
+	sub, err := fs.Sub(distFS, "dist")
+	if err != nil {
+		return nil, false
+	}
+	if _, err := fs.Stat(sub, "index.html"); err != nil {
+		return nil, false
+	}
+	return sub, true
+}
@@ -0,0 +1,11 @@
+//go:build !embedui
+
+// internal/webui/embed_none.go
+package webui
+
+import "io/fs"
+
+// Dist 未使用 embedui 标签构建时，前端文件不可用
+func Dist() (fs.FS, bool) {
+	return nil, false
+}
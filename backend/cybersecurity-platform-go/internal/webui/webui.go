@@ -0,0 +1,12 @@
+// internal/webui/webui.go
+
+// Package webui 提供嵌入到二进制中的前端构建产物（frontend/dist）
+//
+// 默认构建不包含前端文件；需要单文件部署时：
+//
+//	cd frontend && npm run build
+//	cp -r dist/* ../backend/cybersecurity-platform-go/internal/webui/dist/
+//	go build -tags embedui ./MAIN/server
+//
+// dist 中与原文件同名的 .br / .gz 文件会作为预压缩版本发送
+package webui
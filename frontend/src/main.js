@@ -2,6 +2,7 @@ import { createApp } from 'vue' // Vue 3 新写法
 import App from './App.vue'
 import router from './router'
 import store from './store'
+import axios from 'axios'
 
 // 1. Element Plus 替换 Element UI
 import ElementPlus from 'element-plus'
@@ -28,6 +29,14 @@ VMdPreview.use(githubTheme, {
   Hljs: hljs,
 });
 
+// 登录后所有请求都带上会话令牌，后端按令牌识别当前学生
+axios.interceptors.request.use(config => {
+  if (store.getters.token) {
+    config.headers['X-Token'] = store.getters.token
+  }
+  return config
+})
+
 // 4. 创建并挂载应用
 const app = createApp(App)
 
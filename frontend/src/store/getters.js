@@ -2,7 +2,7 @@ const getters = {
   sidebar: state => state.app.sidebar,
   device: state => state.app.device,
   user: state => state.user.user,
-  token: state => state.user.token,
+  token: state => state.user.userInfo.token,
   addRouters: state => state.permission.addRouters,
   routers: state => state.permission.routers
 }
@@ -1,3 +1,5 @@
+import axios from 'axios'
+
 const state = () => ({ // 建议使用函数返回 state，防止 SSR 内存泄露
   userInfo: JSON.parse(localStorage.getItem('user')) || {
     id: '',
@@ -21,7 +23,8 @@ const mutations = {
       userHead: '',
       nickName: '',
       userName: '', 
-      userEmail: ''
+      userEmail: '',
+      token: ''
     };
     localStorage.removeItem('user');
   }
@@ -36,9 +39,15 @@ const actions = {
     commit('SET_USER', updatedInfo);
     return Promise.resolve();
   },
-  logout({ commit }) {
+  // 退出时注销服务端的登录会话，请求失败也清除本地登录状态
+  logout({ commit, state }) {
+    const token = state.userInfo.token;
     commit('CLEAR_USER');
-    return Promise.resolve();
+    if (!token) {
+      return Promise.resolve();
+    }
+    return axios.post('/api/logout', null, { headers: { 'X-Token': token } })
+      .catch(() => {});
   }
 }
 